@@ -5,6 +5,7 @@ go 1.24.7
 require (
 	github.com/jackc/pgx/v5 v5.5.0
 	github.com/spf13/cobra v1.8.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
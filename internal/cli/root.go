@@ -9,8 +9,8 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "xrai",
 	Short: "Xrai - LLM-optimized database schema introspection",
-	Long: `Xrai statically introspects a relational database and generates a
-JSON-first, LLM-optimized schema snapshot.
+	Long: `Xrai statically introspects a relational database and generates an
+LLM-optimized schema snapshot in TOON, JSON, YAML or Markdown.
 
 The output is designed for machine consumption, providing structured
 metadata about tables, columns, relationships, and more.`,
@@ -25,4 +25,5 @@ func Execute() {
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(toonCmd)
 }
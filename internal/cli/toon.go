@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/toon"
+	"github.com/spf13/cobra"
+)
+
+var validateIndent int
+
+var toonCmd = &cobra.Command{
+	Use:   "toon",
+	Short: "Work with TOON files",
+}
+
+var toonValidateCmd = &cobra.Command{
+	Use:   "validate <path>...",
+	Short: "Check TOON files against the spec",
+	Long: `Check TOON files against the TOON spec and report every problem with its
+line and column: array lengths that do not match their rows or items,
+tabular rows with the wrong number of fields, bad indentation, strings that
+must be quoted (including values containing the delimiter) and invalid
+escapes.
+
+Directories are searched recursively for *.toon files.
+
+Example:
+  xrai toon validate ./schema/.xrai
+  xrai toon validate db.index.toon tables/public/users/table.columns.toon`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runToonValidate,
+}
+
+func init() {
+	toonValidateCmd.Flags().IntVar(&validateIndent, "indent", 0, "Spaces per nesting level (default: detected from each file)")
+	toonCmd.AddCommand(toonValidateCmd)
+}
+
+func runToonValidate(cmd *cobra.Command, args []string) error {
+	files, err := toonFiles(args)
+	if err != nil {
+		return err
+	}
+
+	problems, failed := 0, 0
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		indent := validateIndent
+		if indent == 0 {
+			indent = toon.DetectIndent(data)
+		}
+
+		issues := toon.Validate(data, toon.DecodeOptions{Indent: indent})
+		for _, issue := range issues {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s:%d:%d: %s\n", path, issue.Line, issue.Column, issue.Msg)
+		}
+		if len(issues) > 0 {
+			problems += len(issues)
+			failed++
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("found %s in %d of %s", count(problems, "problem"), failed, count(len(files), "file"))
+	}
+	fmt.Fprintf(os.Stderr, "Validated %s\n", count(len(files), "file"))
+	return nil
+}
+
+// count returns n followed by noun, pluralized when n is not one.
+func count(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// toonFiles expands directories in paths to the *.toon files beneath them.
+// Files named explicitly are validated whatever their extension.
+func toonFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(p, ".toon") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", path, err)
+		}
+	}
+	return files, nil
+}
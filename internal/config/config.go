@@ -1,5 +1,7 @@
 package config
 
+import "github.com/nenorrell/X-Rai/internal/toon"
+
 // Config holds all configuration options for xrai generation.
 type Config struct {
 	// Connection
@@ -7,6 +9,16 @@ type Config struct {
 
 	// Output
 	OutputDir string
+	Formats   []string // toon, json, yaml or markdown; the first is primary
+
+	// Bundle also writes the whole snapshot to one schema.bundle file;
+	// BundleExclude leaves views, routines or stats out of it
+	Bundle        bool
+	BundleExclude []string
+
+	// TokenBudget, if positive, drops lower-priority content until the
+	// bundle (with Bundle) or llms.txt fits this many tokens
+	TokenBudget int
 
 	// Scope
 	Schemas []string
@@ -19,6 +31,9 @@ type Config struct {
 	// Redaction
 	RedactComments    bool
 	RedactDefinitions bool
+
+	// TOON layout: delimiter, key folding, indentation and inline lists
+	TOON toon.EncodeOptions
 }
 
 // NewConfig creates a Config with default values.
@@ -30,5 +45,6 @@ func NewConfig() *Config {
 		IncludeStats:      false,
 		RedactComments:    false,
 		RedactDefinitions: false,
+		Formats:           []string{"toon"},
 	}
 }
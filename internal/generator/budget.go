@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/tokens"
+)
+
+// trimSet names the content left out to fit a token budget.
+type trimSet map[string]bool
+
+// comments reports whether comments are dropped for a table with tags.
+func (t trimSet) comments(tags []string) bool {
+	if t["comments"] {
+		return true
+	}
+	if t["lookup_comments"] {
+		for _, tag := range tags {
+			if tag == "lookup" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trimSteps is the order --token-budget drops content in, least useful
+// first. Steps that do not shrink the target are skipped.
+var trimSteps = []string{
+	"stats",            // table row counts and sizes
+	"lookup_comments",  // comments on tables tagged lookup
+	"routine_bodies",   // function and procedure definitions
+	"view_definitions", // view SQL
+	"comments",         // every remaining table, column and schema comment
+	"search_hints",     // spatial, vector and full-text search sections
+	"enum_reference",   // enum values listed in llms.txt
+	"entry_points",     // suggested starting tables in llms.txt
+}
+
+// fitBudget drops content in trimSteps order until the bundle (with
+// --bundle) or llms.txt fits cfg.TokenBudget, and records the outcome.
+func (g *Generator) fitBudget(db *schema.Database) error {
+	g.llmsTrim, g.bundleTrim, g.budget = nil, nil, nil
+	if g.cfg.TokenBudget <= 0 {
+		return nil
+	}
+
+	file := "llms.txt"
+	count := func(trim trimSet) (int, error) {
+		return tokens.Count(g.llmsTxt(db, trim)), nil
+	}
+	if g.cfg.Bundle {
+		w := g.writers[0]
+		file = g.file(bundleFile)
+		count = func(trim trimSet) (int, error) {
+			var buf bytes.Buffer
+			if err := w.Write(&buf, g.bundle(db, trim)); err != nil {
+				return 0, fmt.Errorf("failed to render %s: %w", file, err)
+			}
+			return tokens.Count(buf.String()), nil
+		}
+	}
+
+	trim := trimSet{}
+	n, err := count(trim)
+	if err != nil {
+		return err
+	}
+	budget := &schema.TokenBudget{Budget: g.cfg.TokenBudget, File: file}
+	for _, step := range trimSteps {
+		if n <= g.cfg.TokenBudget {
+			break
+		}
+		trim[step] = true
+		smaller, err := count(trim)
+		if err != nil {
+			return err
+		}
+		if smaller >= n {
+			delete(trim, step)
+			continue
+		}
+		n = smaller
+		budget.Dropped = append(budget.Dropped, step)
+	}
+	budget.Tokens = n
+	budget.Fits = n <= g.cfg.TokenBudget
+
+	if g.cfg.Bundle {
+		g.bundleTrim = trim
+	} else {
+		g.llmsTrim = trim
+	}
+	g.budget = budget
+	return nil
+}
+
+// TokenBudget returns how the last Generate fitted its token budget, or nil
+// if no budget was set.
+func (g *Generator) TokenBudget() *schema.TokenBudget {
+	return g.budget
+}
+
+// countFile records the token count of a file just written in format ext.
+func (g *Generator) countFile(ext, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	rel, err := filepath.Rel(g.outputDir, path)
+	if err != nil {
+		return fmt.Errorf("failed to count tokens in %s: %w", path, err)
+	}
+	g.counts[ext] = append(g.counts[ext], schema.FileTokens{
+		Path:   filepath.ToSlash(rel),
+		Tokens: tokens.Count(string(data)),
+	})
+	return nil
+}
+
+// tokenCounts lists llms.txt and every file written in format ext, sorted
+// by path.
+func (g *Generator) tokenCounts(ext string) *schema.TokenCounts {
+	counts := &schema.TokenCounts{Encoding: tokens.Encoding}
+	counts.Files = append(counts.Files, g.shared...)
+	counts.Files = append(counts.Files, g.counts[ext]...)
+	sort.Slice(counts.Files, func(i, j int) bool {
+		return counts.Files[i].Path < counts.Files[j].Path
+	})
+	for _, f := range counts.Files {
+		counts.Total += f.Tokens
+	}
+	return counts
+}
@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/introspector/ddl"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/tokens"
+	"github.com/nenorrell/X-Rai/internal/toon"
+)
+
+func TestTrimSet_Comments(t *testing.T) {
+	tests := []struct {
+		name     string
+		trim     trimSet
+		tags     []string
+		expected bool
+	}{
+		{"nothing dropped", trimSet{}, []string{"lookup"}, false},
+		{"lookup table", trimSet{"lookup_comments": true}, []string{"audit", "lookup"}, true},
+		{"other table", trimSet{"lookup_comments": true}, []string{"audit"}, false},
+		{"all comments", trimSet{"comments": true}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.trim.comments(tt.tags); got != tt.expected {
+				t.Errorf("comments(%v) = %v, want %v", tt.tags, got, tt.expected)
+			}
+		})
+	}
+}
+
+// generateBudget generates the pg_dump fixture with a token budget and
+// returns the generator and output directory.
+func generateBudget(t *testing.T, budget int, bundle bool) (*Generator, string) {
+	t.Helper()
+
+	intro, err := ddl.New(filepath.Join("..", "introspector", "ddl", "testdata", "pg_dump.sql"))
+	if err != nil {
+		t.Fatalf("ddl.New() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+	cfg.Schemas = []string{"public", "billing"}
+	cfg.IncludeViews = true
+	cfg.IncludeRoutines = true
+	cfg.Bundle = bundle
+	cfg.TokenBudget = budget
+
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	gen := New(cfg)
+	if err := gen.Generate(db); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	return gen, filepath.Join(cfg.OutputDir, ".xrai")
+}
+
+func readManifest(t *testing.T, outDir string) schema.Manifest {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(outDir, "xrai.manifest.toon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest schema.Manifest
+	if err := toon.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return manifest
+}
+
+// TestGenerate_TokenBudget checks that the bundle is trimmed in trimSteps
+// order, that the manifest records the outcome, and that the counts in the
+// manifest match the files on disk.
+func TestGenerate_TokenBudget(t *testing.T) {
+	gen, outDir := generateBudget(t, 0, true)
+	if gen.TokenBudget() != nil {
+		t.Errorf("TokenBudget() = %+v without a budget, want nil", gen.TokenBudget())
+	}
+	manifest := readManifest(t, outDir)
+	if manifest.TokenBudget != nil {
+		t.Errorf("manifest records a token budget without one: %+v", manifest.TokenBudget)
+	}
+
+	full := 0
+	for _, f := range manifest.Tokens.Files {
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tokens.Count(string(data)); got != f.Tokens {
+			t.Errorf("manifest counts %d tokens in %s, file has %d", f.Tokens, f.Path, got)
+		}
+		if f.Path == "schema.bundle.toon" {
+			full = f.Tokens
+		}
+	}
+	if full == 0 {
+		t.Fatalf("manifest does not count the bundle: %+v", manifest.Tokens.Files)
+	}
+
+	// One token under: dropping comments on lookup tables, the first step
+	// that shrinks this fixture, is enough.
+	gen, outDir = generateBudget(t, full-1, true)
+	budget := gen.TokenBudget()
+	if budget == nil || !budget.Fits || !reflect.DeepEqual(budget.Dropped, []string{"lookup_comments"}) {
+		t.Fatalf("TokenBudget() = %+v, want lookup_comments dropped and fitting", budget)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "schema.bundle.toon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokens.Count(string(data)); got != budget.Tokens {
+		t.Errorf("bundle has %d tokens, budget recorded %d", got, budget.Tokens)
+	}
+	if !strings.Contains(string(data), "RETURNS") {
+		t.Error("bundle lost its routine bodies")
+	}
+	if got := readManifest(t, outDir).TokenBudget; !reflect.DeepEqual(got, budget) {
+		t.Errorf("manifest token_budget = %+v, want %+v", got, budget)
+	}
+
+	// An impossible budget drops everything that helps and still reports
+	// the shortfall.
+	gen, _ = generateBudget(t, 1, true)
+	budget = gen.TokenBudget()
+	if budget.Fits {
+		t.Errorf("TokenBudget() = %+v, want it not to fit", budget)
+	}
+	want := []string{"lookup_comments", "routine_bodies", "view_definitions", "comments"}
+	if !reflect.DeepEqual(budget.Dropped, want) {
+		t.Errorf("dropped %v, want %v", budget.Dropped, want)
+	}
+}
+
+// TestGenerate_TokenBudget_LLMsTxt checks that without --bundle the budget
+// applies to llms.txt.
+func TestGenerate_TokenBudget_LLMsTxt(t *testing.T) {
+	gen, outDir := generateBudget(t, 1, false)
+	budget := gen.TokenBudget()
+	if budget == nil || budget.File != "llms.txt" || budget.Fits {
+		t.Fatalf("TokenBudget() = %+v, want llms.txt not fitting", budget)
+	}
+	// Only the llms.txt sections shrink it; the bundle-only steps are skipped.
+	want := []string{"search_hints", "enum_reference", "entry_points"}
+	if !reflect.DeepEqual(budget.Dropped, want) {
+		t.Errorf("dropped %v, want %v", budget.Dropped, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "llms.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokens.Count(string(data)); got != budget.Tokens {
+		t.Errorf("llms.txt has %d tokens, budget recorded %d", got, budget.Tokens)
+	}
+}
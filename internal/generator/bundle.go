@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// bundleFile is the base name of the single-file snapshot.
+const bundleFile = "schema.bundle"
+
+// BundleSections lists the sections --bundle-exclude can leave out.
+var BundleSections = []string{"views", "routines", "stats"}
+
+// ValidateBundleSections checks section names given to --bundle-exclude.
+func ValidateBundleSections(names []string) error {
+	for _, name := range names {
+		known := false
+		for _, s := range BundleSections {
+			known = known || name == s
+		}
+		if !known {
+			return fmt.Errorf("unknown bundle section %q (want %s)", name, strings.Join(BundleSections, ", "))
+		}
+	}
+	return nil
+}
+
+// bundleIncludes reports whether the bundle carries a section: it must have
+// been generated and not excluded.
+func (g *Generator) bundleIncludes(section string) bool {
+	for _, name := range g.cfg.BundleExclude {
+		if name == section {
+			return false
+		}
+	}
+	switch section {
+	case "views":
+		return g.cfg.IncludeViews
+	case "routines":
+		return g.cfg.IncludeRoutines
+	case "stats":
+		return g.cfg.IncludeStats
+	}
+	return true
+}
+
+func (g *Generator) generateBundle(db *schema.Database) error {
+	return g.writeArtifact(filepath.Join(g.outputDir, bundleFile), g.bundle(db, g.bundleTrim))
+}
+
+// bundle assembles the single-file snapshot, leaving out the content in
+// trim.
+func (g *Generator) bundle(db *schema.Database, trim trimSet) schema.Bundle {
+	bundle := schema.Bundle{
+		Manifest:      g.manifest(db),
+		Index:         g.databaseIndex(db),
+		Relationships: relationshipGraph(db),
+		Tables:        make([]schema.BundleTable, 0, len(db.Tables)),
+	}
+
+	// The bundle has no separate files, so its manifest lists only what it
+	// actually carries.
+	bundle.Manifest.EnabledArtifacts.Views = bundle.Manifest.EnabledArtifacts.Views && g.bundleIncludes("views")
+	bundle.Manifest.EnabledArtifacts.Routines = bundle.Manifest.EnabledArtifacts.Routines && g.bundleIncludes("routines")
+	bundle.Manifest.EnabledArtifacts.Stats = bundle.Manifest.EnabledArtifacts.Stats && g.bundleIncludes("stats") && !trim["stats"]
+	bundle.Manifest.StatsEnabled = bundle.Manifest.EnabledArtifacts.Stats
+
+	for i, entry := range bundle.Index.Tables {
+		if trim.comments(entry.Tags) {
+			bundle.Index.Tables[i].ShortDescription = ""
+		}
+	}
+
+	for _, table := range db.Tables {
+		bundle.Tables = append(bundle.Tables, g.bundleTable(table, trim))
+	}
+
+	for _, enum := range db.Enums {
+		out := *enum
+		if trim["comments"] {
+			out.Comment = ""
+		}
+		bundle.Enums = append(bundle.Enums, out)
+	}
+
+	if g.bundleIncludes("views") {
+		for _, view := range db.Views {
+			bundle.Views = append(bundle.Views, g.bundleView(view, trim))
+		}
+	}
+
+	if g.bundleIncludes("routines") {
+		for _, routine := range db.Routines {
+			out := *routine
+			if g.cfg.RedactDefinitions || trim["routine_bodies"] {
+				out.Definition = nil
+			}
+			bundle.Routines = append(bundle.Routines, out)
+		}
+	}
+
+	return bundle
+}
+
+func (g *Generator) bundleTable(table *schema.Table, trim trimSet) schema.BundleTable {
+	dropComments := trim.comments(table.Tags)
+	out := schema.BundleTable{
+		TableName:  table.TableName,
+		SchemaName: table.SchemaName,
+		Columns:    g.bundleColumns(table.Columns, dropComments),
+		PrimaryKey: getPrimaryKeyColumns(table),
+	}
+	if !g.cfg.RedactComments && !dropComments {
+		out.Comment = table.Comment
+	}
+
+	for _, fk := range table.OutgoingForeignKeys {
+		toTable := fk.ToTable
+		if fk.ToSchema != "" && fk.ToSchema != table.SchemaName {
+			toTable = fk.ToSchema + "." + fk.ToTable
+		}
+		out.ForeignKeys = append(out.ForeignKeys, schema.BundleForeignKey{
+			ConstraintName: fk.ConstraintName,
+			Columns:        fk.FromColumns,
+			ToTable:        toTable,
+			ToColumns:      fk.ToColumns,
+			OnDelete:       fk.OnDelete,
+		})
+	}
+
+	for _, con := range table.Constraints {
+		c := schema.BundleConstraint{
+			ConstraintName: con.ConstraintName,
+			Columns:        con.Columns,
+			Definition:     con.Definition,
+		}
+		switch con.ConstraintType {
+		case "UNIQUE":
+			out.UniqueConstraints = append(out.UniqueConstraints, c)
+		case "CHECK":
+			out.CheckConstraints = append(out.CheckConstraints, c)
+		}
+	}
+
+	for _, idx := range table.Indexes {
+		out.Indexes = append(out.Indexes, schema.BundleIndex{
+			IndexName:  idx.IndexName,
+			IndexType:  idx.IndexType,
+			Unique:     idx.Unique,
+			Columns:    idx.Columns,
+			Expression: idx.Expression,
+			Predicate:  idx.Predicate,
+		})
+	}
+
+	if g.bundleIncludes("stats") && !trim["stats"] {
+		out.Stats = table.Stats
+	}
+
+	return out
+}
+
+func (g *Generator) bundleView(view *schema.View, trim trimSet) schema.BundleView {
+	out := schema.BundleView{
+		ViewName:        view.ViewName,
+		SchemaName:      view.SchemaName,
+		Columns:         g.bundleColumns(view.Columns, trim["comments"]),
+		DependsOnTables: view.DependsOnTables,
+	}
+	if !g.cfg.RedactComments && !trim["comments"] {
+		out.Comment = view.Comment
+	}
+	if !g.cfg.RedactDefinitions && !trim["view_definitions"] {
+		out.Definition = view.Definition
+	}
+	return out
+}
+
+func (g *Generator) bundleColumns(columns []*schema.Column, dropComments bool) []schema.BundleColumn {
+	out := make([]schema.BundleColumn, 0, len(columns))
+	for _, col := range columns {
+		c := schema.BundleColumn{
+			ColumnName: col.ColumnName,
+			Type:       columnType(col),
+			Nullable:   col.Nullable,
+			Default:    col.DefaultValue,
+		}
+
+		switch {
+		case col.IsIdentity:
+			def := "GENERATED " + col.IdentityGeneration + " AS IDENTITY"
+			c.Default = &def
+		case col.Generated && col.GenerationExpression != nil:
+			def := "GENERATED ALWAYS AS (" + *col.GenerationExpression + ") STORED"
+			c.Default = &def
+		}
+
+		if !g.cfg.RedactComments && !dropComments && col.Comment != "" {
+			comment := col.Comment
+			c.Comment = &comment
+		}
+
+		out = append(out, c)
+	}
+	return out
+}
+
+// columnType writes a column's type as it would appear in DDL.
+func columnType(col *schema.Column) string {
+	typ := col.DataType
+	switch {
+	case col.DataType == "ARRAY" && strings.HasPrefix(col.UDTName, "_"):
+		typ = col.UDTName[1:] + "[]"
+	case col.DataType == "USER-DEFINED" && col.UDTName != "":
+		typ = col.UDTName
+	}
+
+	switch {
+	case col.Spatial != nil:
+		typ = fmt.Sprintf("%s(%s,%d)", col.Spatial.Kind, col.Spatial.GeometryType, col.Spatial.SRID)
+	case col.Vector != nil && col.Vector.Dimensions > 0:
+		typ = fmt.Sprintf("%s(%d)", col.Vector.Type, col.Vector.Dimensions)
+	case col.CharacterMaxLength != nil:
+		typ = fmt.Sprintf("%s(%d)", typ, *col.CharacterMaxLength)
+	case typ == "numeric" && col.NumericPrecision != nil && col.NumericScale != nil:
+		typ = fmt.Sprintf("numeric(%d,%d)", *col.NumericPrecision, *col.NumericScale)
+	case typ == "numeric" && col.NumericPrecision != nil:
+		typ = fmt.Sprintf("numeric(%d)", *col.NumericPrecision)
+	}
+	return typ
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/introspector/ddl"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/toon"
+)
+
+func TestColumnType(t *testing.T) {
+	intp := func(i int) *int { return &i }
+
+	tests := []struct {
+		name     string
+		col      schema.Column
+		expected string
+	}{
+		{"plain", schema.Column{DataType: "bigint"}, "bigint"},
+		{"varchar", schema.Column{DataType: "character varying", CharacterMaxLength: intp(255)}, "character varying(255)"},
+		{"numeric", schema.Column{DataType: "numeric", NumericPrecision: intp(10), NumericScale: intp(2)}, "numeric(10,2)"},
+		{"enum", schema.Column{DataType: "USER-DEFINED", UDTName: "order_status"}, "order_status"},
+		{"array", schema.Column{DataType: "ARRAY", UDTName: "_text"}, "text[]"},
+		{"vector", schema.Column{DataType: "USER-DEFINED", UDTName: "vector", Vector: &schema.VectorInfo{Type: "vector", Dimensions: 1536}}, "vector(1536)"},
+		{"geometry", schema.Column{DataType: "USER-DEFINED", UDTName: "geometry", Spatial: &schema.SpatialInfo{Kind: "geometry", GeometryType: "Point", SRID: 4326}}, "geometry(Point,4326)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnType(&tt.col); got != tt.expected {
+				t.Errorf("columnType() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateBundleSections(t *testing.T) {
+	if err := ValidateBundleSections([]string{"views", "stats"}); err != nil {
+		t.Errorf("ValidateBundleSections() error = %v", err)
+	}
+	if err := ValidateBundleSections([]string{"indexes"}); err == nil {
+		t.Error("expected an error for an unknown section")
+	}
+}
+
+// TestGenerate_Bundle checks that the bundle carries every table with its
+// columns, keys and indexes, honours --bundle-exclude, and reads back.
+func TestGenerate_Bundle(t *testing.T) {
+	intro, err := ddl.New(filepath.Join("..", "introspector", "ddl", "testdata", "pg_dump.sql"))
+	if err != nil {
+		t.Fatalf("ddl.New() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+	cfg.Schemas = []string{"public", "billing"}
+	cfg.IncludeViews = true
+	cfg.IncludeRoutines = true
+	cfg.Bundle = true
+	cfg.BundleExclude = []string{"routines"}
+
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	if err := New(cfg).Generate(db); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	outDir := filepath.Join(cfg.OutputDir, ".xrai")
+	data, err := os.ReadFile(filepath.Join(outDir, "schema.bundle.toon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle schema.Bundle
+	if err := toon.Unmarshal(data, &bundle); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if len(bundle.Tables) != len(db.Tables) || len(bundle.Index.Tables) != len(db.Tables) {
+		t.Fatalf("bundle has %d tables and %d index entries, want %d", len(bundle.Tables), len(bundle.Index.Tables), len(db.Tables))
+	}
+	if len(bundle.Relationships.Edges) != 3 {
+		t.Errorf("bundle has %d relationship edges, want 3", len(bundle.Relationships.Edges))
+	}
+	if len(bundle.Enums) != 1 || len(bundle.Views) != 1 {
+		t.Errorf("bundle has %d enums and %d views, want 1 each", len(bundle.Enums), len(bundle.Views))
+	}
+	if len(bundle.Routines) != 0 || bundle.Manifest.EnabledArtifacts.Routines {
+		t.Errorf("routines were excluded but the bundle has %d (enabled: %v)", len(bundle.Routines), bundle.Manifest.EnabledArtifacts.Routines)
+	}
+
+	tables := map[string]schema.BundleTable{}
+	for _, table := range bundle.Tables {
+		tables[table.TableName] = table
+	}
+
+	invoices := tables["invoices"]
+	if len(invoices.ForeignKeys) != 1 || invoices.ForeignKeys[0].ToTable != "public.orders" {
+		t.Errorf("invoices foreign keys = %+v, want one to public.orders", invoices.ForeignKeys)
+	}
+
+	orders := tables["orders"]
+	if got := *orders.Columns[0].Default; got != "GENERATED ALWAYS AS IDENTITY" {
+		t.Errorf("orders.id default = %q", got)
+	}
+	if len(orders.CheckConstraints) != 1 || len(orders.Indexes) != 2 {
+		t.Errorf("orders has %d checks and %d indexes, want 1 and 2", len(orders.CheckConstraints), len(orders.Indexes))
+	}
+
+	users := tables["users"]
+	if got := users.Columns[1].Type; got != "character varying(255)" {
+		t.Errorf("users.email type = %q", got)
+	}
+	if len(users.PrimaryKey) != 1 || len(users.UniqueConstraints) != 1 {
+		t.Errorf("users primary key = %v, unique = %+v", users.PrimaryKey, users.UniqueConstraints)
+	}
+
+	llms, err := os.ReadFile(filepath.Join(outDir, "llms.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(llms), "`schema.bundle.toon`") {
+		t.Errorf("llms.txt does not mention the bundle:\n%s", llms)
+	}
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func (g *Generator) generateCollations(db *schema.Database) error {
+	output := schema.CollationsOutput{
+		Collations: make([]schema.Collation, 0, len(db.Collations)),
+	}
+
+	for _, c := range db.Collations {
+		coll := *c
+		if g.cfg.RedactComments {
+			coll.Comment = ""
+		}
+		output.Collations = append(output.Collations, coll)
+	}
+
+	return g.writeArtifact(filepath.Join(g.outputDir, "db.collations"), output)
+}
+
+func (g *Generator) generateTextSearch(db *schema.Database) error {
+	output := schema.TextSearchOutput{
+		Configurations: make([]schema.TextSearchConfig, 0, len(db.TextSearchConfigs)),
+	}
+
+	for _, c := range db.TextSearchConfigs {
+		cfg := *c
+		if g.cfg.RedactComments {
+			cfg.Comment = ""
+		}
+		output.Configurations = append(output.Configurations, cfg)
+	}
+
+	for _, d := range db.TextSearchDictionaries {
+		dict := *d
+		if g.cfg.RedactComments {
+			dict.Comment = ""
+		}
+		output.Dictionaries = append(output.Dictionaries, dict)
+	}
+
+	return g.writeArtifact(filepath.Join(g.outputDir, "db.text-search"), output)
+}
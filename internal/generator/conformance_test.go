@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/introspector"
+	"github.com/nenorrell/X-Rai/internal/introspector/ddl"
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/toon"
+)
+
+// conformanceArtifacts lists every kind of TOON file the generator writes.
+// TestGenerate_Conformance fails if a source stops producing one, so a new
+// artifact type cannot skip validation.
+var conformanceArtifacts = []string{
+	"xrai.manifest.toon",
+	"db.index.toon",
+	"db.relationships.toon",
+	"db.domains.toon",
+	"db.environment.toon",
+	"db.collations.toon",
+	"db.text-search.toon",
+	"schemas/*",
+	"tables/*/*/table.structure.toon",
+	"tables/*/*/table.columns.toon",
+	"tables/*/*/table.indexes.toon",
+	"tables/*/*/table.constraints.toon",
+	"tables/*/*/table.relations.toon",
+	"tables/*/*/table.triggers.toon",
+	"tables/*/*/table.comments.toon",
+	"tables/*/*/table.stats.toon",
+	"views/*/*/view.definition.toon",
+	"views/*/*/view.columns.toon",
+	"views/*/*/view.dependencies.toon",
+	"views/*/*/view.comments.toon",
+	"routines/functions/*/*",
+	"routines/procedures/*/*",
+	"enums/*/*",
+	"sequences/*/*",
+	"types/*/*",
+	"schema.bundle.toon",
+}
+
+// TestGenerate_Conformance runs the TOON validator over every generated
+// file, for each schema source and output layout.
+func TestGenerate_Conformance(t *testing.T) {
+	sources := []struct {
+		name    string
+		open    func() (introspector.Introspector, error)
+		schemas []string
+	}{
+		{
+			name: "fixture",
+			open: func() (introspector.Introspector, error) {
+				return fixture.New(filepath.Join("testdata", "shop.yaml"))
+			},
+			schemas: []string{"public"},
+		},
+		{
+			name: "pg_dump",
+			open: func() (introspector.Introspector, error) {
+				return ddl.New(filepath.Join("..", "introspector", "ddl", "testdata", "pg_dump.sql"))
+			},
+			schemas: []string{"public", "billing"},
+		},
+	}
+	layouts := map[string]toon.EncodeOptions{
+		"spec":                {},
+		"inline lists":        {InlineLists: true},
+		"pipe inline lists":   {Delimiter: toon.Pipe, InlineLists: true},
+		"tab folded indent 4": {Delimiter: toon.Tab, KeyFolding: true, Indent: 4},
+	}
+
+	seen := map[string]bool{}
+	for _, src := range sources {
+		for layoutName, layout := range layouts {
+			t.Run(src.name+"/"+layoutName, func(t *testing.T) {
+				intro, err := src.open()
+				if err != nil {
+					t.Fatalf("open() error = %v", err)
+				}
+
+				cfg := config.NewConfig()
+				cfg.OutputDir = t.TempDir()
+				cfg.Schemas = src.schemas
+				cfg.IncludeViews = true
+				cfg.IncludeRoutines = true
+				cfg.IncludeStats = true
+				cfg.Bundle = true
+				cfg.TOON = layout
+
+				db, err := intro.Introspect(context.Background(), cfg)
+				if err != nil {
+					t.Fatalf("Introspect() error = %v", err)
+				}
+				addCatalogInventories(db)
+				if err := New(cfg).Generate(db); err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+
+				outDir := filepath.Join(cfg.OutputDir, ".xrai")
+				err = filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+					if err != nil || d.IsDir() || filepath.Ext(path) != ".toon" {
+						return err
+					}
+					data, err := os.ReadFile(path)
+					if err != nil {
+						return err
+					}
+					rel, _ := filepath.Rel(outDir, path)
+					rel = filepath.ToSlash(rel)
+					seen[artifactKind(rel)] = true
+
+					for _, issue := range toon.Validate(data, toon.DecodeOptions{Indent: layout.Indent}) {
+						t.Errorf("%s:%d:%d: %s", rel, issue.Line, issue.Column, issue.Msg)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	}
+
+	for _, kind := range conformanceArtifacts {
+		if !seen[kind] {
+			t.Errorf("no %s was generated, so it was not validated", kind)
+		}
+	}
+}
+
+// artifactKind maps a generated file to its entry in conformanceArtifacts.
+func artifactKind(rel string) string {
+	parts := strings.Split(rel, "/")
+	switch {
+	case len(parts) == 4 && (parts[0] == "tables" || parts[0] == "views"):
+		return parts[0] + "/*/*/" + parts[3]
+	case len(parts) == 2:
+		return parts[0] + "/*"
+	case len(parts) > 2:
+		// <kind>/<schema>/<name>
+		return strings.Join(parts[:len(parts)-2], "/") + "/*/*"
+	}
+	return rel
+}
+
+// addCatalogInventories fills in what neither source can describe: the
+// server environment, collations, text search, statistics and a procedure.
+// Values include delimiters and quoting edge cases.
+func addCatalogInventories(db *schema.Database) {
+	size := int64(8 << 20)
+	db.Environment = &schema.Environment{
+		ServerVersion: "16.2",
+		VersionString: "PostgreSQL 16.2 on x86_64-pc-linux-gnu, compiled by gcc",
+		Encoding:      "UTF8",
+		SizeBytes:     &size,
+		Settings: []schema.Setting{
+			{Name: "search_path", Value: `"$user", public`, Source: "default"},
+			{Name: "work_mem", Value: "4096", Unit: "kB", Source: "configuration file"},
+		},
+	}
+	db.Collations = []*schema.Collation{
+		{CollationName: "case_insensitive", SchemaName: "public", Provider: "icu", Locale: "und-u-ks-level2", UsedBy: []string{"users.email"}},
+	}
+	db.TextSearchConfigs = []*schema.TextSearchConfig{
+		{ConfigName: "english", Parser: "default", Dictionaries: []string{"english_stem"}, IsDefault: true},
+	}
+	db.TextSearchDictionaries = []*schema.TextSearchDictionary{
+		{DictionaryName: "english_stem", Template: "snowball", Options: "language = 'english'"},
+	}
+	db.Routines = append(db.Routines, &schema.Routine{
+		RoutineName: "archive_orders",
+		SchemaName:  "public",
+		RoutineType: "procedure",
+		Language:    "plpgsql",
+	})
+
+	nulls, distinct := 0.25, int64(-1)
+	for _, table := range db.Tables {
+		table.Stats = &schema.Stats{
+			ComputedAt:       time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			SamplingStrategy: "pg_stats",
+			ColumnStats: map[string]schema.ColStats{
+				table.Columns[0].ColumnName: {
+					NullFraction:          &nulls,
+					DistinctCountEstimate: &distinct,
+					Min:                   "",
+					Max:                   "z|z, z",
+					TopValues:             []interface{}{"a,b", "x|y", "tab\there", "-1", int64(3), nil},
+				},
+			},
+		}
+	}
+}
@@ -33,5 +33,5 @@ func (g *Generator) generateDomains(db *schema.Database) error {
 		sort.Strings(grouping.Domains[i].Tables)
 	}
 
-	return g.writeTOON(filepath.Join(g.outputDir, "db.domains.toon"), grouping)
+	return g.writeArtifact(filepath.Join(g.outputDir, "db.domains"), grouping)
 }
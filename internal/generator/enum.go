@@ -1,18 +1,10 @@
 package generator
 
-import (
-	"path/filepath"
-
-	"github.com/nenorrell/X-Rai/internal/schema"
-)
+import "github.com/nenorrell/X-Rai/internal/schema"
 
 func (g *Generator) generateEnums(db *schema.Database) error {
-	enumsDir := filepath.Join(g.outputDir, "enums")
-
 	for _, enum := range db.Enums {
-		filename := sanitizeName(enum.EnumName) + ".toon"
-
-		if err := g.writeTOON(filepath.Join(enumsDir, filename), enum); err != nil {
+		if err := g.writeArtifact(g.path(g.layout.enums[enum]), enum); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,13 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+const environmentFile = "db.environment"
+
+func (g *Generator) generateEnvironment(db *schema.Database) error {
+	return g.writeArtifact(filepath.Join(g.outputDir, environmentFile), db.Environment)
+}
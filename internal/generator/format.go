@@ -0,0 +1,508 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/toon"
+	"gopkg.in/yaml.v3"
+)
+
+// Output format names accepted by ParseFormat.
+const (
+	FormatTOON     = "toon"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+)
+
+// ParseFormat returns the canonical name of an output format, accepting the
+// file extensions "yml" and "md" as aliases.
+func ParseFormat(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case FormatTOON:
+		return FormatTOON, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want toon, json, yaml or markdown)", name)
+}
+
+// artifactWriter encodes artifacts in one output format. Every generated
+// file goes through one, so adding a format never touches the generators.
+type artifactWriter interface {
+	// Format is the name accepted by ParseFormat.
+	Format() string
+
+	// Ext is the file extension, without the dot.
+	Ext() string
+
+	// Write encodes v to w.
+	Write(w io.Writer, v interface{}) error
+}
+
+// newWriters returns a writer for each configured format, in order and
+// without duplicates. No formats means TOON.
+func newWriters(formats []string, opts toon.EncodeOptions) ([]artifactWriter, error) {
+	if len(formats) == 0 {
+		formats = []string{FormatTOON}
+	}
+
+	seen := make(map[string]bool)
+	writers := make([]artifactWriter, 0, len(formats))
+	for _, name := range formats {
+		format, err := ParseFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[format] {
+			continue
+		}
+		seen[format] = true
+
+		switch format {
+		case FormatTOON:
+			if err := opts.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid TOON options: %w", err)
+			}
+			writers = append(writers, toonWriter{opts: opts})
+		case FormatJSON:
+			writers = append(writers, jsonWriter{})
+		case FormatYAML:
+			writers = append(writers, yamlWriter{})
+		case FormatMarkdown:
+			writers = append(writers, markdownWriter{})
+		}
+	}
+	return writers, nil
+}
+
+// writeArtifact writes v once per output format, appending each format's
+// extension to base.
+func (g *Generator) writeArtifact(base string, v interface{}) error {
+	for _, w := range g.writers {
+		if err := g.writeAs(w, base, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAs writes v to base plus the writer's extension.
+func (g *Generator) writeAs(w artifactWriter, base string, v interface{}) error {
+	path := base + "." + w.Ext()
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+
+	if err := w.Write(f, v); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+
+	return g.countFile(w.Ext(), path)
+}
+
+// ext is the extension of the primary (first) output format, which llms.txt
+// points readers at.
+func (g *Generator) ext() string {
+	return g.writers[0].Ext()
+}
+
+// file names an artifact in the primary output format.
+func (g *Generator) file(base string) string {
+	return base + "." + g.ext()
+}
+
+type toonWriter struct {
+	opts toon.EncodeOptions
+}
+
+func (toonWriter) Format() string { return FormatTOON }
+func (toonWriter) Ext() string    { return "toon" }
+
+func (t toonWriter) Write(w io.Writer, v interface{}) error {
+	// Stream straight to the file. List fields such as the relationship
+	// graph's nodes and edges are written one element at a time, so a large
+	// graph is never held in memory as a whole document.
+	enc := toon.NewStreamEncoder(w)
+	if err := enc.SetOptions(t.opts); err != nil {
+		return err
+	}
+	return enc.Encode(v)
+}
+
+type jsonWriter struct{}
+
+func (jsonWriter) Format() string { return FormatJSON }
+func (jsonWriter) Ext() string    { return "json" }
+
+func (jsonWriter) Write(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	// Definitions are SQL; keep <, > and & readable.
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+type yamlWriter struct{}
+
+func (yamlWriter) Format() string { return FormatYAML }
+func (yamlWriter) Ext() string    { return "yaml" }
+
+func (yamlWriter) Write(w io.Writer, v interface{}) error {
+	tree, err := orderedTree(v)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(tree)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// orderedObject is a JSON object that keeps its keys in document order,
+// which for the schema types is struct declaration order.
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// orderedTree converts v into nil, bool, json.Number, string, []interface{}
+// and *orderedObject through its JSON encoding, so YAML and Markdown follow
+// the same json tags, omitempty rules and field order as TOON and JSON.
+func orderedTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return readOrdered(dec)
+}
+
+func readOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := &orderedObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.keys = append(obj.keys, key.(string))
+			obj.values = append(obj.values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	case json.Delim('['):
+		arr := make([]interface{}, 0)
+		for dec.More() {
+			value, err := readOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+
+	return tok, nil
+}
+
+// yamlNode builds a YAML node for an ordered tree value. Scalars carry
+// explicit tags so strings that look like numbers or booleans stay quoted.
+func yamlNode(v interface{}) *yaml.Node {
+	switch val := v.(type) {
+	case *orderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for i, k := range val.keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+				yamlNode(val.values[i]))
+		}
+		return node
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range val {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(val), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(val)}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(val)}
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: val}
+		if strings.Contains(val, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// markdownWriter renders artifacts for reading in a prompt: scalar fields
+// become a bullet list, arrays of flat objects become tables, and nested
+// objects and multi-line text get their own headed sections.
+type markdownWriter struct{}
+
+func (markdownWriter) Format() string { return FormatMarkdown }
+func (markdownWriter) Ext() string    { return "md" }
+
+func (markdownWriter) Write(w io.Writer, v interface{}) error {
+	tree, err := orderedTree(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	m := &markdown{w: bw}
+	if obj, ok := tree.(*orderedObject); ok {
+		m.object(obj, 1)
+	} else {
+		m.value("", tree, 1)
+	}
+	return bw.Flush()
+}
+
+type markdown struct {
+	w *bufio.Writer
+
+	// started is set once anything has been written, so blocks can be
+	// separated by a blank line.
+	started bool
+}
+
+// block starts a new paragraph-level block.
+func (m *markdown) block() {
+	if m.started {
+		m.w.WriteString("\n")
+	}
+	m.started = true
+}
+
+func (m *markdown) heading(level int, title string) {
+	if level > 6 {
+		level = 6
+	}
+	m.block()
+	fmt.Fprintf(m.w, "%s %s\n", strings.Repeat("#", level), title)
+}
+
+// object writes inline fields as one bullet list, then a section for each
+// field that needs more room.
+func (m *markdown) object(obj *orderedObject, level int) {
+	inline := false
+	for i, k := range obj.keys {
+		if !markdownInline(obj.values[i]) {
+			continue
+		}
+		if !inline {
+			m.block()
+			inline = true
+		}
+		text := markdownText(obj.values[i])
+		if list, ok := obj.values[i].([]interface{}); ok && len(list) == 0 {
+			text = "none"
+		}
+		fmt.Fprintf(m.w, "- **%s**: %s\n", k, text)
+	}
+
+	for i, k := range obj.keys {
+		if markdownInline(obj.values[i]) {
+			continue
+		}
+		m.heading(level, k)
+		m.value(k, obj.values[i], level+1)
+	}
+}
+
+// value writes a section body for a value that is not inline.
+func (m *markdown) value(key string, v interface{}, level int) {
+	switch val := v.(type) {
+	case *orderedObject:
+		m.object(val, level)
+	case []interface{}:
+		if fields, ok := markdownColumns(val); ok {
+			m.table(val, fields)
+			return
+		}
+		for i, item := range val {
+			if obj, ok := item.(*orderedObject); ok {
+				m.heading(level, markdownItemTitle(key, i, obj))
+				m.object(obj, level+1)
+				continue
+			}
+			m.block()
+			m.w.WriteString("- " + markdownText(item) + "\n")
+		}
+	case string:
+		m.block()
+		fence := "```"
+		for strings.Contains(val, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(m.w, "%s\n%s\n%s\n", fence, strings.TrimRight(val, "\n"), fence)
+	default:
+		m.block()
+		m.w.WriteString(markdownText(v) + "\n")
+	}
+}
+
+func (m *markdown) table(rows []interface{}, fields []string) {
+	m.block()
+	m.w.WriteString("| " + strings.Join(fields, " | ") + " |\n")
+	m.w.WriteString("|" + strings.Repeat("---|", len(fields)) + "\n")
+	for _, row := range rows {
+		obj := row.(*orderedObject)
+		cells := make([]string, len(fields))
+		for i, k := range obj.keys {
+			for j, f := range fields {
+				if f == k && obj.values[i] != nil {
+					cells[j] = markdownCell(markdownText(obj.values[i]))
+				}
+			}
+		}
+		m.w.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+// markdownInline reports whether v fits on a bullet line: a single-line
+// scalar or a list of them.
+func markdownInline(v interface{}) bool {
+	switch val := v.(type) {
+	case *orderedObject:
+		return false
+	case []interface{}:
+		for _, item := range val {
+			if !markdownInline(item) {
+				return false
+			}
+			if _, ok := item.([]interface{}); ok {
+				return false
+			}
+		}
+		return true
+	case string:
+		return !strings.Contains(val, "\n")
+	}
+	return true
+}
+
+// markdownColumns returns the union of field names, in first-seen order,
+// when every item is an object whose fields are all inline.
+func markdownColumns(arr []interface{}) ([]string, bool) {
+	if len(arr) == 0 {
+		return nil, false
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, item := range arr {
+		obj, ok := item.(*orderedObject)
+		if !ok || len(obj.keys) == 0 {
+			return nil, false
+		}
+		for i, k := range obj.keys {
+			if !markdownInline(obj.values[i]) {
+				return nil, false
+			}
+			if !seen[k] {
+				seen[k] = true
+				fields = append(fields, k)
+			}
+		}
+	}
+	return fields, true
+}
+
+// markdownText formats an inline value. Lists are comma separated and
+// empty lists render as nothing.
+func markdownText(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = markdownText(item)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// markdownCell escapes text for a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// markdownItemTitle heads an array item with its first string field, which
+// for schema objects is their name, or with its position.
+func markdownItemTitle(key string, i int, obj *orderedObject) string {
+	for _, v := range obj.values {
+		if s, ok := v.(string); ok && s != "" && !strings.Contains(s, "\n") {
+			return s
+		}
+	}
+	if key == "" {
+		return fmt.Sprintf("Item %d", i+1)
+	}
+	return fmt.Sprintf("%s %d", key, i+1)
+}
+
+// formats lists the names of the configured formats, as recorded in the
+// manifest.
+func (g *Generator) formats() []string {
+	names := make([]string, len(g.writers))
+	for i, w := range g.writers {
+		names[i] = w.Format()
+	}
+	return names
+}
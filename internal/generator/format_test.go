@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/introspector/ddl"
+	"github.com/nenorrell/X-Rai/internal/toon"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"toon", FormatTOON, false},
+		{"JSON", FormatJSON, false},
+		{"yml", FormatYAML, false},
+		{"md", FormatMarkdown, false},
+		{"markdown", FormatMarkdown, false},
+		{"xml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	type column struct {
+		Name     string   `json:"name"`
+		Nullable bool     `json:"nullable"`
+		Default  *string  `json:"default"`
+		Checks   []string `json:"checks"`
+	}
+	type view struct {
+		ViewName   string   `json:"view_name"`
+		Definition string   `json:"definition"`
+		Columns    []column `json:"columns"`
+		Owner      struct {
+			Role string `json:"role"`
+		} `json:"owner"`
+	}
+
+	v := view{
+		ViewName:   "active_users",
+		Definition: "SELECT *\nFROM users",
+		Columns: []column{
+			{Name: "id", Checks: []string{"id > 0", "a|b"}},
+			{Name: "email", Nullable: true},
+		},
+	}
+	v.Owner.Role = "app"
+
+	var sb strings.Builder
+	if err := (markdownWriter{}).Write(&sb, v); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	expected := "- **view_name**: active_users\n" +
+		"\n# definition\n" +
+		"\n```\nSELECT *\nFROM users\n```\n" +
+		"\n# columns\n" +
+		"\n| name | nullable | default | checks |\n" +
+		"|---|---|---|---|\n" +
+		"| id | false |  | id > 0, a\\|b |\n" +
+		"| email | true |  |  |\n" +
+		"\n# owner\n" +
+		"\n- **role**: app\n"
+	if sb.String() != expected {
+		t.Errorf("Write() =\n%s\nwant:\n%s", sb.String(), expected)
+	}
+}
+
+// TestGenerate_Formats writes every format at once and checks that the JSON
+// and YAML files hold the same data as their TOON counterparts, and that
+// llms.txt points at the primary format.
+func TestGenerate_Formats(t *testing.T) {
+	intro, err := ddl.New(filepath.Join("..", "introspector", "ddl", "testdata", "pg_dump.sql"))
+	if err != nil {
+		t.Fatalf("ddl.New() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+	cfg.Schemas = []string{"public", "billing"}
+	cfg.IncludeViews = true
+	cfg.IncludeRoutines = true
+	cfg.Formats = []string{"json", "toon", "yaml", "markdown", "json"}
+
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	addCatalogInventories(db)
+	if err := New(cfg).Generate(db); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	outDir := filepath.Join(cfg.OutputDir, ".xrai")
+	count := 0
+	err = filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".toon" {
+			return err
+		}
+		count++
+		base := strings.TrimSuffix(path, ".toon")
+		rel, _ := filepath.Rel(outDir, base)
+
+		want := decodeFile(t, path, func(data []byte, v interface{}) error {
+			return toon.Unmarshal(data, v)
+		})
+		got := map[string]interface{}{
+			"json": decodeFile(t, base+".json", json.Unmarshal),
+			"yaml": decodeFile(t, base+".yaml", yaml.Unmarshal),
+		}
+		for format, v := range got {
+			if rel == "xrai.manifest" {
+				// Each manifest names the environment file and counts the
+				// tokens of the files in its own format.
+				m := v.(map[string]interface{})
+				if m["environment_file"] != "db.environment."+format {
+					t.Errorf("%s.%s environment_file = %v", rel, format, m["environment_file"])
+				}
+				files := m["tokens"].(map[string]interface{})["files"].([]interface{})
+				if path := files[0].(map[string]interface{})["path"]; path != "db.collations."+format {
+					t.Errorf("%s.%s counts %v first, want db.collations.%s", rel, format, path, format)
+				}
+				m["environment_file"] = want.(map[string]interface{})["environment_file"]
+				m["tokens"] = want.(map[string]interface{})["tokens"]
+			}
+			if !reflect.DeepEqual(v, want) {
+				t.Errorf("%s.%s differs from %s.toon:\n%v\n---\n%v", rel, format, rel, v, want)
+			}
+		}
+
+		if info, err := os.Stat(base + ".md"); err != nil || info.Size() == 0 {
+			t.Errorf("%s.md missing or empty: %v", rel, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count == 0 {
+		t.Fatal("no TOON files were generated")
+	}
+
+	llms, err := os.ReadFile(filepath.Join(outDir, "llms.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"`db.index.json`", "`tables/<schema>/<name>/table.columns.json`", "`.toon`, `.yaml` and `.md`"} {
+		if !strings.Contains(string(llms), want) {
+			t.Errorf("llms.txt does not mention %s:\n%s", want, llms)
+		}
+	}
+	if strings.Contains(string(llms), "db.index.toon") {
+		t.Errorf("llms.txt references TOON files although JSON is primary:\n%s", llms)
+	}
+}
+
+// decodeFile decodes a file into generic values with numbers normalized
+// through JSON, so decoders that type numbers differently compare equal.
+func decodeFile(t *testing.T, path string, unmarshal func([]byte, interface{}) error) interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v interface{}
+	if err := unmarshal(data, &v); err != nil {
+		t.Fatalf("failed to decode %s: %v", path, err)
+	}
+	data, err = json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to re-encode %s: %v", path, err)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		t.Fatal(err)
+	}
+	return normalized
+}
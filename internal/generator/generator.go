@@ -11,13 +11,25 @@ import (
 	"github.com/nenorrell/X-Rai/internal/config"
 	"github.com/nenorrell/X-Rai/internal/heuristics"
 	"github.com/nenorrell/X-Rai/internal/schema"
-	"github.com/nenorrell/X-Rai/internal/toon"
 )
 
-// Generator handles JSON output generation.
+// Generator writes schema artifacts in each configured output format.
 type Generator struct {
 	cfg       *config.Config
 	outputDir string
+	writers   []artifactWriter
+	layout    *layout
+
+	// Token counts of the files written so far, per format extension;
+	// shared holds llms.txt, which every format's manifest lists.
+	counts map[string][]schema.FileTokens
+	shared []schema.FileTokens
+
+	// Content --token-budget dropped from llms.txt or the bundle, and the
+	// outcome.
+	llmsTrim   trimSet
+	bundleTrim trimSet
+	budget     *schema.TokenBudget
 }
 
 // New creates a new Generator.
@@ -30,6 +42,18 @@ func New(cfg *config.Config) *Generator {
 
 // Generate produces all output files for the database schema.
 func (g *Generator) Generate(db *schema.Database) error {
+	writers, err := newWriters(g.cfg.Formats, g.cfg.TOON)
+	if err != nil {
+		return err
+	}
+	g.writers = writers
+	g.counts = make(map[string][]schema.FileTokens)
+	g.shared = nil
+
+	if err := ValidateBundleSections(g.cfg.BundleExclude); err != nil {
+		return err
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -38,16 +62,19 @@ func (g *Generator) Generate(db *schema.Database) error {
 	// Apply heuristics
 	heuristics.ApplyAllHeuristics(db)
 
+	// Assign every object its path before anything refers to one
+	g.layout = newLayout(db)
+
+	// Decide what to drop to fit the token budget (if set)
+	if err := g.fitBudget(db); err != nil {
+		return fmt.Errorf("failed to fit token budget: %w", err)
+	}
+
 	// Generate llms.txt (LLM entry point)
 	if err := g.generateLLMsTxt(db); err != nil {
 		return fmt.Errorf("failed to generate llms.txt: %w", err)
 	}
 
-	// Generate manifest
-	if err := g.generateManifest(db); err != nil {
-		return fmt.Errorf("failed to generate manifest: %w", err)
-	}
-
 	// Generate database index
 	if err := g.generateDatabaseIndex(db); err != nil {
 		return fmt.Errorf("failed to generate database index: %w", err)
@@ -58,11 +85,39 @@ func (g *Generator) Generate(db *schema.Database) error {
 		return fmt.Errorf("failed to generate relationships: %w", err)
 	}
 
+	// Generate environment snapshot
+	if db.Environment != nil {
+		if err := g.generateEnvironment(db); err != nil {
+			return fmt.Errorf("failed to generate environment: %w", err)
+		}
+	}
+
+	// Generate collation inventory
+	if len(db.Collations) > 0 {
+		if err := g.generateCollations(db); err != nil {
+			return fmt.Errorf("failed to generate collations: %w", err)
+		}
+	}
+
+	// Generate text search inventory
+	if len(db.TextSearchConfigs) > 0 {
+		if err := g.generateTextSearch(db); err != nil {
+			return fmt.Errorf("failed to generate text search: %w", err)
+		}
+	}
+
 	// Generate domain groupings
 	if err := g.generateDomains(db); err != nil {
 		return fmt.Errorf("failed to generate domains: %w", err)
 	}
 
+	// Generate schema artifacts
+	if len(db.Namespaces) > 0 {
+		if err := g.generateSchemas(db); err != nil {
+			return fmt.Errorf("failed to generate schemas: %w", err)
+		}
+	}
+
 	// Generate table artifacts
 	if err := g.generateTables(db); err != nil {
 		return fmt.Errorf("failed to generate tables: %w", err)
@@ -103,23 +158,16 @@ func (g *Generator) Generate(db *schema.Database) error {
 		}
 	}
 
-	return nil
-}
-
-// writeTOON writes a value as TOON format to a file.
-func (g *Generator) writeTOON(path string, v interface{}) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	data, err := toon.Marshal(v)
-	if err != nil {
-		return fmt.Errorf("failed to marshal TOON: %w", err)
+	// Generate single-file bundle (if enabled)
+	if g.cfg.Bundle {
+		if err := g.generateBundle(db); err != nil {
+			return fmt.Errorf("failed to generate bundle: %w", err)
+		}
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", path, err)
+	// Generate manifest last, so it can record every file's token count
+	if err := g.generateManifest(db); err != nil {
+		return fmt.Errorf("failed to generate manifest: %w", err)
 	}
 
 	return nil
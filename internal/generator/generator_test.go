@@ -296,3 +296,128 @@ func TestWriteTableIndex(t *testing.T) {
 		t.Error("expected Tables section for uncategorized")
 	}
 }
+
+func TestWriteSchemaSummary(t *testing.T) {
+	namespaces := []*schema.Namespace{
+		{SchemaName: "mart", Comment: "Reporting tables rebuilt nightly", ObjectCounts: schema.ObjectCounts{Tables: 4}},
+		{SchemaName: "staging", ObjectCounts: schema.ObjectCounts{Tables: 12}},
+	}
+
+	if !hasSchemaComments(namespaces) {
+		t.Fatal("expected hasSchemaComments to be true")
+	}
+
+	var sb strings.Builder
+	writeSchemaSummary(&sb, namespaces)
+	result := sb.String()
+
+	if !strings.Contains(result, "- `mart` (4 tables): Reporting tables rebuilt nightly\n") {
+		t.Errorf("expected mart line with comment, got %q", result)
+	}
+	if !strings.Contains(result, "- `staging` (12 tables)\n") {
+		t.Errorf("expected staging line without comment, got %q", result)
+	}
+
+	if hasSchemaComments([]*schema.Namespace{{SchemaName: "public"}}) {
+		t.Error("expected hasSchemaComments to be false without comments")
+	}
+}
+
+func TestWriteSpatialSummary(t *testing.T) {
+	tables := []*schema.Table{
+		{
+			SchemaName: "public",
+			TableName:  "stores",
+			Columns: []*schema.Column{
+				{ColumnName: "id"},
+				{ColumnName: "location", Spatial: &schema.SpatialInfo{Kind: "geometry", GeometryType: "POINT", SRID: 4326, CoordDimension: 2}},
+			},
+			Indexes: []*schema.Index{
+				{IndexName: "stores_location_idx", IndexType: "gist", Columns: []string{"location"}, Spatial: true},
+			},
+		},
+		{
+			SchemaName: "geo",
+			TableName:  "regions",
+			Columns: []*schema.Column{
+				{ColumnName: "area", Spatial: &schema.SpatialInfo{Kind: "geography", GeometryType: "MultiPolygon", SRID: 4326, CoordDimension: 2}},
+			},
+		},
+	}
+
+	if !hasSpatialColumns(tables) {
+		t.Fatal("expected hasSpatialColumns to be true")
+	}
+
+	var sb strings.Builder
+	writeSpatialSummary(&sb, tables)
+	result := sb.String()
+
+	if !strings.Contains(result, "- `public.stores.location`: geometry(POINT, 4326) (indexed)\n") {
+		t.Errorf("expected indexed public.stores.location line, got %q", result)
+	}
+	if !strings.Contains(result, "- `geo.regions.area`: geography(MultiPolygon, 4326)\n") {
+		t.Errorf("expected geo.regions.area line, got %q", result)
+	}
+}
+
+func TestWriteVectorSummary(t *testing.T) {
+	tables := []*schema.Table{
+		{
+			SchemaName: "public",
+			TableName:  "documents",
+			Columns: []*schema.Column{
+				{ColumnName: "embedding", Vector: &schema.VectorInfo{
+					Type:       "vector",
+					Dimensions: 1536,
+					Indexes: []schema.VectorIndex{
+						{IndexName: "documents_embedding_idx", Method: "hnsw", Distance: "cosine", Operator: "<=>"},
+					},
+				}},
+			},
+		},
+	}
+
+	if !hasVectorColumns(tables) {
+		t.Fatal("expected hasVectorColumns to be true")
+	}
+
+	var sb strings.Builder
+	writeVectorSummary(&sb, tables)
+	result := sb.String()
+
+	expected := "- `public.documents.embedding`: vector(1536); hnsw cosine → `ORDER BY embedding <=> $1`\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected %q in %q", expected, result)
+	}
+}
+
+func TestWriteSearchSummary(t *testing.T) {
+	tables := []*schema.Table{
+		{
+			SchemaName: "public",
+			TableName:  "articles",
+			Search: &schema.TableSearch{
+				FullText: []schema.FullTextSource{{Column: "search", Config: "english"}},
+				Trigram:  []schema.TrigramSource{{Column: "title", IndexName: "articles_title_trgm", IndexType: "gin"}},
+			},
+		},
+		{SchemaName: "public", TableName: "users"},
+	}
+
+	if !hasSearch(tables) {
+		t.Fatal("expected hasSearch to be true")
+	}
+
+	var sb strings.Builder
+	writeSearchSummary(&sb, tables, "toon")
+	result := sb.String()
+
+	expected := "- `public.articles`: `search` @@ (english), `title` % (trigram)\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected %q in %q", expected, result)
+	}
+	if strings.Contains(result, "`public.users`") {
+		t.Errorf("did not expect users in search summary: %q", result)
+	}
+}
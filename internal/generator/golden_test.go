@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"context"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden output files")
+
+// TestGenerate_Golden runs the full pipeline over a fixture schema and
+// compares the deterministic outputs against testdata/golden.
+func TestGenerate_Golden(t *testing.T) {
+	intro, err := fixture.New(filepath.Join("testdata", "shop.yaml"))
+	if err != nil {
+		t.Fatalf("fixture.New() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	if err := New(cfg).Generate(db); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	outDir := filepath.Join(cfg.OutputDir, ".xrai")
+	var files []string
+	err = filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(outDir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+
+	compareGolden(t, "files.txt", strings.Join(files, "\n")+"\n")
+
+	// TOON output is deterministic, so every file is compared byte for byte;
+	// only the manifest's generation timestamp varies between runs.
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareGolden(t, name, maskTimestamp(string(data)))
+	}
+}
+
+// TestGenerate_Deterministic generates the same schema twice and expects
+// byte-identical output.
+func TestGenerate_Deterministic(t *testing.T) {
+	intro, err := fixture.New(filepath.Join("testdata", "shop.yaml"))
+	if err != nil {
+		t.Fatalf("fixture.New() error = %v", err)
+	}
+
+	var runs [2]map[string]string
+	for i := range runs {
+		cfg := config.NewConfig()
+		cfg.OutputDir = t.TempDir()
+
+		db, err := intro.Introspect(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("Introspect() error = %v", err)
+		}
+		if err := New(cfg).Generate(db); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		runs[i] = map[string]string{}
+		outDir := filepath.Join(cfg.OutputDir, ".xrai")
+		err = filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(outDir, path)
+			runs[i][rel] = maskTimestamp(string(data))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(runs[0]) != len(runs[1]) {
+		t.Fatalf("runs wrote %d and %d files", len(runs[0]), len(runs[1]))
+	}
+	for name, first := range runs[0] {
+		if second := runs[1][name]; second != first {
+			t.Errorf("%s differs between runs:\n%s\n---\n%s", name, first, second)
+		}
+	}
+}
+
+var timestampRe = regexp.MustCompile(`(?m)^generation_timestamp: .*$`)
+
+func maskTimestamp(s string) string {
+	return timestampRe.ReplaceAllString(s, "generation_timestamp: <timestamp>")
+}
+
+func compareGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", filepath.FromSlash(name))
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file %s (run with -update): %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from golden file; run go test ./internal/generator -run Golden -update and review the diff", name)
+	}
+}
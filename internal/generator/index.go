@@ -8,6 +8,11 @@ import (
 )
 
 func (g *Generator) generateDatabaseIndex(db *schema.Database) error {
+	return g.writeArtifact(filepath.Join(g.outputDir, "db.index"), g.databaseIndex(db))
+}
+
+// databaseIndex lists every table with its key counts and tags.
+func (g *Generator) databaseIndex(db *schema.Database) schema.DatabaseIndex {
 	index := schema.DatabaseIndex{
 		Tables: make([]schema.TableIndexEntry, 0, len(db.Tables)),
 	}
@@ -16,6 +21,7 @@ func (g *Generator) generateDatabaseIndex(db *schema.Database) error {
 		entry := schema.TableIndexEntry{
 			TableName:          table.TableName,
 			SchemaName:         table.SchemaName,
+			Path:               g.layout.tables[table] + "/",
 			RowCountEstimate:   table.RowCountEstimate,
 			ForeignKeyOutCount: len(table.OutgoingForeignKeys),
 			ForeignKeyInCount:  len(table.IncomingForeignKeys),
@@ -41,10 +47,43 @@ func (g *Generator) generateDatabaseIndex(db *schema.Database) error {
 		return index.Tables[i].TableName < index.Tables[j].TableName
 	})
 
+	index.Objects = g.objectIndex(db)
+
 	// Determine recommended start tables
 	index.RecommendedStartTables = findRecommendedStartTables(db.Tables)
 
-	return g.writeTOON(filepath.Join(g.outputDir, "db.index.toon"), index)
+	return index
+}
+
+// objectIndex lists where every generated view, routine, enum, sequence and
+// type was written.
+func (g *Generator) objectIndex(db *schema.Database) []schema.ObjectIndexEntry {
+	var objects []schema.ObjectIndexEntry
+	add := func(kind, name, schemaName, path string) {
+		objects = append(objects, schema.ObjectIndexEntry{Kind: kind, Name: name, SchemaName: schemaName, Path: path})
+	}
+
+	if g.cfg.IncludeViews {
+		for _, view := range db.Views {
+			add("view", view.ViewName, view.SchemaName, g.layout.views[view]+"/")
+		}
+	}
+	if g.cfg.IncludeRoutines {
+		for _, routine := range db.Routines {
+			add(routine.RoutineType, routine.RoutineName, routine.SchemaName, g.file(g.layout.routines[routine]))
+		}
+	}
+	for _, enum := range db.Enums {
+		add("enum", enum.EnumName, enum.SchemaName, g.file(g.layout.enums[enum]))
+	}
+	for _, seq := range db.Sequences {
+		add("sequence", seq.SequenceName, seq.SchemaName, g.file(g.layout.sequences[seq]))
+	}
+	for _, t := range db.Types {
+		add("type", t.TypeName, t.SchemaName, g.file(g.layout.types[t]))
+	}
+
+	return objects
 }
 
 func getPrimaryKeyColumns(table *schema.Table) []string {
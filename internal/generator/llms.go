@@ -8,9 +8,20 @@ import (
 	"strings"
 
 	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/tokens"
 )
 
 func (g *Generator) generateLLMsTxt(db *schema.Database) error {
+	content := g.llmsTxt(db, g.llmsTrim)
+	if err := g.writeFile(filepath.Join(g.outputDir, "llms.txt"), content); err != nil {
+		return err
+	}
+	g.shared = append(g.shared, schema.FileTokens{Path: "llms.txt", Tokens: tokens.Count(content)})
+	return nil
+}
+
+// llmsTxt renders llms.txt, leaving out the sections in trim.
+func (g *Generator) llmsTxt(db *schema.Database, trim trimSet) string {
 	var sb strings.Builder
 
 	// Header - brief and action-oriented
@@ -26,26 +37,59 @@ func (g *Generator) generateLLMsTxt(db *schema.Database) error {
 	sb.WriteString(db.Version)
 	sb.WriteString(").\n\n")
 
+	if len(g.writers) > 1 {
+		others := make([]string, 0, len(g.writers)-1)
+		for _, w := range g.writers[1:] {
+			others = append(others, "`."+w.Ext()+"`")
+		}
+		list := others[0]
+		if len(others) > 1 {
+			list = strings.Join(others[:len(others)-1], ", ") + " and " + others[len(others)-1]
+		}
+		fmt.Fprintf(&sb, "Every file is also available as %s alongside the `.%s` file named below.\n\n", list, g.ext())
+	}
+
+	if g.ext() == "toon" && g.cfg.TOON.InlineLists {
+		sep := string(rune(g.cfg.TOON.ListDelimiter()))
+		fmt.Fprintf(&sb, "In tabular arrays, a field written `name[%s]` holds a list: its items are separated by `%s` within the row, and an empty cell is an empty list.\n\n", sep, sep)
+	}
+
 	// Discovery guide - the key section
 	sb.WriteString("## Finding What You Need\n\n")
 	sb.WriteString("| Task | File to Read |\n")
 	sb.WriteString("|------|-------------|\n")
-	sb.WriteString("| List all tables | `db.index.toon` |\n")
-	sb.WriteString("| See how tables connect | `db.relationships.toon` |\n")
-	sb.WriteString("| Find tables by domain/feature | `db.domains.toon` |\n")
-	sb.WriteString("| Get columns for a table | `tables/<name>/table.columns.toon` |\n")
-	sb.WriteString("| Get primary/foreign keys | `tables/<name>/table.relations.toon` |\n")
-	sb.WriteString("| Check indexes/constraints | `tables/<name>/table.indexes.toon` |\n")
+	if g.cfg.Bundle {
+		fmt.Fprintf(&sb, "| Whole schema in one file | `%s` |\n", g.file(bundleFile))
+	}
+	fmt.Fprintf(&sb, "| List all tables | `%s` |\n", g.file("db.index"))
+	if len(db.Namespaces) > 0 {
+		fmt.Fprintf(&sb, "| What a schema is for | `%s` |\n", g.file("schemas/<schema>"))
+	}
+	fmt.Fprintf(&sb, "| See how tables connect | `%s` |\n", g.file("db.relationships"))
+	fmt.Fprintf(&sb, "| Find tables by domain/feature | `%s` |\n", g.file("db.domains"))
+	if db.Environment != nil {
+		fmt.Fprintf(&sb, "| Time zone, collation, search_path | `%s` |\n", g.file("db.environment"))
+	}
+	fmt.Fprintf(&sb, "| Get columns for a table | `%s` |\n", g.file("tables/<schema>/<name>/table.columns"))
+	fmt.Fprintf(&sb, "| Get primary/foreign keys | `%s` |\n", g.file("tables/<schema>/<name>/table.relations"))
+	fmt.Fprintf(&sb, "| Check indexes/constraints | `%s` |\n", g.file("tables/<schema>/<name>/table.indexes"))
 	if len(db.Enums) > 0 {
-		sb.WriteString("| Look up enum values | `enums/<name>.toon` |\n")
+		fmt.Fprintf(&sb, "| Look up enum values | `%s` |\n", g.file("enums/<schema>/<name>"))
+	}
+	if len(db.Collations) > 0 {
+		fmt.Fprintf(&sb, "| Column collations (sorting, LIKE) | `%s` |\n", g.file("db.collations"))
+	}
+	if len(db.TextSearchConfigs) > 0 {
+		fmt.Fprintf(&sb, "| Full-text search configurations | `%s` |\n", g.file("db.text-search"))
 	}
 	if len(db.Views) > 0 {
-		sb.WriteString("| View definitions | `views/<name>/view.definition.toon` |\n")
+		fmt.Fprintf(&sb, "| View definitions | `%s` |\n", g.file("views/<schema>/<name>/view.definition"))
 	}
 	if len(db.Routines) > 0 {
-		sb.WriteString("| Function/procedure code | `routines/functions/<name>.toon` |\n")
+		fmt.Fprintf(&sb, "| Function/procedure code | `%s` |\n", g.file("routines/functions/<schema>/<name>"))
 	}
 	sb.WriteString("\n")
+	fmt.Fprintf(&sb, "Directory and file names are lowercased, with anything but letters, digits and `_` replaced; names that would clash get a short suffix. The `path` field in `%s` gives every object's exact location.\n\n", g.file("db.index"))
 
 	// Quick stats
 	sb.WriteString("## At a Glance\n\n")
@@ -61,19 +105,43 @@ func (g *Generator) generateLLMsTxt(db *schema.Database) error {
 	}
 	sb.WriteString("\n")
 
+	// Schema purposes from COMMENT ON SCHEMA
+	if hasSchemaComments(db.Namespaces) && !trim["comments"] {
+		sb.WriteString("## Schemas\n\n")
+		writeSchemaSummary(&sb, db.Namespaces)
+	}
+
 	// Table index - organized by importance
 	sb.WriteString("## Table Index\n\n")
 	writeTableIndex(&sb, db.Tables)
 
 	// Relationship hints - where to start exploring
-	if hasRelationships(db.Tables) {
+	if hasRelationships(db.Tables) && !trim["entry_points"] {
 		sb.WriteString("## Key Entry Points\n\n")
 		sb.WriteString("Start exploring from these highly-connected tables:\n\n")
 		writeEntryPoints(&sb, db.Tables)
 	}
 
+	// Spatial summary (PostGIS)
+	if hasSpatialColumns(db.Tables) && !trim["search_hints"] {
+		sb.WriteString("## Spatial Data\n\n")
+		writeSpatialSummary(&sb, db.Tables)
+	}
+
+	// Vector search summary (pgvector)
+	if hasVectorColumns(db.Tables) && !trim["search_hints"] {
+		sb.WriteString("## Vector Search\n\n")
+		writeVectorSummary(&sb, db.Tables)
+	}
+
+	// Full-text / trigram search summary
+	if hasSearch(db.Tables) && !trim["search_hints"] {
+		sb.WriteString("## Text Search\n\n")
+		writeSearchSummary(&sb, db.Tables, g.ext())
+	}
+
 	// Enums quick reference
-	if len(db.Enums) > 0 {
+	if len(db.Enums) > 0 && !trim["enum_reference"] {
 		sb.WriteString("## Enum Quick Reference\n\n")
 		for _, e := range db.Enums {
 			sb.WriteString(fmt.Sprintf("- **%s**: `%s`\n", e.EnumName, strings.Join(e.Values, "`, `")))
@@ -81,7 +149,7 @@ func (g *Generator) generateLLMsTxt(db *schema.Database) error {
 		sb.WriteString("\n")
 	}
 
-	return g.writeFile(filepath.Join(g.outputDir, "llms.txt"), sb.String())
+	return sb.String()
 }
 
 func formatSchemaList(schemas []string) string {
@@ -91,6 +159,27 @@ func formatSchemaList(schemas []string) string {
 	return fmt.Sprintf("%d schemas (%s)", len(schemas), strings.Join(schemas, ", "))
 }
 
+func hasSchemaComments(namespaces []*schema.Namespace) bool {
+	for _, ns := range namespaces {
+		if ns.Comment != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeSchemaSummary(sb *strings.Builder, namespaces []*schema.Namespace) {
+	for _, ns := range namespaces {
+		sb.WriteString(fmt.Sprintf("- `%s` (%d tables)", ns.SchemaName, ns.ObjectCounts.Tables))
+		if ns.Comment != "" {
+			sb.WriteString(": ")
+			sb.WriteString(ns.Comment)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
+
 func writeTableIndex(sb *strings.Builder, tables []*schema.Table) {
 	// Group tables
 	core := make([]*schema.Table, 0)
@@ -206,6 +295,125 @@ func writeEntryPoints(sb *strings.Builder, tables []*schema.Table) {
 	sb.WriteString("\n")
 }
 
+func hasSpatialColumns(tables []*schema.Table) bool {
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			if col.Spatial != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeSpatialSummary(sb *strings.Builder, tables []*schema.Table) {
+	sb.WriteString("PostGIS columns. Use `ST_` functions, match the column SRID ")
+	sb.WriteString("(`ST_SetSRID`/`ST_Transform`), and prefer index-aware predicates like `ST_DWithin` and `ST_Intersects`.\n\n")
+
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			if col.Spatial == nil {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("- `%s.%s.%s`: %s(%s, %d)",
+				t.SchemaName, t.TableName, col.ColumnName, col.Spatial.Kind, col.Spatial.GeometryType, col.Spatial.SRID))
+			if spatialIndexed(t, col.ColumnName) {
+				sb.WriteString(" (indexed)")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
+func spatialIndexed(t *schema.Table, column string) bool {
+	for _, idx := range t.Indexes {
+		if !idx.Spatial {
+			continue
+		}
+		for _, c := range idx.Columns {
+			if c == column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasVectorColumns(tables []*schema.Table) bool {
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			if col.Vector != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeVectorSummary(sb *strings.Builder, tables []*schema.Table) {
+	sb.WriteString("pgvector columns. Order by the operator that matches the index so the ANN index is used.\n\n")
+
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			if col.Vector == nil {
+				continue
+			}
+			typ := col.Vector.Type
+			if col.Vector.Dimensions > 0 {
+				typ = fmt.Sprintf("%s(%d)", typ, col.Vector.Dimensions)
+			}
+			sb.WriteString(fmt.Sprintf("- `%s.%s.%s`: %s", t.SchemaName, t.TableName, col.ColumnName, typ))
+			for _, idx := range col.Vector.Indexes {
+				if idx.Operator == "" {
+					continue
+				}
+				sb.WriteString(fmt.Sprintf("; %s %s → `ORDER BY %s %s $1`",
+					idx.Method, idx.Distance, col.ColumnName, idx.Operator))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
+func hasSearch(tables []*schema.Table) bool {
+	for _, t := range tables {
+		if t.Search != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func writeSearchSummary(sb *strings.Builder, tables []*schema.Table, ext string) {
+	sb.WriteString("Use `@@` against tsvector sources and `%`/`ILIKE` on trigram-indexed columns instead of unindexed `ILIKE` scans. ")
+	fmt.Fprintf(sb, "Details in each table's `search` section of `table.structure.%s`.\n\n", ext)
+
+	for _, t := range tables {
+		if t.Search == nil {
+			continue
+		}
+		parts := make([]string, 0)
+		for _, ft := range t.Search.FullText {
+			target := ft.Column
+			if target == "" {
+				target = ft.Expression
+			}
+			part := fmt.Sprintf("`%s` @@", target)
+			if ft.Config != "" {
+				part += fmt.Sprintf(" (%s)", ft.Config)
+			}
+			parts = append(parts, part)
+		}
+		for _, tg := range t.Search.Trigram {
+			parts = append(parts, fmt.Sprintf("`%s` %% (trigram)", tg.Column))
+		}
+		sb.WriteString(fmt.Sprintf("- `%s.%s`: %s\n", t.SchemaName, t.TableName, strings.Join(parts, ", ")))
+	}
+	sb.WriteString("\n")
+}
+
 func (g *Generator) writeFile(path string, content string) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
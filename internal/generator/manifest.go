@@ -6,8 +6,31 @@ import (
 	"github.com/nenorrell/X-Rai/internal/schema"
 )
 
+// manifestFile is the manifest's base name. It is written last and left out
+// of the token counts it records.
+const manifestFile = "xrai.manifest"
+
 func (g *Generator) generateManifest(db *schema.Database) error {
-	manifest := schema.Manifest{
+	manifest := g.manifest(db)
+	manifest.TokenBudget = g.budget
+
+	// Each format's manifest points at the environment file and counts the
+	// files in that format.
+	for _, w := range g.writers {
+		if db.Environment != nil {
+			manifest.EnvironmentFile = environmentFile + "." + w.Ext()
+		}
+		manifest.Tokens = g.tokenCounts(w.Ext())
+		if err := g.writeAs(w, filepath.Join(g.outputDir, manifestFile), manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manifest describes the snapshot: its source, scope and what was written.
+func (g *Generator) manifest(db *schema.Database) schema.Manifest {
+	return schema.Manifest{
 		GenerationTimestamp: timestamp(),
 		DatabaseEngine:      db.Engine,
 		DatabaseVersion:     db.Version,
@@ -15,17 +38,21 @@ func (g *Generator) generateManifest(db *schema.Database) error {
 		IncludedSchemas:     db.Schemas,
 		IncludedTablesCount: len(db.Tables),
 		EnabledArtifacts: schema.EnabledArtifacts{
-			Tables:    true,
-			Views:     g.cfg.IncludeViews && len(db.Views) > 0,
-			Routines:  g.cfg.IncludeRoutines && len(db.Routines) > 0,
-			Enums:     len(db.Enums) > 0,
-			Sequences: len(db.Sequences) > 0,
-			Types:     len(db.Types) > 0,
-			Stats:     g.cfg.IncludeStats,
+			Schemas:    len(db.Namespaces) > 0,
+			Tables:     true,
+			Views:      g.cfg.IncludeViews && len(db.Views) > 0,
+			Routines:   g.cfg.IncludeRoutines && len(db.Routines) > 0,
+			Enums:      len(db.Enums) > 0,
+			Sequences:  len(db.Sequences) > 0,
+			Types:      len(db.Types) > 0,
+			Collations: len(db.Collations) > 0,
+			TextSearch: len(db.TextSearchConfigs) > 0,
+			Stats:      g.cfg.IncludeStats,
 		},
-		StatsEnabled: g.cfg.IncludeStats,
-		UsageEnabled: false, // Usage heuristics not implemented yet
+		StatsEnabled:        g.cfg.IncludeStats,
+		UsageEnabled:        false, // Usage heuristics not implemented yet
+		Formats:             g.formats(),
+		TOONKeyFolding:      g.cfg.TOON.KeyFolding,
+		UnavailableFeatures: db.Unavailable,
 	}
-
-	return g.writeTOON(filepath.Join(g.outputDir, "xrai.manifest.toon"), manifest)
 }
@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// layout maps every object to its location in the snapshot: a slash-separated
+// path relative to the output directory, without a format extension. Objects
+// live under their schema's directory, so public.invoices and
+// billing.invoices never share one.
+type layout struct {
+	schemas   map[string]string // schema name -> directory name
+	tables    map[*schema.Table]string
+	views     map[*schema.View]string
+	routines  map[*schema.Routine]string
+	enums     map[*schema.Enum]string
+	sequences map[*schema.Sequence]string
+	types     map[*schema.Type]string
+}
+
+// named is an object to be given a file name: key identifies it, name is
+// what the file name is derived from.
+type named struct {
+	key  string
+	name string
+}
+
+// newLayout assigns every object in db a path.
+func newLayout(db *schema.Database) *layout {
+	l := &layout{}
+
+	var schemas []named
+	seen := map[string]bool{}
+	addSchema := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			schemas = append(schemas, named{key: name, name: name})
+		}
+	}
+	for _, ns := range db.Namespaces {
+		addSchema(ns.SchemaName)
+	}
+	for _, t := range db.Tables {
+		addSchema(t.SchemaName)
+	}
+	for _, v := range db.Views {
+		addSchema(v.SchemaName)
+	}
+	for _, r := range db.Routines {
+		addSchema(r.SchemaName)
+	}
+	for _, e := range db.Enums {
+		addSchema(e.SchemaName)
+	}
+	for _, s := range db.Sequences {
+		addSchema(s.SchemaName)
+	}
+	for _, t := range db.Types {
+		addSchema(t.SchemaName)
+	}
+	l.schemas = uniqueNames(schemas)
+
+	l.tables = place(l.schemas, db.Tables, func(t *schema.Table) placement {
+		return placement{"tables", t.SchemaName, t.TableName, t.TableName}
+	})
+	l.views = place(l.schemas, db.Views, func(v *schema.View) placement {
+		return placement{"views", v.SchemaName, v.ViewName, v.ViewName}
+	})
+	l.routines = place(l.schemas, db.Routines, func(r *schema.Routine) placement {
+		dir := "routines/functions"
+		if r.RoutineType == "procedure" {
+			dir = "routines/procedures"
+		}
+		return placement{dir, r.SchemaName, r.RoutineName, routineSignature(r)}
+	})
+	l.enums = place(l.schemas, db.Enums, func(e *schema.Enum) placement {
+		return placement{"enums", e.SchemaName, e.EnumName, e.EnumName}
+	})
+	l.sequences = place(l.schemas, db.Sequences, func(s *schema.Sequence) placement {
+		return placement{"sequences", s.SchemaName, s.SequenceName, s.SequenceName}
+	})
+	l.types = place(l.schemas, db.Types, func(t *schema.Type) placement {
+		return placement{"types", t.SchemaName, t.TypeName, t.TypeName}
+	})
+
+	return l
+}
+
+// placement says where an object goes: the top-level directory, its schema,
+// the name its file name is derived from, and a key unique within the schema.
+type placement struct {
+	dir    string
+	schema string
+	name   string
+	key    string
+}
+
+// place gives each object a path of the form dir/<schema>/<name>. Names
+// only need to be unique within one directory.
+func place[T comparable](schemaDirs map[string]string, objects []T, where func(T) placement) map[T]string {
+	groups := map[string][]named{}
+	for _, obj := range objects {
+		p := where(obj)
+		dir := path.Join(p.dir, schemaDirs[p.schema])
+		groups[dir] = append(groups[dir], named{key: p.key, name: p.name})
+	}
+
+	names := make(map[string]map[string]string, len(groups))
+	for dir, group := range groups {
+		names[dir] = uniqueNames(group)
+	}
+
+	paths := make(map[T]string, len(objects))
+	for _, obj := range objects {
+		p := where(obj)
+		dir := path.Join(p.dir, schemaDirs[p.schema])
+		paths[obj] = path.Join(dir, names[dir][p.key])
+	}
+	return paths
+}
+
+// routineSignature identifies an overload: the name and argument types.
+func routineSignature(r *schema.Routine) string {
+	args := make([]string, 0, len(r.Arguments))
+	for _, arg := range r.Arguments {
+		if arg.Mode != "OUT" {
+			args = append(args, arg.DataType)
+		}
+	}
+	return r.RoutineName + "(" + strings.Join(args, ",") + ")"
+}
+
+// uniqueNames gives each object a filesystem-safe name, keyed by object key.
+// When sanitizing folds several objects onto one name (Order-Items and
+// order_items, or overloads of a function), an object whose name was already
+// safe keeps it, then the first by key; the others get a suffix hashed from
+// their key.
+func uniqueNames(objects []named) map[string]string {
+	sorted := append([]named(nil), objects...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if safeA, safeB := sanitizeName(a.name) == a.name, sanitizeName(b.name) == b.name; safeA != safeB {
+			return safeA
+		}
+		return a.key < b.key
+	})
+
+	names := make(map[string]string, len(sorted))
+	taken := make(map[string]bool, len(sorted))
+	for _, obj := range sorted {
+		if _, ok := names[obj.key]; ok {
+			continue
+		}
+		name := sanitizeName(obj.name)
+		if taken[name] {
+			sum := sha1.Sum([]byte(obj.key))
+			name += "_" + hex.EncodeToString(sum[:4])
+		}
+		taken[name] = true
+		names[obj.key] = name
+	}
+	return names
+}
+
+// path converts a layout path to one under the output directory.
+func (g *Generator) path(rel string) string {
+	return filepath.Join(g.outputDir, filepath.FromSlash(rel))
+}
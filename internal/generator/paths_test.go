@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/toon"
+)
+
+func TestUniqueNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []named
+		expected map[string]string
+	}{
+		{
+			"distinct",
+			[]named{{"users", "users"}, {"orders", "orders"}},
+			map[string]string{"users": "users", "orders": "orders"},
+		},
+		{
+			"safe name wins regardless of order",
+			[]named{{"Order-Items", "Order-Items"}, {"order_items", "order_items"}},
+			map[string]string{"order_items": "order_items", "Order-Items": "order_items_6a111a8d"},
+		},
+		{
+			"overloads",
+			[]named{{"total(integer)", "total"}, {"total()", "total"}},
+			map[string]string{"total()": "total", "total(integer)": "total_978493df"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uniqueNames(tt.objects)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("uniqueNames() = %v, want %v", got, tt.expected)
+			}
+			for key, want := range tt.expected {
+				if got[key] != want {
+					t.Errorf("uniqueNames()[%q] = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+// TestGenerate_CollidingNames checks that tables sharing a name across
+// schemas, or folding to the same name, each get their own directory, and
+// that db.index points at it.
+func TestGenerate_CollidingNames(t *testing.T) {
+	table := func(schemaName, name string) *schema.Table {
+		return &schema.Table{
+			SchemaName: schemaName,
+			TableName:  name,
+			TableType:  "BASE TABLE",
+			Columns:    []*schema.Column{{ColumnName: "id", DataType: "bigint", OrdinalPosition: 1}},
+		}
+	}
+
+	db := &schema.Database{
+		Name:    "collide",
+		Engine:  "postgresql",
+		Version: "16",
+		Schemas: []string{"billing", "public"},
+		Tables: []*schema.Table{
+			table("billing", "invoices"),
+			table("public", "Order-Items"),
+			table("public", "invoices"),
+			table("public", "order_items"),
+		},
+		Enums: []*schema.Enum{
+			{SchemaName: "billing", EnumName: "status", Values: []string{"open"}},
+			{SchemaName: "public", EnumName: "status", Values: []string{"active"}},
+		},
+	}
+
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+	if err := New(cfg).Generate(db); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	outDir := filepath.Join(cfg.OutputDir, ".xrai")
+
+	data, err := os.ReadFile(filepath.Join(outDir, "db.index.toon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index schema.DatabaseIndex
+	if err := toon.Unmarshal(data, &index); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	paths := map[string]string{}
+	for _, entry := range index.Tables {
+		paths[entry.SchemaName+"."+entry.TableName] = entry.Path
+	}
+	for _, entry := range index.Objects {
+		paths[entry.Kind+" "+entry.SchemaName+"."+entry.Name] = entry.Path
+	}
+
+	want := map[string]string{
+		"billing.invoices":    "tables/billing/invoices/",
+		"public.invoices":     "tables/public/invoices/",
+		"public.order_items":  "tables/public/order_items/",
+		"public.Order-Items":  "tables/public/order_items_6a111a8d/",
+		"enum billing.status": "enums/billing/status.toon",
+		"enum public.status":  "enums/public/status.toon",
+	}
+	for key, path := range want {
+		if paths[key] != path {
+			t.Errorf("index path of %s = %q, want %q", key, paths[key], path)
+		}
+	}
+
+	// Each table's structure file names the table it describes.
+	for key, path := range paths {
+		if filepath.Ext(path) != "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(path), "table.structure.toon"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var structure schema.TableStructure
+		if err := toon.Unmarshal(data, &structure); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if got := structure.SchemaName + "." + structure.TableName; got != key {
+			t.Errorf("%s holds %s, want %s", path, got, key)
+		}
+	}
+}
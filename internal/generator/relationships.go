@@ -8,6 +8,11 @@ import (
 )
 
 func (g *Generator) generateRelationships(db *schema.Database) error {
+	return g.writeArtifact(filepath.Join(g.outputDir, "db.relationships"), relationshipGraph(db))
+}
+
+// relationshipGraph builds the foreign key and inheritance graph.
+func relationshipGraph(db *schema.Database) schema.RelationshipGraph {
 	graph := schema.RelationshipGraph{
 		Nodes: make([]schema.RelationshipNode, 0, len(db.Tables)),
 		Edges: make([]schema.RelationshipEdge, 0),
@@ -33,6 +38,7 @@ func (g *Generator) generateRelationships(db *schema.Database) error {
 	for _, table := range db.Tables {
 		for _, fk := range table.OutgoingForeignKeys {
 			graph.Edges = append(graph.Edges, schema.RelationshipEdge{
+				Kind:           schema.EdgeKindForeignKey,
 				FromTable:      table.TableName,
 				FromSchema:     table.SchemaName,
 				FromColumns:    fk.FromColumns,
@@ -44,9 +50,32 @@ func (g *Generator) generateRelationships(db *schema.Database) error {
 		}
 	}
 
+	// Add edges from table inheritance (child -> parent)
+	for _, table := range db.Tables {
+		for _, parent := range table.InheritsFrom {
+			graph.Edges = append(graph.Edges, schema.RelationshipEdge{
+				Kind:       schema.EdgeKindInherits,
+				FromTable:  table.TableName,
+				FromSchema: table.SchemaName,
+				ToTable:    parent.TableName,
+				ToSchema:   parent.SchemaName,
+			})
+		}
+	}
+
 	// Sort edges for deterministic output
 	sort.Slice(graph.Edges, func(i, j int) bool {
-		return graph.Edges[i].ConstraintName < graph.Edges[j].ConstraintName
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.ConstraintName != b.ConstraintName {
+			return a.ConstraintName < b.ConstraintName
+		}
+		if a.FromSchema != b.FromSchema {
+			return a.FromSchema < b.FromSchema
+		}
+		return a.FromTable < b.FromTable
 	})
 
 	// Identify junction table candidates
@@ -57,5 +86,5 @@ func (g *Generator) generateRelationships(db *schema.Database) error {
 	}
 	sort.Strings(graph.JunctionTableCandidates)
 
-	return g.writeTOON(filepath.Join(g.outputDir, "db.relationships.toon"), graph)
+	return graph
 }
@@ -0,0 +1,26 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func (g *Generator) generateSchemas(db *schema.Database) error {
+	schemasDir := filepath.Join(g.outputDir, "schemas")
+
+	for _, ns := range db.Namespaces {
+		filename := g.layout.schemas[ns.SchemaName]
+
+		output := *ns
+		if g.cfg.RedactComments {
+			output.Comment = ""
+		}
+
+		if err := g.writeArtifact(filepath.Join(schemasDir, filename), output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
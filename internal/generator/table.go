@@ -7,47 +7,45 @@ import (
 )
 
 func (g *Generator) generateTables(db *schema.Database) error {
-	tablesDir := filepath.Join(g.outputDir, "tables")
-
 	for _, table := range db.Tables {
-		tableDir := filepath.Join(tablesDir, sanitizeName(table.TableName))
+		tableDir := g.path(g.layout.tables[table])
 
-		// Generate table.structure.toon
+		// Generate table.structure
 		if err := g.generateTableStructure(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.columns.toon
+		// Generate table.columns
 		if err := g.generateTableColumns(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.indexes.toon
+		// Generate table.indexes
 		if err := g.generateTableIndexes(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.constraints.toon
+		// Generate table.constraints
 		if err := g.generateTableConstraints(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.relations.toon
+		// Generate table.relations
 		if err := g.generateTableRelations(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.triggers.toon
+		// Generate table.triggers
 		if err := g.generateTableTriggers(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.comments.toon
+		// Generate table.comments
 		if err := g.generateTableComments(tableDir, table); err != nil {
 			return err
 		}
 
-		// Generate table.stats.toon (if enabled)
+		// Generate table.stats (if enabled)
 		if g.cfg.IncludeStats && table.Stats != nil {
 			if err := g.generateTableStats(tableDir, table); err != nil {
 				return err
@@ -60,10 +58,20 @@ func (g *Generator) generateTables(db *schema.Database) error {
 
 func (g *Generator) generateTableStructure(tableDir string, table *schema.Table) error {
 	structure := schema.TableStructure{
-		TableName:        table.TableName,
-		SchemaName:       table.SchemaName,
-		TableType:        table.TableType,
-		RowCountEstimate: table.RowCountEstimate,
+		TableName:         table.TableName,
+		SchemaName:        table.SchemaName,
+		TableType:         table.TableType,
+		RowCountEstimate:  table.RowCountEstimate,
+		Persistence:       table.Persistence,
+		Tablespace:        table.Tablespace,
+		AccessMethod:      table.AccessMethod,
+		StorageParameters: table.StorageParameters,
+		ReplicaIdentity:   table.ReplicaIdentity,
+		RLSEnabled:        table.RLSEnabled,
+		RLSForced:         table.RLSForced,
+		InheritsFrom:      table.InheritsFrom,
+		InheritedBy:       table.InheritedBy,
+		Search:            table.Search,
 	}
 
 	// Find primary key
@@ -77,7 +85,7 @@ func (g *Generator) generateTableStructure(tableDir string, table *schema.Table)
 		}
 	}
 
-	return g.writeTOON(filepath.Join(tableDir, "table.structure.toon"), structure)
+	return g.writeArtifact(filepath.Join(tableDir, "table.structure"), structure)
 }
 
 func (g *Generator) generateTableColumns(tableDir string, table *schema.Table) error {
@@ -87,27 +95,26 @@ func (g *Generator) generateTableColumns(tableDir string, table *schema.Table) e
 	}
 
 	output := schema.ColumnsOutput{Columns: columns}
-	return g.writeTOON(filepath.Join(tableDir, "table.columns.toon"), output)
+	return g.writeArtifact(filepath.Join(tableDir, "table.columns"), output)
 }
 
 func (g *Generator) generateTableIndexes(tableDir string, table *schema.Table) error {
 	indexes := make([]schema.Index, 0, len(table.Indexes))
 	for _, idx := range table.Indexes {
-		// Create a copy without the Definition field (internal use only)
-		indexes = append(indexes, schema.Index{
-			IndexName:      idx.IndexName,
-			Unique:         idx.Unique,
-			Primary:        idx.Primary,
-			IndexType:      idx.IndexType,
-			Columns:        idx.Columns,
-			IncludeColumns: idx.IncludeColumns,
-			Predicate:      idx.Predicate,
-			Expression:     idx.Expression,
-		})
+		out := *idx
+
+		if g.cfg.RedactComments {
+			out.Comment = ""
+		}
+		if g.cfg.RedactDefinitions {
+			out.Definition = ""
+		}
+
+		indexes = append(indexes, out)
 	}
 
 	output := schema.IndexesOutput{Indexes: indexes}
-	return g.writeTOON(filepath.Join(tableDir, "table.indexes.toon"), output)
+	return g.writeArtifact(filepath.Join(tableDir, "table.indexes"), output)
 }
 
 func (g *Generator) generateTableConstraints(tableDir string, table *schema.Table) error {
@@ -142,7 +149,7 @@ func (g *Generator) generateTableConstraints(tableDir string, table *schema.Tabl
 		}
 	}
 
-	return g.writeTOON(filepath.Join(tableDir, "table.constraints.toon"), output)
+	return g.writeArtifact(filepath.Join(tableDir, "table.constraints"), output)
 }
 
 func (g *Generator) generateTableRelations(tableDir string, table *schema.Table) error {
@@ -153,15 +160,20 @@ func (g *Generator) generateTableRelations(tableDir string, table *schema.Table)
 
 	for _, fk := range table.OutgoingForeignKeys {
 		output.OutgoingForeignKeys = append(output.OutgoingForeignKeys, schema.ForeignKeyOutput{
-			ConstraintName: fk.ConstraintName,
-			FromColumns:    fk.FromColumns,
-			ToTable:        fk.ToTable,
-			ToSchema:       fk.ToSchema,
-			ToColumns:      fk.ToColumns,
-			OnUpdate:       fk.OnUpdate,
-			OnDelete:       fk.OnDelete,
-			Nullable:       fk.Nullable,
-			Cardinality:    fk.Cardinality,
+			ConstraintName:     fk.ConstraintName,
+			FromColumns:        fk.FromColumns,
+			ToTable:            fk.ToTable,
+			ToSchema:           fk.ToSchema,
+			ToColumns:          fk.ToColumns,
+			OnUpdate:           fk.OnUpdate,
+			OnDelete:           fk.OnDelete,
+			OnDeleteSetColumns: fk.OnDeleteSetColumns,
+			MatchType:          fk.MatchType,
+			Deferrable:         fk.Deferrable,
+			InitiallyDeferred:  fk.InitiallyDeferred,
+			NotValid:           fk.NotValid,
+			Nullable:           fk.Nullable,
+			Cardinality:        fk.Cardinality,
 		})
 	}
 
@@ -172,6 +184,9 @@ func (g *Generator) generateTableRelations(tableDir string, table *schema.Table)
 			FromSchema:     fk.FromSchema,
 			FromColumns:    fk.FromColumns,
 			ToColumns:      fk.ToColumns,
+			OnDelete:       fk.OnDelete,
+			Deferrable:     fk.Deferrable,
+			NotValid:       fk.NotValid,
 			Cardinality:    fk.Cardinality,
 		})
 	}
@@ -187,7 +202,7 @@ func (g *Generator) generateTableRelations(tableDir string, table *schema.Table)
 		}
 	}
 
-	return g.writeTOON(filepath.Join(tableDir, "table.relations.toon"), output)
+	return g.writeArtifact(filepath.Join(tableDir, "table.relations"), output)
 }
 
 func (g *Generator) generateTableTriggers(tableDir string, table *schema.Table) error {
@@ -210,7 +225,7 @@ func (g *Generator) generateTableTriggers(tableDir string, table *schema.Table)
 	}
 
 	output := schema.TriggersOutput{Triggers: triggers}
-	return g.writeTOON(filepath.Join(tableDir, "table.triggers.toon"), output)
+	return g.writeArtifact(filepath.Join(tableDir, "table.triggers"), output)
 }
 
 func (g *Generator) generateTableComments(tableDir string, table *schema.Table) error {
@@ -231,14 +246,14 @@ func (g *Generator) generateTableComments(tableDir string, table *schema.Table)
 	// Add inferred semantics
 	output.InferredSemantics = inferColumnSemantics(table.Columns)
 
-	return g.writeTOON(filepath.Join(tableDir, "table.comments.toon"), output)
+	return g.writeArtifact(filepath.Join(tableDir, "table.comments"), output)
 }
 
 func (g *Generator) generateTableStats(tableDir string, table *schema.Table) error {
 	if table.Stats == nil {
 		return nil
 	}
-	return g.writeTOON(filepath.Join(tableDir, "table.stats.toon"), table.Stats)
+	return g.writeArtifact(filepath.Join(tableDir, "table.stats"), table.Stats)
 }
 
 func inferColumnSemantics(columns []*schema.Column) *schema.InferredSemantics {
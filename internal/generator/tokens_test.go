@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+	"github.com/nenorrell/X-Rai/internal/tokens"
+	"github.com/nenorrell/X-Rai/internal/toon"
+)
+
+// TestInlineLists_TokenReduction measures the golden fixture's snapshot,
+// llms.txt included, with and without inline lists and expects the compact
+// layout to be meaningfully smaller.
+func TestInlineLists_TokenReduction(t *testing.T) {
+	var counts, bytes [2]int
+	for i, inline := range []bool{false, true} {
+		intro, err := fixture.New(filepath.Join("testdata", "shop.yaml"))
+		if err != nil {
+			t.Fatalf("fixture.New() error = %v", err)
+		}
+
+		cfg := config.NewConfig()
+		cfg.OutputDir = t.TempDir()
+		cfg.TOON = toon.EncodeOptions{InlineLists: inline}
+
+		db, err := intro.Introspect(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("Introspect() error = %v", err)
+		}
+		if err := New(cfg).Generate(db); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		err = filepath.WalkDir(filepath.Join(cfg.OutputDir, ".xrai"), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			counts[i] += tokens.Count(string(data))
+			bytes[i] += len(data)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := 100 * float64(counts[0]-counts[1]) / float64(counts[0])
+	t.Logf("tokens %d -> %d (%.1f%% fewer), bytes %d -> %d", counts[0], counts[1], saved, bytes[0], bytes[1])
+	if saved < 5 {
+		t.Errorf("inline lists saved %.1f%% of tokens, want at least 5%%", saved)
+	}
+}
@@ -7,27 +7,25 @@ import (
 )
 
 func (g *Generator) generateViews(db *schema.Database) error {
-	viewsDir := filepath.Join(g.outputDir, "views")
-
 	for _, view := range db.Views {
-		viewDir := filepath.Join(viewsDir, sanitizeName(view.ViewName))
+		viewDir := g.path(g.layout.views[view])
 
-		// Generate view.definition.toon
+		// Generate view.definition
 		if err := g.generateViewDefinition(viewDir, view); err != nil {
 			return err
 		}
 
-		// Generate view.columns.toon
+		// Generate view.columns
 		if err := g.generateViewColumns(viewDir, view); err != nil {
 			return err
 		}
 
-		// Generate view.dependencies.toon
+		// Generate view.dependencies
 		if err := g.generateViewDependencies(viewDir, view); err != nil {
 			return err
 		}
 
-		// Generate view.comments.toon
+		// Generate view.comments
 		if err := g.generateViewComments(viewDir, view); err != nil {
 			return err
 		}
@@ -46,7 +44,7 @@ func (g *Generator) generateViewDefinition(viewDir string, view *schema.View) er
 		def.Definition = view.Definition
 	}
 
-	return g.writeTOON(filepath.Join(viewDir, "view.definition.toon"), def)
+	return g.writeArtifact(filepath.Join(viewDir, "view.definition"), def)
 }
 
 func (g *Generator) generateViewColumns(viewDir string, view *schema.View) error {
@@ -56,7 +54,7 @@ func (g *Generator) generateViewColumns(viewDir string, view *schema.View) error
 	}
 
 	output := schema.ColumnsOutput{Columns: columns}
-	return g.writeTOON(filepath.Join(viewDir, "view.columns.toon"), output)
+	return g.writeArtifact(filepath.Join(viewDir, "view.columns"), output)
 }
 
 func (g *Generator) generateViewDependencies(viewDir string, view *schema.View) error {
@@ -65,7 +63,7 @@ func (g *Generator) generateViewDependencies(viewDir string, view *schema.View)
 		DependsOnViews:  view.DependsOnViews,
 	}
 
-	return g.writeTOON(filepath.Join(viewDir, "view.dependencies.toon"), deps)
+	return g.writeArtifact(filepath.Join(viewDir, "view.dependencies"), deps)
 }
 
 func (g *Generator) generateViewComments(viewDir string, view *schema.View) error {
@@ -83,5 +81,5 @@ func (g *Generator) generateViewComments(viewDir string, view *schema.View) erro
 		}
 	}
 
-	return g.writeTOON(filepath.Join(viewDir, "view.comments.toon"), output)
+	return g.writeArtifact(filepath.Join(viewDir, "view.comments"), output)
 }
@@ -11,6 +11,9 @@ func ApplyAllHeuristics(db *schema.Database) {
 		// Apply tags
 		applyTableTags(table)
 
+		// Summarize full-text and trigram search support
+		table.Search = detectSearch(table)
+
 		// Determine FK cardinality
 		for _, fk := range table.OutgoingForeignKeys {
 			fk.Cardinality = determineCardinality(table, fk)
@@ -0,0 +1,126 @@
+package heuristics
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+var (
+	regconfigRe  = regexp.MustCompile(`'([^']+)'::regconfig`)
+	identifierRe = regexp.MustCompile(`"[^"]+"|[A-Za-z_][A-Za-z0-9_]*`)
+	literalRe    = regexp.MustCompile(`'[^']*'`)
+)
+
+const (
+	fullTextUsage = "WHERE <column> @@ websearch_to_tsquery(<config>, $1)"
+	trigramUsage  = "WHERE <column> % $1 (similarity) or ILIKE '%' || $1 || '%'"
+)
+
+// detectSearch summarizes tsvector columns, full-text expression indexes and
+// trigram indexes so queries can use @@ or % instead of unindexed ILIKE.
+func detectSearch(table *schema.Table) *schema.TableSearch {
+	search := &schema.TableSearch{}
+
+	// tsvector columns, optionally generated from to_tsvector(...)
+	for _, col := range table.Columns {
+		if col.DataType != "tsvector" && col.UDTName != "tsvector" {
+			continue
+		}
+
+		src := schema.FullTextSource{
+			Column:  col.ColumnName,
+			Indexes: indexesOnColumn(table, col.ColumnName),
+		}
+
+		if col.GenerationExpression != nil {
+			src.Config = textSearchConfig(*col.GenerationExpression)
+			src.SourceColumns = referencedColumns(table, *col.GenerationExpression, col.ColumnName)
+		}
+
+		search.FullText = append(search.FullText, src)
+	}
+
+	for _, idx := range table.Indexes {
+		// Expression indexes over to_tsvector(...)
+		if idx.Expression != nil && strings.Contains(*idx.Expression, "to_tsvector(") &&
+			(idx.IndexType == "gin" || idx.IndexType == "gist") {
+			search.FullText = append(search.FullText, schema.FullTextSource{
+				Expression:    *idx.Expression,
+				Config:        textSearchConfig(*idx.Expression),
+				SourceColumns: referencedColumns(table, *idx.Expression, ""),
+				Indexes:       []string{idx.IndexName},
+			})
+		}
+
+		// Trigram indexes (pg_trgm)
+		for _, key := range idx.Keys {
+			if key.OpClass == "gin_trgm_ops" || key.OpClass == "gist_trgm_ops" {
+				search.Trigram = append(search.Trigram, schema.TrigramSource{
+					Column:    key.Column,
+					IndexName: idx.IndexName,
+					IndexType: idx.IndexType,
+				})
+			}
+		}
+	}
+
+	if len(search.FullText) == 0 && len(search.Trigram) == 0 {
+		return nil
+	}
+	if len(search.FullText) > 0 {
+		search.FullTextUsage = fullTextUsage
+	}
+	if len(search.Trigram) > 0 {
+		search.TrigramUsage = trigramUsage
+	}
+
+	return search
+}
+
+// textSearchConfig extracts the regconfig literal from an expression.
+func textSearchConfig(expr string) string {
+	if m := regconfigRe.FindStringSubmatch(expr); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// referencedColumns returns the table columns mentioned in an expression.
+func referencedColumns(table *schema.Table, expr, exclude string) []string {
+	// Drop string literals so config names are not mistaken for columns
+	stripped := literalRe.ReplaceAllString(expr, "")
+
+	mentioned := make(map[string]bool)
+	for _, tok := range identifierRe.FindAllString(stripped, -1) {
+		mentioned[strings.Trim(tok, `"`)] = true
+	}
+
+	var cols []string
+	for _, col := range table.Columns {
+		if col.ColumnName != exclude && mentioned[col.ColumnName] {
+			cols = append(cols, col.ColumnName)
+		}
+	}
+	return cols
+}
+
+// indexesOnColumn returns the GIN/GiST indexes whose keys include the column.
+func indexesOnColumn(table *schema.Table, column string) []string {
+	var names []string
+	for _, idx := range table.Indexes {
+		if idx.IndexType != "gin" && idx.IndexType != "gist" {
+			continue
+		}
+		for _, c := range idx.Columns {
+			if c == column {
+				names = append(names, idx.IndexName)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package heuristics
+
+import (
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestDetectSearch_GeneratedTsvector(t *testing.T) {
+	table := &schema.Table{
+		TableName: "articles",
+		Columns: []*schema.Column{
+			{ColumnName: "id", DataType: "integer"},
+			{ColumnName: "title", DataType: "text"},
+			{ColumnName: "body", DataType: "text"},
+			{
+				ColumnName:           "search",
+				DataType:             "tsvector",
+				Generated:            true,
+				GenerationExpression: ptr("to_tsvector('english'::regconfig, (COALESCE(title, ''::text) || ' '::text) || COALESCE(body, ''::text))"),
+			},
+		},
+		Indexes: []*schema.Index{
+			{IndexName: "articles_search_idx", IndexType: "gin", Columns: []string{"search"}},
+		},
+	}
+
+	search := detectSearch(table)
+	if search == nil || len(search.FullText) != 1 {
+		t.Fatalf("expected one full-text source, got %+v", search)
+	}
+
+	src := search.FullText[0]
+	if src.Column != "search" || src.Config != "english" {
+		t.Errorf("unexpected source: %+v", src)
+	}
+	if len(src.SourceColumns) != 2 || src.SourceColumns[0] != "title" || src.SourceColumns[1] != "body" {
+		t.Errorf("expected source columns [title body], got %v", src.SourceColumns)
+	}
+	if len(src.Indexes) != 1 || src.Indexes[0] != "articles_search_idx" {
+		t.Errorf("expected GIN index link, got %v", src.Indexes)
+	}
+	if search.FullTextUsage != fullTextUsage || search.TrigramUsage != "" {
+		t.Errorf("expected only the full-text usage hint, got %q and %q", search.FullTextUsage, search.TrigramUsage)
+	}
+}
+
+func TestDetectSearch_ExpressionAndTrigramIndexes(t *testing.T) {
+	table := &schema.Table{
+		TableName: "products",
+		Columns: []*schema.Column{
+			{ColumnName: "name", DataType: "text"},
+			{ColumnName: "description", DataType: "text"},
+		},
+		Indexes: []*schema.Index{
+			{
+				IndexName:  "products_fts_idx",
+				IndexType:  "gin",
+				Expression: ptr("to_tsvector('simple'::regconfig, description)"),
+				Keys:       []schema.IndexKey{{Column: "to_tsvector('simple'::regconfig, description)", OpClass: "tsvector_ops"}},
+			},
+			{
+				IndexName: "products_name_trgm_idx",
+				IndexType: "gin",
+				Columns:   []string{"name"},
+				Keys:      []schema.IndexKey{{Column: "name", OpClass: "gin_trgm_ops"}},
+			},
+		},
+	}
+
+	search := detectSearch(table)
+	if search == nil {
+		t.Fatal("expected search summary")
+	}
+
+	if len(search.FullText) != 1 || search.FullText[0].Config != "simple" {
+		t.Errorf("expected one simple-config expression source, got %+v", search.FullText)
+	}
+	if len(search.FullText[0].SourceColumns) != 1 || search.FullText[0].SourceColumns[0] != "description" {
+		t.Errorf("expected description source column, got %v", search.FullText[0].SourceColumns)
+	}
+	if len(search.Trigram) != 1 || search.Trigram[0].Column != "name" {
+		t.Errorf("expected trigram on name, got %+v", search.Trigram)
+	}
+	if search.FullTextUsage != fullTextUsage || search.TrigramUsage != trigramUsage {
+		t.Errorf("expected section usage hints, got %q and %q", search.FullTextUsage, search.TrigramUsage)
+	}
+}
+
+func TestDetectSearch_None(t *testing.T) {
+	table := &schema.Table{
+		TableName: "users",
+		Columns:   []*schema.Column{{ColumnName: "email", DataType: "text"}},
+		Indexes:   []*schema.Index{{IndexName: "users_email_key", IndexType: "btree", Columns: []string{"email"}}},
+	}
+
+	if search := detectSearch(table); search != nil {
+		t.Errorf("expected nil search summary, got %+v", search)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,233 @@
+package ddl
+
+import (
+	"strings"
+)
+
+// cursor walks the tokens of a single statement.
+type cursor struct {
+	stmt statement
+	pos  int
+}
+
+func (c *cursor) done() bool {
+	return c.pos >= len(c.stmt.tokens)
+}
+
+func (c *cursor) peek() token {
+	if c.done() {
+		return token{kind: tokPunct}
+	}
+	return c.stmt.tokens[c.pos]
+}
+
+func (c *cursor) next() token {
+	t := c.peek()
+	if !c.done() {
+		c.pos++
+	}
+	return t
+}
+
+// accept consumes the keyword sequence if it appears next.
+func (c *cursor) accept(keywords ...string) bool {
+	for n, kw := range keywords {
+		if c.pos+n >= len(c.stmt.tokens) || !c.stmt.tokens[c.pos+n].is(kw) {
+			return false
+		}
+	}
+	c.pos += len(keywords)
+	return true
+}
+
+// acceptPunct consumes the punctuation if it appears next.
+func (c *cursor) acceptPunct(p string) bool {
+	if c.peek().isPunct(p) {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+// peekAny reports whether the next token is one of the keywords.
+func (c *cursor) peekAny(keywords ...string) bool {
+	t := c.peek()
+	for _, kw := range keywords {
+		if t.is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// find advances past the first occurrence of the keyword, if any.
+func (c *cursor) find(keyword string) bool {
+	for n := c.pos; n < len(c.stmt.tokens); n++ {
+		if c.stmt.tokens[n].is(keyword) {
+			c.pos = n + 1
+			return true
+		}
+	}
+	return false
+}
+
+// ident consumes an identifier. Unquoted names fold to lower case.
+func (c *cursor) ident() (string, bool) {
+	t := c.peek()
+	switch t.kind {
+	case tokIdent:
+		c.pos++
+		return strings.ToLower(t.text), true
+	case tokQuoted:
+		c.pos++
+		return t.text, true
+	}
+	return "", false
+}
+
+// name consumes a dotted, possibly qualified name.
+func (c *cursor) name() []string {
+	first, ok := c.ident()
+	if !ok {
+		return nil
+	}
+	parts := []string{first}
+	for c.peek().isPunct(".") {
+		c.pos++
+		part, ok := c.ident()
+		if !ok {
+			break
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// group consumes a parenthesised group and returns its inner token range.
+func (c *cursor) group() (int, int, bool) {
+	if !c.peek().isPunct("(") {
+		return 0, 0, false
+	}
+	start := c.pos + 1
+	depth := 0
+	for n := c.pos; n < len(c.stmt.tokens); n++ {
+		t := c.stmt.tokens[n]
+		if t.isPunct("(") || t.isPunct("[") {
+			depth++
+		} else if t.isPunct(")") || t.isPunct("]") {
+			depth--
+			if depth == 0 {
+				c.pos = n + 1
+				return start, n, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// until consumes tokens up to (not including) the first depth-0 keyword in
+// stop, or the end of the statement, returning the consumed range.
+func (c *cursor) until(stop ...string) (int, int) {
+	start := c.pos
+	depth := 0
+	for ; c.pos < len(c.stmt.tokens); c.pos++ {
+		t := c.stmt.tokens[c.pos]
+		switch {
+		case t.isPunct("(") || t.isPunct("["):
+			depth++
+		case t.isPunct(")") || t.isPunct("]"):
+			depth--
+		case depth == 0:
+			for _, kw := range stop {
+				if t.is(kw) || t.isPunct(kw) {
+					return start, c.pos
+				}
+			}
+		}
+	}
+	return start, c.pos
+}
+
+// sub returns a cursor over a token range of the same statement.
+func (c *cursor) sub(start, end int) *cursor {
+	return &cursor{stmt: statement{tokens: c.stmt.tokens[start:end], src: c.stmt.src, line: c.stmt.line}}
+}
+
+// source returns the original text spanning a token range.
+func (c *cursor) source(start, end int) string {
+	if start >= end {
+		return ""
+	}
+	return c.stmt.src[c.stmt.tokens[start].start:c.stmt.tokens[end-1].end]
+}
+
+// rest returns the original text from the cursor to the end of the statement.
+func (c *cursor) rest() string {
+	return c.source(c.pos, len(c.stmt.tokens))
+}
+
+// split divides a token range on depth-0 commas.
+func (c *cursor) split(start, end int) [][2]int {
+	var parts [][2]int
+	depth := 0
+	from := start
+	for n := start; n < end; n++ {
+		t := c.stmt.tokens[n]
+		switch {
+		case t.isPunct("(") || t.isPunct("["):
+			depth++
+		case t.isPunct(")") || t.isPunct("]"):
+			depth--
+		case t.isPunct(",") && depth == 0:
+			parts = append(parts, [2]int{from, n})
+			from = n + 1
+		}
+	}
+	if from < end {
+		parts = append(parts, [2]int{from, end})
+	}
+	return parts
+}
+
+// render rebuilds a type or name from tokens with canonical spacing, e.g.
+// "character varying(255)[]" or "public.order_status".
+func (c *cursor) render(start, end int) string {
+	var sb strings.Builder
+	var prev token
+	for n := start; n < end; n++ {
+		t := c.stmt.tokens[n]
+		if n > start && !t.isPunct(")") && !t.isPunct(",") && !t.isPunct("]") && !t.isPunct(".") &&
+			!(t.isPunct("(") && prev.kind != tokPunct) && !t.isPunct("[") &&
+			!prev.isPunct("(") && !prev.isPunct("[") && !prev.isPunct(".") {
+			sb.WriteByte(' ')
+		}
+		switch t.kind {
+		case tokQuoted:
+			sb.WriteString(`"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`)
+		case tokIdent:
+			sb.WriteString(strings.ToLower(t.text))
+		default:
+			sb.WriteString(c.stmt.src[t.start:t.end])
+		}
+		prev = t
+	}
+	return sb.String()
+}
+
+// identList parses "(a, b, c)" into names.
+func (c *cursor) identList() ([]string, bool) {
+	start, end, ok := c.group()
+	if !ok {
+		return nil, false
+	}
+	var names []string
+	for _, part := range c.split(start, end) {
+		sub := c.sub(part[0], part[1])
+		name, ok := sub.ident()
+		if !ok {
+			return nil, false
+		}
+		names = append(names, name)
+	}
+	return names, true
+}
@@ -0,0 +1,91 @@
+// Package ddl implements an introspector that reads PostgreSQL DDL, such as
+// pg_dump --schema-only output or a folder of migrations, without a database.
+package ddl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+)
+
+// Introspector builds a schema.Database from DDL files. Statements that could
+// not be applied are available from Skipped.
+type Introspector struct {
+	*fixture.Introspector
+	skipped []Skipped
+}
+
+// New parses the given SQL files in order. Directories are expanded to their
+// *.sql files in lexical order, which matches common migration naming.
+func New(paths ...string) (*Introspector, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no SQL files found in %s", strings.Join(paths, ", "))
+	}
+
+	p := newParser()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := p.parse(file, string(data)); err != nil {
+			return nil, fmt.Errorf("failed to parse SQL: %w", err)
+		}
+	}
+
+	base := filepath.Base(strings.TrimRight(paths[0], string(filepath.Separator)))
+	doc := p.document(strings.TrimSuffix(base, filepath.Ext(base)))
+
+	intro, err := fixture.FromDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Introspector{Introspector: intro, skipped: p.skipped}, nil
+}
+
+// Parse parses SQL text into a fixture document. It is the in-memory
+// counterpart of New, used by tests and tooling.
+func Parse(name, src string) (*fixture.Document, []Skipped, error) {
+	p := newParser()
+	if err := p.parse(name, src); err != nil {
+		return nil, nil, err
+	}
+	doc := p.document(name)
+	return doc, p.skipped, nil
+}
+
+// Skipped returns the statements and clauses that were not applied.
+func (i *Introspector) Skipped() []Skipped {
+	return i.skipped
+}
+
+func expandPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.sql"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", path, err)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
@@ -0,0 +1,306 @@
+package ddl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestNew_PgDump(t *testing.T) {
+	intro, err := New(filepath.Join("testdata", "pg_dump.sql"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.Schemas = []string{"public", "billing"}
+	cfg.IncludeViews = true
+	cfg.IncludeRoutines = true
+
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	if db.Name != "pg_dump" || db.Version != "16.2" {
+		t.Errorf("metadata = %q %q", db.Name, db.Version)
+	}
+
+	var names []string
+	for _, tbl := range db.Tables {
+		names = append(names, tbl.SchemaName+"."+tbl.TableName)
+	}
+	if got := strings.Join(names, ","); got != "billing.invoices,public.order_items,public.orders,public.users" {
+		t.Errorf("tables = %s", got)
+	}
+
+	users := findTable(db, "public", "users")
+	if users.Comment != "Registered customers" {
+		t.Errorf("users comment = %q", users.Comment)
+	}
+	if id := users.Columns[0]; id.Nullable || id.DefaultValue == nil || !strings.HasPrefix(*id.DefaultValue, "nextval(") {
+		t.Errorf("users.id = %+v", id)
+	}
+	if email := users.Columns[1]; email.UDTName != "varchar" || *email.CharacterMaxLength != 255 || email.Comment == "" {
+		t.Errorf("users.email = %+v", email)
+	}
+	if len(users.Indexes) != 4 {
+		t.Fatalf("users indexes = %d, want 4", len(users.Indexes))
+	}
+	trgm := users.Indexes[1]
+	if trgm.IndexName != "users_email_trgm_idx" || trgm.IndexType != "gin" || trgm.Keys[0].OpClass != "gin_trgm_ops" || trgm.Comment != "Fuzzy email lookup" {
+		t.Errorf("trigram index = %+v", trgm)
+	}
+	if len(users.IncomingForeignKeys) != 1 || users.IncomingForeignKeys[0].OnDelete != "CASCADE" {
+		t.Errorf("users incoming = %+v", users.IncomingForeignKeys)
+	}
+	if len(users.Triggers) != 1 || users.Triggers[0].FunctionOrProcedure != "touch_updated_at" {
+		t.Errorf("users triggers = %+v", users.Triggers)
+	}
+
+	orders := findTable(db, "public", "orders")
+	if !orders.Columns[0].IsIdentity || orders.Columns[0].IdentityGeneration != "ALWAYS" {
+		t.Errorf("orders.id = %+v", orders.Columns[0])
+	}
+	if status := orders.Columns[2]; status.DataType != "USER-DEFINED" || status.UDTName != "order_status" {
+		t.Errorf("orders.status = %+v", status)
+	}
+	if def := *orders.Constraints[1].Definition; def != "CHECK ((total_cents < 10000000))" {
+		t.Errorf("check definition = %q", def)
+	}
+
+	items := findTable(db, "public", "order_items")
+	if fk := items.OutgoingForeignKeys[0]; !fk.Deferrable || !fk.InitiallyDeferred {
+		t.Errorf("order_items fk = %+v", fk)
+	}
+	if fk := findTable(db, "billing", "invoices").OutgoingForeignKeys[0]; !fk.NotValid || fk.ToSchema != "public" {
+		t.Errorf("invoices fk = %+v", fk)
+	}
+
+	if len(db.Views) != 1 || strings.Join(db.Views[0].DependsOnTables, ",") != "orders,users" {
+		t.Errorf("views = %+v", db.Views)
+	}
+	if len(db.Routines) != 2 || db.Routines[0].Comment != "Sum of line items" || len(db.Routines[0].Arguments) != 2 {
+		t.Errorf("routines = %+v", db.Routines)
+	}
+	if len(db.Enums) != 1 || len(db.Enums[0].Values) != 3 {
+		t.Errorf("enums = %+v", db.Enums)
+	}
+	if len(db.Sequences) != 2 || db.Sequences[1].OwnedBy != "users.id" {
+		t.Errorf("sequences = %+v", db.Sequences)
+	}
+	if len(db.Types) != 2 || db.Types[1].Constraint == nil || *db.Types[1].Constraint != "CHECK ((VALUE >= 0))" {
+		t.Errorf("types = %+v", db.Types)
+	}
+
+	var reasons []string
+	for _, s := range intro.Skipped() {
+		reasons = append(reasons, s.Text)
+	}
+	if len(reasons) != 2 || !strings.HasPrefix(reasons[0], "CREATE MATERIALIZED VIEW") || !strings.HasPrefix(reasons[1], "CREATE POLICY") {
+		t.Errorf("skipped = %v", reasons)
+	}
+}
+
+func TestNew_Migrations(t *testing.T) {
+	intro, err := New(filepath.Join("testdata", "migrations"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	db, err := intro.Introspect(context.Background(), config.NewConfig())
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	accounts := findTable(db, "public", "accounts")
+	var cols []string
+	for _, c := range accounts.Columns {
+		cols = append(cols, c.ColumnName)
+	}
+	if got := strings.Join(cols, ","); got != "id,name,plan,created_at" {
+		t.Errorf("accounts columns = %s", got)
+	}
+	if accounts.Comment != "Billing accounts" {
+		t.Errorf("accounts comment = %q", accounts.Comment)
+	}
+	if accounts.Constraints[1].ConstraintName != "accounts_name_key" {
+		t.Errorf("accounts constraints = %+v", accounts.Constraints)
+	}
+
+	members := findTable(db, "public", "members")
+	if members.Columns[2].ColumnName != "login_email" || members.Columns[2].UDTName != "citext" {
+		t.Errorf("members.login_email = %+v", members.Columns[2])
+	}
+	var idx []string
+	for _, i := range members.Indexes {
+		idx = append(idx, i.IndexName)
+	}
+	if got := strings.Join(idx, ","); got != "members_login_email_key,members_pkey" {
+		t.Errorf("members indexes = %s", got)
+	}
+	if fk := members.OutgoingForeignKeys[0]; fk.ConstraintName != "members_account_id_fkey" || fk.ToTable != "accounts" {
+		t.Errorf("members fk = %+v", fk)
+	}
+
+	if got := strings.Join(db.Enums[0].Values, ","); got != "free,pro,enterprise" {
+		t.Errorf("plan values = %s", got)
+	}
+	if len(db.Sequences) != 2 || db.Sequences[0].SequenceName != "accounts_id_seq" || db.Sequences[0].DataType != "integer" {
+		t.Errorf("sequences = %+v", db.Sequences)
+	}
+
+	skipped := intro.Skipped()
+	if len(skipped) != 1 || !strings.Contains(skipped[0].Reason, "ENABLE ROW LEVEL SECURITY") {
+		t.Errorf("skipped = %v", skipped)
+	}
+}
+
+func TestLex(t *testing.T) {
+	src := `
+		-- comment; with semicolon
+		CREATE FUNCTION f() RETURNS text AS $body$ SELECT 'a;b' $body$;
+		/* block ; /* nested */ comment */
+		COMMENT ON TABLE t IS 'it''s; fine';
+		COMMENT ON TABLE t IS E'line\nbreak';
+		\connect shop
+		SELECT 1
+	`
+
+	stmts, err := lex(src)
+	if err != nil {
+		t.Fatalf("lex() error = %v", err)
+	}
+	if len(stmts) != 4 {
+		t.Fatalf("statements = %d, want 4", len(stmts))
+	}
+
+	last := func(s statement) token { return s.tokens[len(s.tokens)-1] }
+	if body := last(stmts[0]); body.kind != tokString || body.text != " SELECT 'a;b' " {
+		t.Errorf("dollar body = %q", body.text)
+	}
+	if lit := last(stmts[1]); lit.text != "it's; fine" {
+		t.Errorf("literal = %q", lit.text)
+	}
+	if lit := last(stmts[2]); lit.text != "line\nbreak" {
+		t.Errorf("escape literal = %q", lit.text)
+	}
+	if stmts[3].line != 8 {
+		t.Errorf("SELECT line = %d, want 8", stmts[3].line)
+	}
+
+	if _, err := lex("SELECT 'unterminated"); err == nil {
+		t.Error("lex() error = nil for unterminated literal")
+	}
+}
+
+func findTable(db *schema.Database, schemaName, name string) *schema.Table {
+	for _, t := range db.Tables {
+		if t.SchemaName == schemaName && t.TableName == name {
+			return t
+		}
+	}
+	return &schema.Table{}
+}
+
+func TestIntrospect_IndexKeys(t *testing.T) {
+	db := introspectSQL(t, `
+		CREATE TABLE t ("Order" integer PRIMARY KEY, a integer, b integer, x text);
+		CREATE INDEX t_ab ON t (a DESC NULLS LAST, b);
+		CREATE INDEX t_lower_x ON t ((lower(x)) DESC);
+	`)
+
+	table := findTable(db, "public", "t")
+	indexes := map[string]*schema.Index{}
+	for _, idx := range table.Indexes {
+		indexes[idx.IndexName] = idx
+	}
+
+	ab := indexes["t_ab"]
+	if ab == nil || len(ab.Keys) != 2 {
+		t.Fatalf("t_ab = %+v", ab)
+	}
+	if k := ab.Keys[0]; k.Column != "a" || k.SortOrder != "DESC" || k.NullsOrder != "LAST" {
+		t.Errorf("t_ab key a = %+v", k)
+	}
+	if k := ab.Keys[1]; k.Column != "b" || k.SortOrder != "ASC" || k.NullsOrder != "LAST" {
+		t.Errorf("t_ab key b = %+v", k)
+	}
+
+	lower := indexes["t_lower_x"]
+	if lower == nil || lower.Expression == nil || *lower.Expression != "lower(x)" {
+		t.Fatalf("t_lower_x = %+v", lower)
+	}
+	if lower.Columns == nil || len(lower.Columns) != 0 {
+		t.Errorf("t_lower_x columns = %#v, want empty slice", lower.Columns)
+	}
+	if k := lower.Keys[0]; k.SortOrder != "DESC" || k.NullsOrder != "FIRST" {
+		t.Errorf("t_lower_x key = %+v", k)
+	}
+
+	pkey := indexes["t_pkey"]
+	if pkey == nil || !strings.Contains(pkey.Definition, `("Order")`) {
+		t.Errorf("t_pkey = %+v", pkey)
+	}
+	if def := *table.Constraints[0].Definition; def != `PRIMARY KEY ("Order")` {
+		t.Errorf("pkey definition = %q", def)
+	}
+}
+
+func TestIntrospect_RoutineOverloads(t *testing.T) {
+	db := introspectSQL(t, `
+		CREATE FUNCTION f(a integer) RETURNS integer LANGUAGE sql AS $$ SELECT a $$;
+		CREATE FUNCTION f(a integer, b text) RETURNS integer LANGUAGE sql AS $$ SELECT a $$;
+		CREATE FUNCTION g(a integer) RETURNS integer LANGUAGE sql AS $$ SELECT a $$;
+		CREATE FUNCTION g(a text) RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;
+		COMMENT ON FUNCTION f(integer, text) IS 'two args';
+		COMMENT ON FUNCTION f(int4) IS 'one arg';
+		DROP FUNCTION g(text);
+	`)
+
+	comments := map[int]string{}
+	var g int
+	for _, r := range db.Routines {
+		switch r.RoutineName {
+		case "f":
+			comments[len(r.Arguments)] = r.Comment
+		case "g":
+			g++
+			if r.Arguments[0].DataType != "integer" {
+				t.Errorf("g survivor = %+v", r.Arguments)
+			}
+		}
+	}
+	if comments[1] != "one arg" || comments[2] != "two args" {
+		t.Errorf("f comments = %v", comments)
+	}
+	if g != 1 {
+		t.Errorf("g overloads = %d, want 1", g)
+	}
+}
+
+func introspectSQL(t *testing.T, src string) *schema.Database {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	intro, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.IncludeRoutines = true
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	return db
+}
@@ -0,0 +1,269 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokIdent  tokenKind = iota // bare word or keyword
+	tokQuoted                  // "quoted identifier"
+	tokString                  // 'literal', E'literal' or $tag$body$tag$
+	tokNumber
+	tokPunct // ( ) , ; . [ ] and operators
+)
+
+// token is a lexical unit with its byte span in the source, so expressions
+// can be sliced back out verbatim.
+type token struct {
+	kind  tokenKind
+	text  string // identifier name, unquoted string value, or raw punctuation
+	start int
+	end   int
+	line  int
+}
+
+// is reports whether the token is the given keyword (case-insensitive).
+func (t token) is(keyword string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, keyword)
+}
+
+// isPunct reports whether the token is the given punctuation.
+func (t token) isPunct(p string) bool {
+	return t.kind == tokPunct && t.text == p
+}
+
+// statement is one semicolon-terminated SQL statement.
+type statement struct {
+	tokens []token
+	src    string
+	line   int
+}
+
+// text returns the source text of the whole statement.
+func (s statement) text() string {
+	if len(s.tokens) == 0 {
+		return ""
+	}
+	return s.src[s.tokens[0].start:s.tokens[len(s.tokens)-1].end]
+}
+
+// lex splits SQL into statements. Comments are dropped, psql meta-commands
+// (lines starting with a backslash) are skipped, and dollar-quoted bodies are
+// kept as single string tokens.
+func lex(src string) ([]statement, error) {
+	var (
+		stmts   []statement
+		current []token
+		line    = 1
+		i       = 0
+	)
+
+	emit := func(t token) {
+		current = append(current, t)
+	}
+	flush := func() {
+		if len(current) > 0 {
+			stmts = append(stmts, statement{tokens: current, src: src, line: current[0].line})
+		}
+		current = nil
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			depth := 0
+			for i < len(src) {
+				if strings.HasPrefix(src[i:], "/*") {
+					depth++
+					i += 2
+				} else if strings.HasPrefix(src[i:], "*/") {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					if src[i] == '\n' {
+						line++
+					}
+					i++
+				}
+			}
+			if depth != 0 {
+				return nil, fmt.Errorf("line %d: unterminated block comment", line)
+			}
+
+		case c == '\\' && len(current) == 0:
+			// psql meta-command such as \connect or \restrict
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case c == ';':
+			flush()
+			i++
+
+		case c == '\'' || ((c == 'E' || c == 'e') && i+1 < len(src) && src[i+1] == '\''):
+			start, startLine := i, line
+			escapes := c != '\''
+			if escapes {
+				i++
+			}
+			i++
+			var sb strings.Builder
+			closed := false
+			for i < len(src) {
+				ch := src[i]
+				if ch == '\n' {
+					line++
+				}
+				if escapes && ch == '\\' && i+1 < len(src) {
+					sb.WriteByte(unescape(src[i+1]))
+					i += 2
+					continue
+				}
+				if ch == '\'' {
+					if i+1 < len(src) && src[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					closed = true
+					break
+				}
+				sb.WriteByte(ch)
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated string literal", startLine)
+			}
+			emit(token{kind: tokString, text: sb.String(), start: start, end: i, line: startLine})
+
+		case c == '$' && dollarTag(src[i:]) != "":
+			tag := dollarTag(src[i:])
+			start, startLine := i, line
+			bodyStart := i + len(tag)
+			end := strings.Index(src[bodyStart:], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar-quoted string %s", startLine, tag)
+			}
+			body := src[bodyStart : bodyStart+end]
+			line += strings.Count(body, "\n")
+			i = bodyStart + end + len(tag)
+			emit(token{kind: tokString, text: body, start: start, end: i, line: startLine})
+
+		case c == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			closed := false
+			for i < len(src) {
+				if src[i] == '"' {
+					if i+1 < len(src) && src[i+1] == '"' {
+						sb.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					closed = true
+					break
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated quoted identifier", line)
+			}
+			emit(token{kind: tokQuoted, text: sb.String(), start: start, end: i, line: line})
+
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			emit(token{kind: tokIdent, text: src[start:i], start: start, end: i, line: line})
+
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.' || src[i] == 'e' || src[i] == 'E') {
+				i++
+			}
+			emit(token{kind: tokNumber, text: src[start:i], start: start, end: i, line: line})
+
+		case strings.ContainsRune("(),.[]", rune(c)):
+			emit(token{kind: tokPunct, text: string(c), start: i, end: i + 1, line: line})
+			i++
+
+		default:
+			// Operators: group consecutive operator characters
+			start := i
+			for i < len(src) && strings.ContainsRune("+-*/<>=~!@#%^&|`?:", rune(src[i])) {
+				if strings.HasPrefix(src[i:], "--") || strings.HasPrefix(src[i:], "/*") {
+					break
+				}
+				i++
+			}
+			if i == start {
+				i++
+			}
+			emit(token{kind: tokPunct, text: src[start:i], start: start, end: i, line: line})
+		}
+	}
+
+	flush()
+	return stmts, nil
+}
+
+// dollarTag returns the opening $tag$ at the start of s, or "".
+func dollarTag(s string) string {
+	if len(s) < 2 || s[0] != '$' {
+		return ""
+	}
+	for j := 1; j < len(s); j++ {
+		if s[j] == '$' {
+			return s[:j+1]
+		}
+		if !isIdentPart(s[j]) || (j == 1 && s[j] >= '0' && s[j] <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	default:
+		return c
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
@@ -0,0 +1,718 @@
+package ddl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+)
+
+func (p *parser) createIndex(c *cursor) {
+	definition := "CREATE " + c.rest()
+	unique := c.accept("UNIQUE")
+	c.accept("INDEX")
+	c.accept("CONCURRENTLY")
+	c.accept("IF", "NOT", "EXISTS")
+
+	var name string
+	if !c.peekAny("ON") {
+		name, _ = c.ident()
+	}
+	if !c.accept("ON") {
+		p.skip(c.stmt, "malformed CREATE INDEX")
+		return
+	}
+	c.accept("ONLY")
+	schemaName, table := p.qualify(c.name())
+	td, ok := p.tables[schemaName+"."+table]
+	if !ok {
+		p.skip(c.stmt, "index on unknown table "+schemaName+"."+table)
+		return
+	}
+	if name == "" {
+		name = table + "_idx"
+	}
+
+	idx := fixture.IndexDoc{Name: name, Unique: unique, Method: "btree"}
+	if c.accept("USING") {
+		idx.Method = strings.ToLower(c.next().text)
+	}
+
+	start, end, ok := c.group()
+	if !ok {
+		p.skip(c.stmt, "malformed index key list")
+		return
+	}
+
+	var exprs []string
+	for _, part := range c.split(start, end) {
+		key := c.sub(part[0], part[1])
+		var kd fixture.IndexKeyDoc
+		if col, ok := key.ident(); ok && (key.done() || key.peekAny("ASC", "DESC", "NULLS", "COLLATE") || key.peek().kind == tokIdent) {
+			kd.Column = col
+			idx.Columns = append(idx.Columns, col)
+		} else {
+			key.pos = 0
+			kd.Expression = indexExpression(key)
+			exprs = append(exprs, kd.Expression)
+		}
+		indexKeyOptions(key, &kd)
+		if idx.OpClass == "" {
+			idx.OpClass = kd.OpClass
+		}
+		idx.Keys = append(idx.Keys, kd)
+	}
+	if len(exprs) > 0 {
+		idx.Expression = strings.Join(exprs, ", ")
+	}
+
+	if c.accept("INCLUDE") {
+		idx.Include, _ = c.identList()
+	}
+	if c.accept("NULLS", "NOT", "DISTINCT") {
+		p.skip(c.stmt, "NULLS NOT DISTINCT on a standalone index is not modelled")
+	}
+	if c.accept("WITH") {
+		c.group()
+	}
+	if c.accept("TABLESPACE") {
+		c.ident()
+	}
+	if c.accept("WHERE") {
+		idx.Where = c.rest()
+	}
+
+	idx.Definition = definition
+	td.Indexes = append(td.Indexes, idx)
+	p.indexes[schemaName+"."+name] = schemaName + "." + table
+}
+
+// indexExpression consumes an expression index key, a function call or a
+// parenthesised expression, and returns it as pg_get_expr prints it: without
+// the outer parentheses or any ordering that follows.
+func indexExpression(key *cursor) string {
+	if start, end, ok := key.group(); ok {
+		return key.source(start, end)
+	}
+	key.name()
+	key.group()
+	return key.source(0, key.pos)
+}
+
+// indexKeyOptions parses what follows an index key: COLLATE, an operator
+// class, ASC/DESC and NULLS FIRST/LAST.
+func indexKeyOptions(key *cursor, kd *fixture.IndexKeyDoc) {
+	for !key.done() {
+		switch {
+		case key.accept("COLLATE"):
+			if parts := key.name(); len(parts) > 0 {
+				kd.Collation = parts[len(parts)-1]
+			}
+		case key.accept("ASC"):
+			kd.Order = "asc"
+		case key.accept("DESC"):
+			kd.Order = "desc"
+		case key.accept("NULLS", "FIRST"):
+			kd.Nulls = "first"
+		case key.accept("NULLS", "LAST"):
+			kd.Nulls = "last"
+		default:
+			// A bare word is the operator class, optionally with parameters
+			if opclass := key.name(); len(opclass) > 0 {
+				kd.OpClass = opclass[len(opclass)-1]
+				key.group()
+				continue
+			}
+			key.next()
+		}
+	}
+}
+
+func (p *parser) createView(c *cursor) {
+	c.accept("TEMP")
+	c.accept("TEMPORARY")
+	c.accept("RECURSIVE")
+	c.accept("VIEW")
+	schemaName, name := p.qualify(c.name())
+
+	if c.peek().isPunct("(") {
+		c.group()
+	}
+	if c.accept("WITH") {
+		c.group()
+	}
+	if !c.accept("AS") {
+		p.skip(c.stmt, "malformed CREATE VIEW")
+		return
+	}
+
+	query := c.pos
+	vd := &fixture.ViewDoc{Name: name, Schema: schemaName}
+
+	// information_schema.views reports pg_get_viewdef, which pg_dump prints
+	// on the line after AS and terminates with a semicolon
+	def := strings.TrimLeft(c.stmt.src[c.stmt.tokens[query-1].end:c.stmt.tokens[len(c.stmt.tokens)-1].end], "\n")
+	vd.Definition = def + ";"
+
+	for c.pos = query; !c.done(); {
+		if c.accept("FROM") || c.accept("JOIN") {
+			// pg_dump parenthesises joins: FROM (a JOIN b ON ...)
+			for c.acceptPunct("(") {
+			}
+			for {
+				if c.peek().isPunct("(") {
+					break
+				}
+				ds, dn := p.qualify(c.name())
+				if dn != "" {
+					vd.DependsOn = appendUnique(vd.DependsOn, ds+"."+dn)
+				}
+				c.accept("AS")
+				if c.peek().kind == tokIdent && !c.peekAny("WHERE", "JOIN", "LEFT", "RIGHT", "INNER", "FULL",
+					"CROSS", "ON", "GROUP", "ORDER", "LIMIT", "UNION", "NATURAL", "HAVING", "WINDOW", "USING") {
+					c.next()
+				}
+				if !c.acceptPunct(",") {
+					break
+				}
+			}
+			continue
+		}
+		c.next()
+	}
+
+	p.schema(schemaName)
+	p.views[schemaName+"."+name] = vd
+}
+
+// routineOptions are the keywords that can follow RETURNS.
+var routineOptions = []string{"LANGUAGE", "AS", "IMMUTABLE", "STABLE", "VOLATILE", "STRICT", "CALLED",
+	"SECURITY", "PARALLEL", "COST", "ROWS", "SET", "WINDOW", "LEAKPROOF", "NOT", "SUPPORT", "TRANSFORM",
+	"BEGIN", "RETURN", "EXTERNAL"}
+
+func (p *parser) createRoutine(c *cursor) {
+	kind := "function"
+	if c.accept("PROCEDURE") {
+		kind = "procedure"
+	} else {
+		c.accept("FUNCTION")
+	}
+
+	headerStart := c.pos
+	schemaName, name := p.qualify(c.name())
+	rd := &fixture.RoutineDoc{Name: name, Schema: schemaName, Kind: kind}
+
+	// pg_get_functiondef always prints CREATE OR REPLACE
+	rd.Definition = "CREATE OR REPLACE " + strings.ToUpper(kind) + " " + c.source(headerStart, len(c.stmt.tokens))
+
+	if start, end, ok := c.group(); ok {
+		for _, part := range c.split(start, end) {
+			rd.Arguments = append(rd.Arguments, p.argument(c.sub(part[0], part[1])))
+		}
+	}
+
+	for !c.done() {
+		switch {
+		case c.accept("RETURNS"):
+			s, e := c.until(routineOptions...)
+			rd.Returns = c.render(s, e)
+		case c.accept("LANGUAGE"):
+			t := c.next()
+			rd.Language = strings.ToLower(t.text)
+		default:
+			c.next()
+		}
+	}
+
+	p.schema(schemaName)
+	p.routines[routineKey(schemaName, name, rd.Arguments)] = rd
+}
+
+// routineKey identifies an overload the way PostgreSQL does: by schema, name
+// and input argument types.
+func routineKey(schemaName, name string, args []fixture.ArgumentDoc) string {
+	var types []string
+	for _, arg := range args {
+		if arg.Mode != "OUT" {
+			types = append(types, fixture.ResolveType(arg.Type).UDTName)
+		}
+	}
+	return schemaName + "." + name + "(" + strings.Join(types, ",") + ")"
+}
+
+// routine resolves the routine a COMMENT or DROP names: by its argument
+// types when listed, otherwise the only routine with that name.
+func (p *parser) routine(c *cursor, schemaName, name string) (string, bool) {
+	if start, end, ok := c.group(); ok {
+		var args []fixture.ArgumentDoc
+		for _, part := range c.split(start, end) {
+			args = append(args, p.argument(c.sub(part[0], part[1])))
+		}
+		key := routineKey(schemaName, name, args)
+		_, ok := p.routines[key]
+		return key, ok
+	}
+
+	prefix := schemaName + "." + name + "("
+	var found string
+	for key := range p.routines {
+		if strings.HasPrefix(key, prefix) {
+			if found != "" {
+				return "", false
+			}
+			found = key
+		}
+	}
+	return found, found != ""
+}
+
+// argument parses "[mode] [name] type [DEFAULT expr]".
+func (p *parser) argument(c *cursor) fixture.ArgumentDoc {
+	arg := fixture.ArgumentDoc{Mode: "IN"}
+	if c.peekAny("IN", "OUT", "INOUT", "VARIADIC") {
+		arg.Mode = strings.ToUpper(c.next().text)
+		if arg.Mode == "IN" && c.accept("OUT") {
+			arg.Mode = "INOUT"
+		}
+	}
+
+	s, e := c.until("DEFAULT", "=")
+	typeStart := s
+	// A leading word is the argument name unless the whole span is a known type
+	if e-s > 1 && fixture.ResolveType(c.render(s, e)).DataType == "USER-DEFINED" {
+		arg.Name = c.sub(s, s+1).render(0, 1)
+		typeStart = s + 1
+	}
+	arg.Type = c.render(typeStart, e)
+
+	if c.accept("DEFAULT") || c.acceptPunct("=") {
+		def := c.rest()
+		arg.Default = &def
+	}
+	return arg
+}
+
+func (p *parser) createType(c *cursor) {
+	schemaName, name := p.qualify(c.name())
+	key := schemaName + "." + name
+
+	if !c.accept("AS") {
+		p.skip(c.stmt, "base and shell types are not supported")
+		return
+	}
+
+	switch {
+	case c.accept("ENUM"):
+		ed := &fixture.EnumDoc{Name: name, Schema: schemaName}
+		if start, end, ok := c.group(); ok {
+			for n := start; n < end; n++ {
+				if t := c.stmt.tokens[n]; t.kind == tokString {
+					ed.Values = append(ed.Values, t.text)
+				}
+			}
+		}
+		p.enums[key] = ed
+
+	case c.accept("RANGE"):
+		td := &fixture.TypeDoc{Name: name, Schema: schemaName, Kind: "range"}
+		if start, end, ok := c.group(); ok {
+			opts := c.sub(start, end)
+			if opts.accept("SUBTYPE") {
+				opts.acceptPunct("=")
+				s, e := opts.until(",")
+				td.BaseType = opts.render(s, e)
+			}
+		}
+		p.types[key] = td
+
+	case c.peek().isPunct("("):
+		td := &fixture.TypeDoc{Name: name, Schema: schemaName, Kind: "composite"}
+		start, end, _ := c.group()
+		for _, part := range c.split(start, end) {
+			attr := c.sub(part[0], part[1])
+			attrName, _ := attr.ident()
+			s, e := attr.until("COLLATE")
+			td.Attributes = append(td.Attributes, fixture.AttributeDoc{Name: attrName, Type: attr.render(s, e)})
+		}
+		p.types[key] = td
+
+	default:
+		p.skip(c.stmt, "unsupported CREATE TYPE form")
+		return
+	}
+
+	p.schema(schemaName)
+}
+
+func (p *parser) createDomain(c *cursor) {
+	schemaName, name := p.qualify(c.name())
+	c.accept("AS")
+
+	td := &fixture.TypeDoc{Name: name, Schema: schemaName, Kind: "domain"}
+	s, e := c.until(columnStop...)
+	td.BaseType = c.render(s, e)
+
+	for !c.done() {
+		if c.accept("CHECK") {
+			if start, end, ok := c.group(); ok {
+				td.Check = "CHECK " + c.source(start-1, end+1)
+			}
+			continue
+		}
+		c.next()
+	}
+
+	p.schema(schemaName)
+	p.types[schemaName+"."+name] = td
+}
+
+func (p *parser) createSequence(c *cursor) {
+	c.accept("IF", "NOT", "EXISTS")
+	schemaName, name := p.qualify(c.name())
+	seq := newSequence(schemaName, name, "bigint")
+	p.sequenceOptions(c, seq)
+	p.schema(schemaName)
+	p.sequences[schemaName+"."+name] = seq
+}
+
+func (p *parser) alterSequence(c *cursor) {
+	c.accept("IF", "EXISTS")
+	schemaName, name := p.qualify(c.name())
+	seq, ok := p.sequences[schemaName+"."+name]
+	if !ok {
+		if c.find("OWNER") {
+			return
+		}
+		p.skip(c.stmt, "ALTER SEQUENCE on unknown sequence")
+		return
+	}
+	if c.peekAny("OWNER") {
+		return
+	}
+	p.sequenceOptions(c, seq)
+}
+
+// sequenceOptions applies CREATE/ALTER SEQUENCE options.
+func (p *parser) sequenceOptions(c *cursor, seq *fixture.SequenceDoc) {
+	for !c.done() {
+		switch {
+		case c.accept("AS"):
+			s, e := c.until("INCREMENT", "MINVALUE", "MAXVALUE", "NO", "START", "CACHE", "CYCLE", "OWNED")
+			seq.Type = fixture.ResolveType(c.render(s, e)).DataType
+			seq.Max = int64Ptr(maxForType(seq.Type))
+		case c.accept("INCREMENT"):
+			c.accept("BY")
+			seq.Increment = signedInt(c)
+		case c.accept("NO", "MINVALUE"), c.accept("NO", "MAXVALUE"):
+			// Keep the type's defaults
+		case c.accept("MINVALUE"):
+			seq.Min = signedInt(c)
+		case c.accept("MAXVALUE"):
+			seq.Max = signedInt(c)
+		case c.accept("START"):
+			c.accept("WITH")
+			seq.Start = signedInt(c)
+		case c.accept("CYCLE"):
+			seq.Cycle = true
+		case c.accept("NO", "CYCLE"):
+			seq.Cycle = false
+		case c.accept("OWNED", "BY"):
+			parts := c.name()
+			if len(parts) >= 2 {
+				schemaName, table := p.qualify(parts[:len(parts)-1])
+				seq.OwnedBy = ownerName(schemaName, table, parts[len(parts)-1])
+			} else {
+				seq.OwnedBy = ""
+			}
+		default:
+			c.next()
+		}
+	}
+}
+
+func (p *parser) alterType(c *cursor) {
+	schemaName, name := p.qualify(c.name())
+	ed, ok := p.enums[schemaName+"."+name]
+
+	switch {
+	case c.peekAny("OWNER"):
+	case ok && c.accept("ADD", "VALUE"):
+		c.accept("IF", "NOT", "EXISTS")
+		value := c.next().text
+		if contains(ed.Values, value) {
+			return
+		}
+		pos := len(ed.Values)
+		if c.accept("BEFORE") || c.accept("AFTER") {
+			before := c.stmt.tokens[c.pos-1].is("BEFORE")
+			anchor := c.next().text
+			for i, v := range ed.Values {
+				if v == anchor {
+					pos = i
+					if !before {
+						pos++
+					}
+				}
+			}
+		}
+		ed.Values = append(ed.Values[:pos], append([]string{value}, ed.Values[pos:]...)...)
+	case ok && c.accept("RENAME", "VALUE"):
+		from := c.next().text
+		c.accept("TO")
+		replace(ed.Values, from, c.next().text)
+	default:
+		p.skip(c.stmt, "unsupported ALTER TYPE action")
+	}
+}
+
+func (p *parser) createTrigger(c *cursor) {
+	definition := "CREATE " + c.rest()
+	c.accept("CONSTRAINT")
+	c.accept("TRIGGER")
+	name, _ := c.ident()
+
+	tr := fixture.TriggerDoc{Name: name, Definition: definition}
+	switch {
+	case c.accept("INSTEAD", "OF"):
+		tr.Timing = "instead of"
+	default:
+		tr.Timing = strings.ToLower(c.next().text)
+	}
+
+	for !c.accept("ON") && !c.done() {
+		t := c.next()
+		if t.is("INSERT") || t.is("UPDATE") || t.is("DELETE") || t.is("TRUNCATE") {
+			tr.Events = append(tr.Events, strings.ToLower(t.text))
+		}
+	}
+
+	schemaName, table := p.qualify(c.name())
+	td, ok := p.tables[schemaName+"."+table]
+	if !ok {
+		p.skip(c.stmt, "trigger on unknown table "+schemaName+"."+table)
+		return
+	}
+
+	if c.find("EXECUTE") {
+		c.accept("FUNCTION")
+		c.accept("PROCEDURE")
+		fs, fn := p.qualify(c.name())
+		tr.Function = fn
+		if fs != "public" {
+			tr.Function = fs + "." + fn
+		}
+	}
+
+	td.Triggers = append(td.Triggers, tr)
+}
+
+func (p *parser) comment(c *cursor) {
+	var kind string
+	switch {
+	case c.accept("MATERIALIZED", "VIEW"):
+		p.skip(c.stmt, "materialized views are not supported")
+		return
+	case c.peekAny("TABLE", "COLUMN", "VIEW", "SCHEMA", "TYPE", "DOMAIN", "INDEX", "SEQUENCE", "FUNCTION", "PROCEDURE"):
+		kind = strings.ToUpper(c.next().text)
+	case c.peekAny("EXTENSION"):
+		return
+	default:
+		p.skip(c.stmt, "unsupported COMMENT target")
+		return
+	}
+
+	parts := c.name()
+	var routine *fixture.RoutineDoc
+	if kind == "FUNCTION" || kind == "PROCEDURE" {
+		s, n := p.qualify(parts)
+		if key, ok := p.routine(c, s, n); ok {
+			routine = p.routines[key]
+		}
+	} else if c.peek().isPunct("(") {
+		c.group()
+	}
+	if !c.accept("IS") {
+		p.skip(c.stmt, "malformed COMMENT")
+		return
+	}
+	var text string
+	if t := c.next(); t.kind == tokString {
+		text = t.text
+	}
+
+	applied := false
+	switch kind {
+	case "TABLE":
+		s, n := p.qualify(parts)
+		if td, ok := p.tables[s+"."+n]; ok {
+			td.Comment, applied = text, true
+		}
+	case "COLUMN":
+		if len(parts) >= 2 {
+			s, n := p.qualify(parts[:len(parts)-1])
+			if td, ok := p.tables[s+"."+n]; ok {
+				if col := findColumn(td, parts[len(parts)-1]); col != nil {
+					col.Comment, applied = text, true
+				}
+			}
+		}
+	case "VIEW":
+		s, n := p.qualify(parts)
+		if vd, ok := p.views[s+"."+n]; ok {
+			vd.Comment, applied = text, true
+		}
+	case "SCHEMA":
+		p.schema(parts[0]).Comment, applied = text, true
+	case "TYPE", "DOMAIN":
+		s, n := p.qualify(parts)
+		if ed, ok := p.enums[s+"."+n]; ok {
+			ed.Comment, applied = text, true
+		} else if td, ok := p.types[s+"."+n]; ok {
+			td.Comment, applied = text, true
+		}
+	case "INDEX":
+		s, n := p.qualify(parts)
+		if owner, ok := p.indexes[s+"."+n]; ok {
+			td := p.tables[owner]
+			for i := range td.Indexes {
+				if td.Indexes[i].Name == n {
+					td.Indexes[i].Comment, applied = text, true
+				}
+			}
+		}
+	case "SEQUENCE":
+		s, n := p.qualify(parts)
+		if seq, ok := p.sequences[s+"."+n]; ok {
+			seq.Comment, applied = text, true
+		}
+	case "FUNCTION", "PROCEDURE":
+		if routine != nil {
+			routine.Comment, applied = text, true
+		}
+	}
+
+	if !applied {
+		p.skip(c.stmt, "comment on unknown object")
+	}
+}
+
+func (p *parser) drop(c *cursor) {
+	kind := strings.ToUpper(c.next().text)
+	if kind == "MATERIALIZED" {
+		c.accept("VIEW")
+	}
+	c.accept("CONCURRENTLY")
+	c.accept("IF", "EXISTS")
+
+	for {
+		s, n := p.qualify(c.name())
+		key := s + "." + n
+		if kind == "FUNCTION" || kind == "PROCEDURE" {
+			key, _ = p.routine(c, s, n)
+		} else if c.peek().isPunct("(") {
+			c.group()
+		}
+
+		switch kind {
+		case "TABLE":
+			delete(p.tables, key)
+			for name, owner := range p.indexes {
+				if owner == key {
+					delete(p.indexes, name)
+				}
+			}
+		case "VIEW":
+			delete(p.views, key)
+		case "INDEX":
+			if owner, ok := p.indexes[key]; ok {
+				td := p.tables[owner]
+				var kept []fixture.IndexDoc
+				for _, idx := range td.Indexes {
+					if idx.Name != n {
+						kept = append(kept, idx)
+					}
+				}
+				td.Indexes = kept
+				delete(p.indexes, key)
+			}
+		case "TYPE", "DOMAIN":
+			delete(p.enums, key)
+			delete(p.types, key)
+		case "SEQUENCE":
+			delete(p.sequences, key)
+		case "FUNCTION", "PROCEDURE":
+			delete(p.routines, key)
+		case "SCHEMA":
+			delete(p.schemas, n)
+		case "TRIGGER":
+			if c.accept("ON") {
+				ts, tn := p.qualify(c.name())
+				if td, ok := p.tables[ts+"."+tn]; ok {
+					var kept []fixture.TriggerDoc
+					for _, tr := range td.Triggers {
+						if tr.Name != n {
+							kept = append(kept, tr)
+						}
+					}
+					td.Triggers = kept
+				}
+			}
+		case "EXTENSION", "OWNED", "ROLE", "USER", "DATABASE":
+		default:
+			p.skip(c.stmt, "unsupported DROP statement")
+			return
+		}
+
+		if !c.acceptPunct(",") {
+			return
+		}
+	}
+}
+
+// newSequence returns a sequence with PostgreSQL's default options.
+func newSequence(schemaName, name, dataType string) *fixture.SequenceDoc {
+	return &fixture.SequenceDoc{
+		Name:      name,
+		Schema:    schemaName,
+		Type:      dataType,
+		Start:     int64Ptr(1),
+		Increment: int64Ptr(1),
+		Min:       int64Ptr(1),
+		Max:       int64Ptr(maxForType(dataType)),
+	}
+}
+
+func maxForType(dataType string) int64 {
+	switch dataType {
+	case "smallint":
+		return 32767
+	case "integer":
+		return 2147483647
+	default:
+		return 9223372036854775807
+	}
+}
+
+// signedInt consumes an optionally negative integer literal.
+func signedInt(c *cursor) *int64 {
+	neg := c.acceptPunct("-")
+	n, err := strconv.ParseInt(c.next().text, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if neg {
+		n = -n
+	}
+	return &n
+}
+
+func appendUnique(values []string, v string) []string {
+	if contains(values, v) {
+		return values
+	}
+	return append(values, v)
+}
+
+func int64Ptr(n int64) *int64 {
+	return &n
+}
@@ -0,0 +1,320 @@
+package ddl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+)
+
+// Skipped records a statement or clause the parser did not apply.
+type Skipped struct {
+	File   string
+	Line   int
+	Text   string
+	Reason string
+}
+
+func (s Skipped) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", s.File, s.Line, s.Text, s.Reason)
+}
+
+// parser accumulates DDL into fixture documents keyed by "schema.name".
+type parser struct {
+	file       string
+	searchPath string
+
+	databaseName string
+	version      string
+
+	schemas   map[string]*fixture.SchemaDoc
+	tables    map[string]*fixture.TableDoc
+	views     map[string]*fixture.ViewDoc
+	routines  map[string]*fixture.RoutineDoc
+	enums     map[string]*fixture.EnumDoc
+	sequences map[string]*fixture.SequenceDoc
+	types     map[string]*fixture.TypeDoc
+
+	// index name ("schema.index") -> owning table key
+	indexes map[string]string
+
+	skipped []Skipped
+}
+
+func newParser() *parser {
+	return &parser{
+		searchPath: "public",
+		schemas:    make(map[string]*fixture.SchemaDoc),
+		tables:     make(map[string]*fixture.TableDoc),
+		views:      make(map[string]*fixture.ViewDoc),
+		routines:   make(map[string]*fixture.RoutineDoc),
+		enums:      make(map[string]*fixture.EnumDoc),
+		sequences:  make(map[string]*fixture.SequenceDoc),
+		types:      make(map[string]*fixture.TypeDoc),
+		indexes:    make(map[string]string),
+	}
+}
+
+var dumpVersion = regexp.MustCompile(`(?m)^-- Dumped from database version (\S+)`)
+
+// parse applies one file's statements in order.
+func (p *parser) parse(file, src string) error {
+	p.file = file
+	if m := dumpVersion.FindStringSubmatch(src); m != nil && p.version == "" {
+		p.version = m[1]
+	}
+
+	stmts, err := lex(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	for _, stmt := range stmts {
+		p.statement(stmt)
+	}
+	return nil
+}
+
+// statement dispatches on the leading keywords.
+func (p *parser) statement(stmt statement) {
+	c := &cursor{stmt: stmt}
+
+	switch {
+	case c.accept("CREATE"):
+		p.create(c)
+	case c.accept("ALTER"):
+		p.alter(c)
+	case c.accept("COMMENT", "ON"):
+		p.comment(c)
+	case c.accept("DROP"):
+		p.drop(c)
+	case c.accept("SET"):
+		p.set(c)
+	case c.peekAny("SELECT", "GRANT", "REVOKE", "BEGIN", "COMMIT", "START", "END", "ROLLBACK",
+		"INSERT", "UPDATE", "DELETE", "COPY", "ANALYZE", "VACUUM", "RESET", "DO"):
+		// No effect on the schema model
+	default:
+		p.skip(stmt, "unsupported statement")
+	}
+}
+
+func (p *parser) create(c *cursor) {
+	c.accept("OR", "REPLACE")
+
+	switch {
+	case c.peekAny("TABLE", "UNLOGGED", "TEMP", "TEMPORARY", "GLOBAL", "LOCAL") && c.find("TABLE"):
+		p.createTable(c)
+	case c.peekAny("UNIQUE", "INDEX"):
+		p.createIndex(c)
+	case c.peekAny("VIEW", "RECURSIVE") || (c.peekAny("TEMP", "TEMPORARY") && c.find("VIEW")):
+		p.createView(c)
+	case c.peekAny("FUNCTION", "PROCEDURE"):
+		p.createRoutine(c)
+	case c.accept("TYPE"):
+		p.createType(c)
+	case c.accept("DOMAIN"):
+		p.createDomain(c)
+	case c.accept("SEQUENCE"), c.peekAny("TEMP", "TEMPORARY") && c.find("SEQUENCE"):
+		p.createSequence(c)
+	case c.accept("SCHEMA"):
+		p.createSchema(c)
+	case c.peekAny("TRIGGER", "CONSTRAINT"):
+		p.createTrigger(c)
+	case c.accept("DATABASE"):
+		p.databaseName, _ = c.ident()
+	case c.peekAny("EXTENSION"):
+		// Extensions only matter through the types they provide
+	default:
+		p.skip(c.stmt, "unsupported CREATE statement")
+	}
+}
+
+func (p *parser) alter(c *cursor) {
+	switch {
+	case c.accept("TABLE"):
+		p.alterTable(c)
+	case c.accept("SEQUENCE"):
+		p.alterSequence(c)
+	case c.accept("TYPE"):
+		p.alterType(c)
+	default:
+		if c.find("OWNER") {
+			return
+		}
+		p.skip(c.stmt, "unsupported ALTER statement")
+	}
+}
+
+func (p *parser) set(c *cursor) {
+	c.accept("LOCAL")
+	c.accept("SESSION")
+	if !c.accept("search_path") {
+		return
+	}
+	if !c.accept("TO") {
+		c.acceptPunct("=")
+	}
+	if name, ok := c.ident(); ok && name != "pg_catalog" {
+		p.searchPath = name
+	}
+}
+
+func (p *parser) createSchema(c *cursor) {
+	c.accept("IF", "NOT", "EXISTS")
+	name, ok := c.ident()
+	if !ok {
+		p.skip(c.stmt, "missing schema name")
+		return
+	}
+	s := p.schema(name)
+	if c.accept("AUTHORIZATION") {
+		s.Owner, _ = c.ident()
+	}
+}
+
+// schema returns the schema document, creating it on first reference.
+func (p *parser) schema(name string) *fixture.SchemaDoc {
+	s, ok := p.schemas[name]
+	if !ok {
+		s = &fixture.SchemaDoc{Name: name}
+		p.schemas[name] = s
+	}
+	return s
+}
+
+func (p *parser) skip(stmt statement, reason string) {
+	p.skipped = append(p.skipped, Skipped{
+		File:   p.file,
+		Line:   stmt.line,
+		Text:   summarize(stmt.text()),
+		Reason: reason,
+	})
+}
+
+// document assembles everything parsed so far, sorted the way the live
+// catalog queries order their results.
+func (p *parser) document(defaultName string) *fixture.Document {
+	doc := &fixture.Document{
+		Database: p.databaseName,
+		Engine:   "postgresql",
+		Version:  p.version,
+	}
+	if doc.Database == "" {
+		doc.Database = defaultName
+	}
+
+	for _, k := range sortedKeys(p.schemas) {
+		doc.Schemas = append(doc.Schemas, *p.schemas[k])
+	}
+	for _, k := range sortedKeys(p.tables) {
+		doc.Tables = append(doc.Tables, p.resolveTable(p.tables[k]))
+	}
+	for _, k := range sortedKeys(p.views) {
+		vd := *p.views[k]
+		// FROM items that are functions, CTEs or subqueries are not dependencies
+		vd.DependsOn = nil
+		for _, dep := range p.views[k].DependsOn {
+			if p.tables[dep] != nil || (p.views[dep] != nil && dep != k) {
+				vd.DependsOn = append(vd.DependsOn, dep)
+			}
+		}
+		doc.Views = append(doc.Views, vd)
+	}
+	for _, k := range sortedKeys(p.routines) {
+		doc.Routines = append(doc.Routines, *p.routines[k])
+	}
+	for _, k := range sortedKeys(p.enums) {
+		doc.Enums = append(doc.Enums, *p.enums[k])
+	}
+	for _, k := range sortedKeys(p.sequences) {
+		doc.Sequences = append(doc.Sequences, *p.sequences[k])
+	}
+	for _, k := range sortedKeys(p.types) {
+		doc.Types = append(doc.Types, *p.types[k])
+	}
+
+	return doc
+}
+
+// resolveTable drops foreign keys and parents that point outside the parsed
+// files, reporting them instead of failing validation.
+func (p *parser) resolveTable(td *fixture.TableDoc) fixture.TableDoc {
+	out := *td
+	out.ForeignKeys = nil
+	for _, fk := range td.ForeignKeys {
+		target, cols := splitReference(fk.References)
+		targetDoc, ok := p.tables[target]
+		if !ok {
+			p.skipped = append(p.skipped, Skipped{File: p.file, Text: fmt.Sprintf("%s.%s foreign key %s", td.Schema, td.Name, fk.Name), Reason: "references unknown table " + target})
+			continue
+		}
+		if len(cols) == 0 {
+			cols = targetDoc.PrimaryKey
+		}
+		if len(cols) != len(fk.Columns) {
+			p.skipped = append(p.skipped, Skipped{File: p.file, Text: fmt.Sprintf("%s.%s foreign key %s", td.Schema, td.Name, fk.Name), Reason: "referenced key not found on " + target})
+			continue
+		}
+		fk.References = formatReference(target, cols)
+		out.ForeignKeys = append(out.ForeignKeys, fk)
+	}
+
+	out.Inherits = nil
+	for _, parent := range td.Inherits {
+		if _, ok := p.tables[parent]; ok {
+			out.Inherits = append(out.Inherits, parent)
+		}
+	}
+
+	return out
+}
+
+// qualify splits a parsed name into schema and object, applying the search path.
+func (p *parser) qualify(parts []string) (string, string) {
+	switch len(parts) {
+	case 0:
+		return p.searchPath, ""
+	case 1:
+		return p.searchPath, parts[0]
+	default:
+		return parts[len(parts)-2], parts[len(parts)-1]
+	}
+}
+
+func splitReference(ref string) (string, []string) {
+	name, cols, _ := strings.Cut(ref, "(")
+	var result []string
+	for _, c := range strings.Split(strings.TrimSuffix(cols, ")"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			result = append(result, c)
+		}
+	}
+	return name, result
+}
+
+func formatReference(table string, cols []string) string {
+	if len(cols) == 0 {
+		return table
+	}
+	return fmt.Sprintf("%s(%s)", table, strings.Join(cols, ", "))
+}
+
+func sortedKeys[T any](m map[string]*T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// summarize shortens a statement to its first line for reporting.
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > 80 {
+		text = text[:77] + "..."
+	}
+	return text
+}
@@ -0,0 +1,579 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+)
+
+// columnStop lists the keywords that end a column's type or default.
+var columnStop = []string{"CONSTRAINT", "NOT", "NULL", "DEFAULT", "PRIMARY", "UNIQUE", "CHECK",
+	"REFERENCES", "COLLATE", "GENERATED", "DEFERRABLE", "INITIALLY"}
+
+func (p *parser) createTable(c *cursor) {
+	c.accept("IF", "NOT", "EXISTS")
+	schemaName, name := p.qualify(c.name())
+	if name == "" {
+		p.skip(c.stmt, "missing table name")
+		return
+	}
+	if c.peekAny("PARTITION", "OF") {
+		p.skip(c.stmt, "partitions and typed tables are not supported")
+		return
+	}
+
+	start, end, ok := c.group()
+	if !ok {
+		p.skip(c.stmt, "CREATE TABLE AS is not supported")
+		return
+	}
+
+	td := &fixture.TableDoc{Name: name, Schema: schemaName}
+	for _, part := range c.split(start, end) {
+		el := c.sub(part[0], part[1])
+		if el.peekAny("CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "EXCLUDE") {
+			p.tableConstraint(el, td)
+		} else if el.peekAny("LIKE") {
+			p.skip(c.stmt, "LIKE clauses are not supported")
+		} else {
+			p.addColumn(el, td)
+		}
+	}
+
+	if c.accept("INHERITS") {
+		if s, e, ok := c.group(); ok {
+			for _, part := range c.split(s, e) {
+				ps, pn := p.qualify(c.sub(part[0], part[1]).name())
+				td.Inherits = append(td.Inherits, ps+"."+pn)
+			}
+		}
+	}
+
+	p.schema(schemaName)
+	p.tables[schemaName+"."+name] = td
+}
+
+// addColumn parses a column definition with its inline constraints.
+func (p *parser) addColumn(c *cursor, td *fixture.TableDoc) {
+	name, ok := c.ident()
+	if !ok {
+		p.skip(c.stmt, "malformed column definition")
+		return
+	}
+
+	ts, te := c.until(columnStop...)
+	col := fixture.ColumnDoc{Name: name, Type: c.render(ts, te)}
+
+	var constraintName string
+	for !c.done() {
+		switch {
+		case c.accept("CONSTRAINT"):
+			constraintName, _ = c.ident()
+			continue
+		case c.accept("NOT", "NULL"):
+			col.Nullable = boolPtr(false)
+		case c.accept("NULL"):
+			col.Nullable = boolPtr(true)
+		case c.accept("DEFAULT"):
+			s, e := c.until(columnStop...)
+			if s == e && c.accept("NULL") {
+				break
+			}
+			def := c.source(s, e)
+			col.Default = &def
+		case c.accept("PRIMARY", "KEY"):
+			td.PrimaryKey = []string{name}
+			td.PKName = constraintName
+		case c.accept("UNIQUE"):
+			u := fixture.UniqueDoc{Name: constraintName, Columns: []string{name}}
+			u.NullsNotDistinct = c.accept("NULLS", "NOT", "DISTINCT")
+			c.accept("NULLS", "DISTINCT")
+			td.Unique = append(td.Unique, u)
+		case c.accept("CHECK"):
+			if s, e, ok := c.group(); ok {
+				td.Checks = append(td.Checks, fixture.CheckDoc{
+					Name:       orDefault(constraintName, td.Name+"_"+name+"_check"),
+					Expression: c.source(s, e),
+				})
+			}
+		case c.accept("REFERENCES"):
+			fk := p.references(c, td.Schema)
+			fk.Name = orDefault(constraintName, fmt.Sprintf("%s_%s_fkey", td.Name, name))
+			fk.Columns = []string{name}
+			td.ForeignKeys = append(td.ForeignKeys, fk)
+		case c.accept("COLLATE"):
+			parts := c.name()
+			col.Collation = parts[len(parts)-1]
+		case c.accept("GENERATED"):
+			p.generated(c, &col, td)
+		case c.accept("DEFERRABLE"), c.accept("NOT", "DEFERRABLE"):
+		case c.accept("INITIALLY"):
+			c.next()
+		default:
+			p.skip(c.stmt, fmt.Sprintf("unsupported clause %q on column %s", c.peek().text, name))
+			c.next()
+			c.until(columnStop...)
+		}
+		constraintName = ""
+	}
+
+	if fixture.ResolveType(col.Type).Serial {
+		p.addSerialSequence(td, col)
+	}
+
+	td.Columns = append(td.Columns, col)
+}
+
+// generated handles GENERATED ALWAYS AS (expr) STORED and identity columns.
+func (p *parser) generated(c *cursor, col *fixture.ColumnDoc, td *fixture.TableDoc) {
+	identity := "ALWAYS"
+	if c.accept("BY", "DEFAULT") {
+		identity = "BY DEFAULT"
+	} else {
+		c.accept("ALWAYS")
+	}
+	c.accept("AS")
+
+	if c.accept("IDENTITY") {
+		col.Identity = identity
+		seqName := fmt.Sprintf("%s_%s_seq", td.Name, col.Name)
+		if s, e, ok := c.group(); ok {
+			opts := c.sub(s, e)
+			if opts.find("SEQUENCE") && opts.accept("NAME") {
+				_, seqName = p.qualify(opts.name())
+			}
+		}
+		p.sequences[td.Schema+"."+seqName] = newSequence(td.Schema, seqName, fixture.ResolveType(col.Type).DataType)
+		return
+	}
+
+	if s, e, ok := c.group(); ok {
+		col.Generated = c.source(s, e)
+	}
+	c.accept("STORED")
+	c.accept("VIRTUAL")
+}
+
+// addSerialSequence registers the sequence a serial column implicitly owns.
+func (p *parser) addSerialSequence(td *fixture.TableDoc, col fixture.ColumnDoc) {
+	seqName := fmt.Sprintf("%s_%s_seq", td.Name, col.Name)
+	seq := newSequence(td.Schema, seqName, fixture.ResolveType(col.Type).DataType)
+	seq.OwnedBy = ownerName(td.Schema, td.Name, col.Name)
+	p.sequences[td.Schema+"."+seqName] = seq
+}
+
+// tableConstraint parses a table-level constraint into the table document.
+func (p *parser) tableConstraint(c *cursor, td *fixture.TableDoc) {
+	var name string
+	if c.accept("CONSTRAINT") {
+		name, _ = c.ident()
+	}
+
+	switch {
+	case c.accept("PRIMARY", "KEY"):
+		cols, ok := c.identList()
+		if !ok {
+			p.skip(c.stmt, "malformed primary key")
+			return
+		}
+		td.PrimaryKey = cols
+		td.PKName = name
+	case c.accept("UNIQUE"):
+		nnd := c.accept("NULLS", "NOT", "DISTINCT")
+		c.accept("NULLS", "DISTINCT")
+		cols, ok := c.identList()
+		if !ok {
+			p.skip(c.stmt, "unsupported UNIQUE constraint form")
+			return
+		}
+		td.Unique = append(td.Unique, fixture.UniqueDoc{Name: name, Columns: cols, NullsNotDistinct: nnd})
+	case c.accept("CHECK"):
+		s, e, ok := c.group()
+		if !ok {
+			p.skip(c.stmt, "malformed CHECK constraint")
+			return
+		}
+		td.Checks = append(td.Checks, fixture.CheckDoc{
+			Name:       orDefault(name, td.Name+"_check"),
+			Expression: c.source(s, e),
+		})
+	case c.accept("FOREIGN", "KEY"):
+		cols, ok := c.identList()
+		if !ok || !c.accept("REFERENCES") {
+			p.skip(c.stmt, "malformed foreign key")
+			return
+		}
+		fk := p.references(c, td.Schema)
+		fk.Name = orDefault(name, fmt.Sprintf("%s_%s_fkey", td.Name, strings.Join(cols, "_")))
+		fk.Columns = cols
+		fk.NotValid = fk.NotValid || c.accept("NOT", "VALID")
+		td.ForeignKeys = append(td.ForeignKeys, fk)
+	default:
+		p.skip(c.stmt, "unsupported table constraint")
+	}
+}
+
+// references parses the target and options of a REFERENCES clause.
+func (p *parser) references(c *cursor, defaultSchema string) fixture.ForeignKeyDoc {
+	parts := c.name()
+	targetSchema, target := defaultSchema, ""
+	if len(parts) == 1 {
+		targetSchema, target = p.searchPath, parts[0]
+	} else if len(parts) > 1 {
+		targetSchema, target = parts[len(parts)-2], parts[len(parts)-1]
+	}
+
+	var cols []string
+	if c.peek().isPunct("(") {
+		cols, _ = c.identList()
+	}
+
+	fk := fixture.ForeignKeyDoc{References: formatReference(targetSchema+"."+target, cols)}
+	for !c.done() {
+		switch {
+		case c.accept("MATCH"):
+			fk.Match = strings.ToUpper(c.next().text)
+		case c.accept("ON", "DELETE"):
+			fk.OnDelete = referentialAction(c)
+		case c.accept("ON", "UPDATE"):
+			fk.OnUpdate = referentialAction(c)
+		case c.accept("DEFERRABLE"):
+			fk.Deferrable = true
+		case c.accept("NOT", "DEFERRABLE"):
+		case c.accept("INITIALLY", "DEFERRED"):
+			fk.Deferred = true
+		case c.accept("INITIALLY", "IMMEDIATE"):
+		case c.accept("NOT", "VALID"):
+			fk.NotValid = true
+		default:
+			return fk
+		}
+	}
+	return fk
+}
+
+func referentialAction(c *cursor) string {
+	switch {
+	case c.accept("NO", "ACTION"):
+		return "NO ACTION"
+	case c.accept("SET", "NULL"):
+		c.group()
+		return "SET NULL"
+	case c.accept("SET", "DEFAULT"):
+		c.group()
+		return "SET DEFAULT"
+	default:
+		return strings.ToUpper(c.next().text)
+	}
+}
+
+func (p *parser) alterTable(c *cursor) {
+	c.accept("IF", "EXISTS")
+	c.accept("ONLY")
+	schemaName, name := p.qualify(c.name())
+	key := schemaName + "." + name
+	c.acceptPunct("*")
+
+	td, ok := p.tables[key]
+	if !ok {
+		if c.find("OWNER") {
+			return
+		}
+		p.skip(c.stmt, "ALTER TABLE on unknown table "+key)
+		return
+	}
+
+	// RENAME TO changes the table key and must be handled before actions
+	if c.accept("RENAME", "TO") {
+		newName, _ := c.ident()
+		p.renameTable(td, newName)
+		return
+	}
+
+	for _, part := range c.split(c.pos, len(c.stmt.tokens)) {
+		p.alterTableAction(c.sub(part[0], part[1]), td)
+	}
+}
+
+func (p *parser) alterTableAction(c *cursor, td *fixture.TableDoc) {
+	switch {
+	case c.peekAny("OWNER"):
+		// Ownership is not modelled
+
+	case c.accept("ADD"):
+		if c.peekAny("CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "EXCLUDE") {
+			p.tableConstraint(c, td)
+			return
+		}
+		c.accept("COLUMN")
+		c.accept("IF", "NOT", "EXISTS")
+		p.addColumn(c, td)
+
+	case c.accept("DROP", "CONSTRAINT"):
+		c.accept("IF", "EXISTS")
+		name, _ := c.ident()
+		dropConstraint(td, name)
+
+	case c.accept("DROP"):
+		c.accept("COLUMN")
+		c.accept("IF", "EXISTS")
+		name, _ := c.ident()
+		p.dropColumn(td, name)
+
+	case c.accept("ALTER"):
+		c.accept("COLUMN")
+		name, _ := c.ident()
+		col := findColumn(td, name)
+		if col == nil {
+			p.skip(c.stmt, fmt.Sprintf("ALTER COLUMN on unknown column %s.%s", td.Name, name))
+			return
+		}
+		p.alterColumn(c, td, col)
+
+	case c.accept("RENAME", "CONSTRAINT"):
+		from, _ := c.ident()
+		c.accept("TO")
+		to, _ := c.ident()
+		renameConstraint(td, from, to)
+
+	case c.accept("RENAME"):
+		c.accept("COLUMN")
+		from, _ := c.ident()
+		c.accept("TO")
+		to, _ := c.ident()
+		p.renameColumn(td, from, to)
+
+	case c.accept("INHERIT"):
+		ps, pn := p.qualify(c.name())
+		td.Inherits = append(td.Inherits, ps+"."+pn)
+
+	default:
+		p.skip(c.stmt, "unsupported ALTER TABLE action "+summarize(c.rest()))
+	}
+}
+
+func (p *parser) alterColumn(c *cursor, td *fixture.TableDoc, col *fixture.ColumnDoc) {
+	switch {
+	case c.accept("SET", "DEFAULT"):
+		def := c.rest()
+		col.Default = &def
+	case c.accept("DROP", "DEFAULT"):
+		col.Default = nil
+	case c.accept("SET", "NOT", "NULL"):
+		col.Nullable = boolPtr(false)
+	case c.accept("DROP", "NOT", "NULL"):
+		col.Nullable = nil
+	case c.accept("SET", "DATA", "TYPE"), c.accept("TYPE"):
+		s, e := c.until("COLLATE", "USING")
+		col.Type = c.render(s, e)
+		if c.accept("COLLATE") {
+			parts := c.name()
+			col.Collation = parts[len(parts)-1]
+		}
+	case c.accept("ADD"):
+		if c.accept("GENERATED") {
+			p.generated(c, col, td)
+			return
+		}
+		p.skip(c.stmt, "unsupported ALTER COLUMN action")
+	case c.accept("DROP", "IDENTITY"):
+		col.Identity = ""
+	case c.peekAny("SET") && (c.find("STATISTICS") || c.find("STORAGE") || c.find("COMPRESSION")):
+		// Physical storage settings are not modelled
+	default:
+		p.skip(c.stmt, "unsupported ALTER COLUMN action "+summarize(c.rest()))
+	}
+}
+
+// dropColumn removes a column and any keys, indexes and foreign keys that
+// depended on it, as DROP COLUMN does.
+func (p *parser) dropColumn(td *fixture.TableDoc, name string) {
+	var cols []fixture.ColumnDoc
+	for _, col := range td.Columns {
+		if col.Name != name {
+			cols = append(cols, col)
+		}
+	}
+	td.Columns = cols
+
+	if contains(td.PrimaryKey, name) {
+		td.PrimaryKey, td.PKName = nil, ""
+	}
+
+	var unique []fixture.UniqueDoc
+	for _, u := range td.Unique {
+		if !contains(u.Columns, name) {
+			unique = append(unique, u)
+		}
+	}
+	td.Unique = unique
+
+	var indexes []fixture.IndexDoc
+	for _, idx := range td.Indexes {
+		if contains(idx.Columns, name) || contains(idx.Include, name) {
+			delete(p.indexes, td.Schema+"."+idx.Name)
+			continue
+		}
+		indexes = append(indexes, idx)
+	}
+	td.Indexes = indexes
+
+	var fks []fixture.ForeignKeyDoc
+	for _, fk := range td.ForeignKeys {
+		if !contains(fk.Columns, name) {
+			fks = append(fks, fk)
+		}
+	}
+	td.ForeignKeys = fks
+}
+
+// renameColumn renames a column and every reference to it.
+func (p *parser) renameColumn(td *fixture.TableDoc, from, to string) {
+	if col := findColumn(td, from); col != nil {
+		col.Name = to
+	}
+	replace(td.PrimaryKey, from, to)
+	for i := range td.Unique {
+		replace(td.Unique[i].Columns, from, to)
+	}
+	for i := range td.Indexes {
+		replace(td.Indexes[i].Columns, from, to)
+		replace(td.Indexes[i].Include, from, to)
+	}
+	for i := range td.ForeignKeys {
+		replace(td.ForeignKeys[i].Columns, from, to)
+	}
+
+	// Foreign keys in other tables that name this column explicitly
+	key := td.Schema + "." + td.Name
+	for _, other := range p.tables {
+		for i, fk := range other.ForeignKeys {
+			target, cols := splitReference(fk.References)
+			if target == key {
+				replace(cols, from, to)
+				other.ForeignKeys[i].References = formatReference(target, cols)
+			}
+		}
+	}
+}
+
+// renameTable moves a table to a new key and updates references to it.
+func (p *parser) renameTable(td *fixture.TableDoc, newName string) {
+	oldKey := td.Schema + "." + td.Name
+	newKey := td.Schema + "." + newName
+	delete(p.tables, oldKey)
+	td.Name = newName
+	p.tables[newKey] = td
+
+	for _, other := range p.tables {
+		for i, fk := range other.ForeignKeys {
+			if target, cols := splitReference(fk.References); target == oldKey {
+				other.ForeignKeys[i].References = formatReference(newKey, cols)
+			}
+		}
+		replace(other.Inherits, oldKey, newKey)
+	}
+	for name, owner := range p.indexes {
+		if owner == oldKey {
+			p.indexes[name] = newKey
+		}
+	}
+}
+
+func dropConstraint(td *fixture.TableDoc, name string) {
+	if td.PKName == name || (td.PKName == "" && td.Name+"_pkey" == name) {
+		td.PrimaryKey, td.PKName = nil, ""
+	}
+
+	var unique []fixture.UniqueDoc
+	for _, u := range td.Unique {
+		if u.Name != name {
+			unique = append(unique, u)
+		}
+	}
+	td.Unique = unique
+
+	var checks []fixture.CheckDoc
+	for _, ch := range td.Checks {
+		if ch.Name != name {
+			checks = append(checks, ch)
+		}
+	}
+	td.Checks = checks
+
+	var fks []fixture.ForeignKeyDoc
+	for _, fk := range td.ForeignKeys {
+		if fk.Name != name {
+			fks = append(fks, fk)
+		}
+	}
+	td.ForeignKeys = fks
+}
+
+func renameConstraint(td *fixture.TableDoc, from, to string) {
+	if td.PKName == from || (td.PKName == "" && td.Name+"_pkey" == from) {
+		td.PKName = to
+	}
+	for i := range td.Unique {
+		if td.Unique[i].Name == from {
+			td.Unique[i].Name = to
+		}
+	}
+	for i := range td.Checks {
+		if td.Checks[i].Name == from {
+			td.Checks[i].Name = to
+		}
+	}
+	for i := range td.ForeignKeys {
+		if td.ForeignKeys[i].Name == from {
+			td.ForeignKeys[i].Name = to
+		}
+	}
+}
+
+func findColumn(td *fixture.TableDoc, name string) *fixture.ColumnDoc {
+	for i := range td.Columns {
+		if td.Columns[i].Name == name {
+			return &td.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ownerName formats a sequence owner the way regclass prints it: the schema
+// is omitted for objects on the default search path.
+func ownerName(schemaName, table, column string) string {
+	if schemaName == "public" {
+		return table + "." + column
+	}
+	return schemaName + "." + table + "." + column
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func replace(values []string, from, to string) {
+	for i := range values {
+		if values[i] == from {
+			values[i] = to
+		}
+	}
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,18 @@
+-- Initial schema
+CREATE TYPE plan AS ENUM ('free', 'pro');
+
+CREATE TABLE accounts (
+    id serial PRIMARY KEY,
+    name varchar(120) NOT NULL UNIQUE,
+    plan plan NOT NULL DEFAULT 'free',
+    legacy_flag boolean
+);
+
+CREATE TABLE members (
+    id bigserial PRIMARY KEY,
+    account_id integer NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    email text NOT NULL,
+    CONSTRAINT members_email_check CHECK (position('@' in email) > 1)
+);
+
+CREATE INDEX members_account_idx ON members (account_id);
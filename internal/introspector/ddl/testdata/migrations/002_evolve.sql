@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TYPE plan ADD VALUE 'enterprise' AFTER 'pro';
+
+ALTER TABLE accounts
+    ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+    DROP COLUMN legacy_flag;
+
+ALTER TABLE members RENAME COLUMN email TO login_email;
+ALTER TABLE members ALTER COLUMN login_email TYPE citext;
+
+CREATE UNIQUE INDEX IF NOT EXISTS members_login_email_key ON members (account_id, login_email);
+
+ALTER TABLE members ENABLE ROW LEVEL SECURITY;
+
+COMMENT ON TABLE accounts IS 'Billing accounts';
+
+DROP INDEX members_account_idx;
+
+COMMIT;
@@ -0,0 +1,195 @@
+--
+-- PostgreSQL database dump
+--
+
+\restrict abc123
+
+-- Dumped from database version 16.2 (Debian 16.2-1.pgdg120+2)
+-- Dumped by pg_dump version 16.2
+
+SET statement_timeout = 0;
+SET lock_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+
+--
+-- Name: billing; Type: SCHEMA; Schema: -; Owner: shop
+--
+
+CREATE SCHEMA billing;
+
+
+ALTER SCHEMA billing OWNER TO shop;
+
+--
+-- Name: pg_trgm; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+COMMENT ON EXTENSION pg_trgm IS 'text similarity measurement and index searching based on trigrams';
+
+--
+-- Name: order_status; Type: TYPE; Schema: public; Owner: shop
+--
+
+CREATE TYPE public.order_status AS ENUM (
+    'pending',
+    'paid',
+    'shipped'
+);
+
+
+ALTER TYPE public.order_status OWNER TO shop;
+
+CREATE TYPE public.address AS (
+	street text,
+	city character varying(100)
+);
+
+CREATE DOMAIN public.positive_cents AS integer
+	CONSTRAINT positive_cents_check CHECK ((VALUE >= 0));
+
+--
+-- Name: touch_updated_at(); Type: FUNCTION; Schema: public; Owner: shop
+--
+
+CREATE FUNCTION public.touch_updated_at() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+  NEW.updated_at := now();  -- keep; semicolons inside bodies are fine
+  RETURN NEW;
+END;
+$$;
+
+CREATE FUNCTION public.order_total(p_order_id bigint, include_tax boolean DEFAULT true) RETURNS numeric
+    LANGUAGE sql STABLE
+    AS $_$ SELECT sum(quantity * price) FROM public.order_items WHERE order_id = $1 $_$;
+
+COMMENT ON FUNCTION public.order_total(p_order_id bigint, include_tax boolean) IS 'Sum of line items';
+
+SET default_tablespace = '';
+
+SET default_table_access_method = heap;
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: shop
+--
+
+CREATE TABLE public.users (
+    id bigint NOT NULL,
+    email character varying(255) NOT NULL,
+    "displayName" text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone
+);
+
+
+ALTER TABLE public.users OWNER TO shop;
+
+COMMENT ON TABLE public.users IS 'Registered customers';
+
+COMMENT ON COLUMN public.users.email IS 'Login address, stored lower-case';
+
+CREATE SEQUENCE public.users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+
+CREATE TABLE public.orders (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    status public.order_status DEFAULT 'pending'::public.order_status NOT NULL,
+    total_cents public.positive_cents,
+    note text COLLATE pg_catalog."C",
+    CONSTRAINT orders_total_check CHECK ((total_cents < 10000000))
+);
+
+ALTER TABLE public.orders ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.orders_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+CREATE TABLE public.order_items (
+    order_id bigint NOT NULL,
+    sku text NOT NULL,
+    quantity integer DEFAULT 1 NOT NULL,
+    price numeric(10,2) NOT NULL
+);
+
+CREATE TABLE billing.invoices (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    order_id bigint,
+    issued_on date
+);
+
+CREATE VIEW public.open_orders AS
+ SELECT o.id,
+    o.user_id,
+    u.email
+   FROM (public.orders o
+     JOIN public.users u ON ((u.id = o.user_id)))
+  WHERE (o.status <> 'shipped'::public.order_status);
+
+COMMENT ON VIEW public.open_orders IS 'Orders not yet shipped';
+
+CREATE MATERIALIZED VIEW public.daily_totals AS
+ SELECT 1 AS n
+  WITH NO DATA;
+
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.order_items
+    ADD CONSTRAINT order_items_pkey PRIMARY KEY (order_id, sku);
+
+ALTER TABLE ONLY billing.invoices
+    ADD CONSTRAINT invoices_pkey PRIMARY KEY (id);
+
+CREATE INDEX orders_user_id_idx ON public.orders USING btree (user_id) WHERE (status <> 'shipped'::public.order_status);
+
+CREATE UNIQUE INDEX users_lower_email_idx ON public.users USING btree (lower((email)::text));
+
+CREATE INDEX users_email_trgm_idx ON public.users USING gin (email public.gin_trgm_ops);
+
+COMMENT ON INDEX public.users_email_trgm_idx IS 'Fuzzy email lookup';
+
+CREATE TRIGGER users_touch BEFORE UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION public.touch_updated_at();
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.order_items
+    ADD CONSTRAINT order_items_order_id_fkey FOREIGN KEY (order_id) REFERENCES public.orders(id) DEFERRABLE INITIALLY DEFERRED;
+
+ALTER TABLE ONLY billing.invoices
+    ADD CONSTRAINT invoices_order_id_fkey FOREIGN KEY (order_id) REFERENCES public.orders(id) NOT VALID;
+
+CREATE POLICY users_self ON public.users USING ((id = (current_setting('app.user_id'::text))::bigint));
+
+GRANT SELECT ON TABLE public.users TO reporting;
+
+--
+-- PostgreSQL database dump complete
+--
+
+\unrestrict abc123
@@ -0,0 +1,593 @@
+package fixture
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// buildNamespaces returns every in-scope schema that is declared or holds an
+// object, sorted by name, with object counts tallied from the document.
+func buildNamespaces(doc *Document, inScope func(string) bool, redactComments bool) []*schema.Namespace {
+	byName := make(map[string]*schema.Namespace)
+	get := func(name string) *schema.Namespace {
+		ns, ok := byName[name]
+		if !ok {
+			ns = &schema.Namespace{SchemaName: name}
+			byName[name] = ns
+		}
+		return ns
+	}
+
+	for _, s := range doc.Schemas {
+		ns := get(s.Name)
+		ns.Owner = s.Owner
+		if !redactComments {
+			ns.Comment = s.Comment
+		}
+	}
+
+	declaredSeqs := make(map[string]bool)
+	for _, s := range doc.Sequences {
+		get(s.Schema).ObjectCounts.Sequences++
+		declaredSeqs[s.Schema+"."+s.Name] = true
+	}
+	for _, t := range doc.Tables {
+		ns := get(t.Schema)
+		ns.ObjectCounts.Tables++
+		for _, c := range t.Columns {
+			// serial columns own an implicit sequence
+			if ResolveType(c.Type).Serial && !declaredSeqs[fmt.Sprintf("%s.%s_%s_seq", t.Schema, t.Name, c.Name)] {
+				ns.ObjectCounts.Sequences++
+			}
+		}
+	}
+	for _, v := range doc.Views {
+		get(v.Schema).ObjectCounts.Views++
+	}
+	for _, r := range doc.Routines {
+		if strings.EqualFold(r.Kind, "procedure") {
+			get(r.Schema).ObjectCounts.Procedures++
+		} else {
+			get(r.Schema).ObjectCounts.Functions++
+		}
+	}
+	for _, e := range doc.Enums {
+		get(e.Schema).ObjectCounts.Enums++
+	}
+	for _, t := range doc.Types {
+		get(t.Schema).ObjectCounts.Types++
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		if inScope(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	namespaces := make([]*schema.Namespace, len(names))
+	for i, name := range names {
+		namespaces[i] = byName[name]
+	}
+	return namespaces
+}
+
+// buildTable converts a table document into columns, constraints, indexes and
+// triggers shaped like the postgres introspector's output.
+func buildTable(td TableDoc, redactComments bool) *schema.Table {
+	table := &schema.Table{
+		TableName:        td.Name,
+		SchemaName:       td.Schema,
+		TableType:        "BASE TABLE",
+		RowCountEstimate: td.RowCount,
+		Persistence:      "permanent",
+	}
+	if !redactComments {
+		table.Comment = td.Comment
+	}
+
+	pk := make(map[string]bool, len(td.PrimaryKey))
+	for _, c := range td.PrimaryKey {
+		pk[c] = true
+	}
+
+	for n, cd := range td.Columns {
+		col := buildColumn(cd, td, pk[cd.Name])
+		col.OrdinalPosition = n + 1
+		if redactComments {
+			col.Comment = ""
+		}
+		table.Columns = append(table.Columns, col)
+	}
+
+	if len(td.PrimaryKey) > 0 {
+		name := td.PKName
+		if name == "" {
+			name = td.Name + "_pkey"
+		}
+		def := fmt.Sprintf("PRIMARY KEY (%s)", quoteIdents(td.PrimaryKey))
+		table.Constraints = append(table.Constraints, &schema.Constraint{
+			ConstraintName: name,
+			ConstraintType: "PRIMARY KEY",
+			Columns:        td.PrimaryKey,
+			Definition:     &def,
+		})
+		table.Indexes = append(table.Indexes, buildIndex(td, IndexDoc{Name: name, Columns: td.PrimaryKey, Unique: true}, true))
+	}
+
+	for _, u := range td.Unique {
+		name := u.Name
+		if name == "" {
+			name = fmt.Sprintf("%s_%s_key", td.Name, strings.Join(u.Columns, "_"))
+		}
+		def := fmt.Sprintf("UNIQUE (%s)", quoteIdents(u.Columns))
+		if u.NullsNotDistinct {
+			def = fmt.Sprintf("UNIQUE NULLS NOT DISTINCT (%s)", quoteIdents(u.Columns))
+		}
+		table.Constraints = append(table.Constraints, &schema.Constraint{
+			ConstraintName:   name,
+			ConstraintType:   "UNIQUE",
+			Columns:          u.Columns,
+			Definition:       &def,
+			NullsNotDistinct: u.NullsNotDistinct,
+		})
+		idx := buildIndex(td, IndexDoc{Name: name, Columns: u.Columns, Unique: true}, false)
+		idx.NullsNotDistinct = u.NullsNotDistinct
+		table.Indexes = append(table.Indexes, idx)
+	}
+
+	for n, c := range td.Checks {
+		name := c.Name
+		if name == "" {
+			name = td.Name + "_check"
+			if n > 0 {
+				name = fmt.Sprintf("%s_check%d", td.Name, n)
+			}
+		}
+		def := fmt.Sprintf("CHECK (%s)", c.Expression)
+		table.Constraints = append(table.Constraints, &schema.Constraint{
+			ConstraintName: name,
+			ConstraintType: "CHECK",
+			Expression:     &def,
+			Definition:     &def,
+		})
+	}
+
+	for _, id := range td.Indexes {
+		idx := buildIndex(td, id, false)
+		if !redactComments {
+			idx.Comment = id.Comment
+		}
+		table.Indexes = append(table.Indexes, idx)
+	}
+
+	for _, trd := range td.Triggers {
+		def := trd.Definition
+		if def == "" {
+			def = fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s.%s FOR EACH ROW EXECUTE FUNCTION %s()",
+				trd.Name, strings.ToUpper(trd.Timing), strings.ToUpper(strings.Join(trd.Events, " OR ")),
+				td.Schema, td.Name, trd.Function)
+		}
+		table.Triggers = append(table.Triggers, &schema.Trigger{
+			TriggerName:         trd.Name,
+			Timing:              strings.ToLower(trd.Timing),
+			Events:              lowerAll(trd.Events),
+			FunctionOrProcedure: trd.Function,
+			Definition:          &def,
+		})
+	}
+
+	// Match the live introspector's catalog ordering
+	sortConstraints(table.Constraints)
+	sort.SliceStable(table.Indexes, func(a, b int) bool {
+		return table.Indexes[a].IndexName < table.Indexes[b].IndexName
+	})
+	sort.SliceStable(table.Triggers, func(a, b int) bool {
+		return table.Triggers[a].TriggerName < table.Triggers[b].TriggerName
+	})
+
+	return table
+}
+
+// sortConstraints orders primary key, unique, then check constraints, each
+// by name, as pg_constraint is queried.
+func sortConstraints(constraints []*schema.Constraint) {
+	rank := map[string]int{"PRIMARY KEY": 1, "UNIQUE": 2, "CHECK": 3, "EXCLUSION": 4}
+	sort.SliceStable(constraints, func(a, b int) bool {
+		ra, rb := rank[constraints[a].ConstraintType], rank[constraints[b].ConstraintType]
+		if ra != rb {
+			return ra < rb
+		}
+		return constraints[a].ConstraintName < constraints[b].ConstraintName
+	})
+}
+
+// buildColumn resolves a column's type and nullability. Primary key, serial
+// and identity columns are always NOT NULL.
+func buildColumn(cd ColumnDoc, td TableDoc, inPK bool) *schema.Column {
+	info := ResolveType(cd.Type)
+
+	col := &schema.Column{
+		ColumnName:         cd.Name,
+		DataType:           info.DataType,
+		UDTName:            info.UDTName,
+		Nullable:           cd.Nullable == nil || *cd.Nullable,
+		DefaultValue:       cd.Default,
+		CharacterMaxLength: info.CharacterMaxLength,
+		NumericPrecision:   info.NumericPrecision,
+		NumericScale:       info.NumericScale,
+		TypeModifier:       -1,
+		Comment:            cd.Comment,
+	}
+
+	if info.Serial && col.DefaultValue == nil {
+		seq := fmt.Sprintf("%s_%s_seq", td.Name, cd.Name)
+		if td.Schema != defaultSchema {
+			seq = td.Schema + "." + seq
+		}
+		def := fmt.Sprintf("nextval('%s'::regclass)", seq)
+		col.DefaultValue = &def
+	}
+
+	if cd.Identity != "" {
+		col.IsIdentity = true
+		col.IdentityGeneration = strings.ToUpper(cd.Identity)
+	}
+
+	if cd.Generated != "" {
+		expr := cd.Generated
+		col.Generated = true
+		col.GenerationExpression = &expr
+	}
+
+	if cd.Collation != "" {
+		coll := cd.Collation
+		col.Collation = &coll
+	}
+
+	if inPK || info.Serial || col.IsIdentity {
+		col.Nullable = false
+	}
+
+	return col
+}
+
+// buildIndex synthesizes an index and its CREATE INDEX definition.
+func buildIndex(td TableDoc, id IndexDoc, primary bool) *schema.Index {
+	method := strings.ToLower(id.Method)
+	if method == "" {
+		method = "btree"
+	}
+
+	idx := &schema.Index{
+		IndexName: id.Name,
+		Unique:    id.Unique || primary,
+		Primary:   primary,
+		IndexType: method,
+		Columns:   id.Columns,
+	}
+	if len(id.Include) > 0 {
+		idx.IncludeColumns = id.Include
+	}
+
+	keys := id.Keys
+	if len(keys) == 0 {
+		if id.Expression != "" {
+			keys = []IndexKeyDoc{{Expression: id.Expression, OpClass: id.OpClass}}
+		} else {
+			for _, col := range id.Columns {
+				keys = append(keys, IndexKeyDoc{Column: col, OpClass: id.OpClass})
+			}
+		}
+	} else {
+		idx.Columns = nil
+		for _, k := range keys {
+			if k.Expression == "" {
+				idx.Columns = append(idx.Columns, k.Column)
+			}
+		}
+	}
+	// Like pg_index, an index on expressions only has no columns
+	if idx.Columns == nil {
+		idx.Columns = []string{}
+	}
+
+	var exprs []string
+	for _, k := range keys {
+		if k.Expression != "" {
+			exprs = append(exprs, k.Expression)
+		}
+	}
+	if len(exprs) > 0 {
+		expr := strings.Join(exprs, ", ")
+		idx.Expression = &expr
+	}
+
+	parts := make([]string, len(keys))
+	for n, k := range keys {
+		key := schema.IndexKey{Column: k.Column, OpClass: k.OpClass, Collation: k.Collation}
+		parts[n] = quoteIdent(k.Column)
+		if k.Expression != "" {
+			key.Column = k.Expression
+			parts[n] = k.Expression
+		}
+		if k.Collation != "" {
+			parts[n] += " COLLATE " + quoteIdent(k.Collation)
+		}
+		if k.OpClass != "" {
+			parts[n] += " " + k.OpClass
+		}
+
+		if method == "btree" {
+			key.SortOrder, key.NullsOrder = "ASC", "LAST"
+			if strings.EqualFold(k.Order, "desc") {
+				key.SortOrder, key.NullsOrder = "DESC", "FIRST"
+				parts[n] += " DESC"
+			}
+			if k.Nulls != "" && !strings.EqualFold(k.Nulls, key.NullsOrder) {
+				key.NullsOrder = strings.ToUpper(k.Nulls)
+				parts[n] += " NULLS " + key.NullsOrder
+			}
+		}
+		idx.Keys = append(idx.Keys, key)
+	}
+
+	var def strings.Builder
+	def.WriteString("CREATE ")
+	if idx.Unique {
+		def.WriteString("UNIQUE ")
+	}
+	fmt.Fprintf(&def, "INDEX %s ON %s.%s USING %s (%s)",
+		quoteIdent(id.Name), quoteIdent(td.Schema), quoteIdent(td.Name), method, strings.Join(parts, ", "))
+	if len(id.Include) > 0 {
+		fmt.Fprintf(&def, " INCLUDE (%s)", quoteIdents(id.Include))
+	}
+	if id.Where != "" {
+		pred := id.Where
+		idx.Predicate = &pred
+		fmt.Fprintf(&def, " WHERE (%s)", pred)
+	}
+	idx.Definition = def.String()
+	if id.Definition != "" {
+		idx.Definition = id.Definition
+	}
+
+	return idx
+}
+
+// buildForeignKeys resolves each foreign key against its target table and
+// derives nullability from the referencing columns.
+func buildForeignKeys(doc *Document, td TableDoc, table *schema.Table) []*schema.ForeignKey {
+	nullable := make(map[string]bool, len(table.Columns))
+	for _, c := range table.Columns {
+		nullable[c.ColumnName] = c.Nullable
+	}
+
+	var fks []*schema.ForeignKey
+	for _, fd := range td.ForeignKeys {
+		target, targetCols := parseReference(fd.References, td.Schema)
+		toSchema, toTable, _ := strings.Cut(target, ".")
+		if len(targetCols) == 0 {
+			targetCols = doc.table(toSchema, toTable).PrimaryKey
+		}
+
+		name := fd.Name
+		if name == "" {
+			name = fmt.Sprintf("%s_%s_fkey", td.Name, strings.Join(fd.Columns, "_"))
+		}
+
+		fk := &schema.ForeignKey{
+			ConstraintName:    name,
+			FromSchema:        td.Schema,
+			FromTable:         td.Name,
+			FromColumns:       fd.Columns,
+			ToSchema:          toSchema,
+			ToTable:           toTable,
+			ToColumns:         targetCols,
+			OnUpdate:          fkAction(fd.OnUpdate),
+			OnDelete:          fkAction(fd.OnDelete),
+			MatchType:         "SIMPLE",
+			Deferrable:        fd.Deferrable,
+			InitiallyDeferred: fd.Deferred,
+			NotValid:          fd.NotValid,
+		}
+		if fd.Match != "" {
+			fk.MatchType = strings.ToUpper(fd.Match)
+		}
+		for _, c := range fd.Columns {
+			if nullable[c] {
+				fk.Nullable = true
+				break
+			}
+		}
+		fks = append(fks, fk)
+	}
+
+	sort.SliceStable(fks, func(a, b int) bool {
+		return fks[a].ConstraintName < fks[b].ConstraintName
+	})
+	return fks
+}
+
+// sortObjects orders every object list by schema then name, as the postgres
+// catalog queries do, so document order does not leak into the output.
+func sortObjects(db *schema.Database) {
+	less := func(schemaA, nameA, schemaB, nameB string) bool {
+		if schemaA != schemaB {
+			return schemaA < schemaB
+		}
+		return nameA < nameB
+	}
+
+	sort.SliceStable(db.Tables, func(a, b int) bool {
+		return less(db.Tables[a].SchemaName, db.Tables[a].TableName, db.Tables[b].SchemaName, db.Tables[b].TableName)
+	})
+	sort.SliceStable(db.Views, func(a, b int) bool {
+		return less(db.Views[a].SchemaName, db.Views[a].ViewName, db.Views[b].SchemaName, db.Views[b].ViewName)
+	})
+	sort.SliceStable(db.Routines, func(a, b int) bool {
+		return less(db.Routines[a].SchemaName, db.Routines[a].RoutineName, db.Routines[b].SchemaName, db.Routines[b].RoutineName)
+	})
+	sort.SliceStable(db.Enums, func(a, b int) bool {
+		return less(db.Enums[a].SchemaName, db.Enums[a].EnumName, db.Enums[b].SchemaName, db.Enums[b].EnumName)
+	})
+	sort.SliceStable(db.Sequences, func(a, b int) bool {
+		return less(db.Sequences[a].SchemaName, db.Sequences[a].SequenceName, db.Sequences[b].SchemaName, db.Sequences[b].SequenceName)
+	})
+	sort.SliceStable(db.Types, func(a, b int) bool {
+		return less(db.Types[a].SchemaName, db.Types[a].TypeName, db.Types[b].SchemaName, db.Types[b].TypeName)
+	})
+}
+
+// buildIncomingForeignKeys mirrors each outgoing foreign key onto its target.
+func buildIncomingForeignKeys(tables []*schema.Table) {
+	tableMap := make(map[string]*schema.Table, len(tables))
+	for _, t := range tables {
+		tableMap[t.SchemaName+"."+t.TableName] = t
+	}
+
+	for _, t := range tables {
+		for _, fk := range t.OutgoingForeignKeys {
+			if target, ok := tableMap[fk.ToSchema+"."+fk.ToTable]; ok {
+				incoming := *fk
+				target.IncomingForeignKeys = append(target.IncomingForeignKeys, &incoming)
+			}
+		}
+	}
+}
+
+// applyParents links INHERITS parents and marks inherited columns.
+func applyParents(td TableDoc, table *schema.Table, tableMap map[string]*schema.Table) {
+	for _, p := range td.Inherits {
+		parent, ok := tableMap[qualify(p, td.Schema)]
+		if !ok {
+			continue
+		}
+		table.InheritsFrom = append(table.InheritsFrom, schema.TableRef{TableName: parent.TableName, SchemaName: parent.SchemaName})
+		parent.InheritedBy = append(parent.InheritedBy, schema.TableRef{TableName: table.TableName, SchemaName: table.SchemaName})
+
+		for _, pc := range parent.Columns {
+			for _, c := range table.Columns {
+				if c.ColumnName == pc.ColumnName {
+					c.Inherited = true
+				}
+			}
+		}
+	}
+}
+
+func buildView(doc *Document, vd ViewDoc, cfg *config.Config) *schema.View {
+	view := &schema.View{
+		ViewName:   vd.Name,
+		SchemaName: vd.Schema,
+	}
+	if !cfg.RedactDefinitions && vd.Definition != "" {
+		def := vd.Definition
+		view.Definition = &def
+	}
+	if !cfg.RedactComments {
+		view.Comment = vd.Comment
+	}
+
+	for n, cd := range vd.Columns {
+		col := buildColumn(cd, TableDoc{Name: vd.Name, Schema: vd.Schema}, false)
+		col.OrdinalPosition = n + 1
+		if cfg.RedactComments {
+			col.Comment = ""
+		}
+		view.Columns = append(view.Columns, col)
+	}
+
+	// Dependencies are reported unqualified for public, like pg_depend lookups
+	for _, dep := range vd.DependsOn {
+		key := qualify(dep, vd.Schema)
+		name := strings.TrimPrefix(key, defaultSchema+".")
+		depSchema, depName, _ := strings.Cut(key, ".")
+		if doc.table(depSchema, depName) != nil {
+			view.DependsOnTables = append(view.DependsOnTables, name)
+		} else {
+			view.DependsOnViews = append(view.DependsOnViews, name)
+		}
+	}
+
+	return view
+}
+
+func buildRoutine(rd RoutineDoc, redactDefinitions bool) *schema.Routine {
+	kind := strings.ToLower(rd.Kind)
+	if kind == "" {
+		kind = "function"
+	}
+
+	routine := &schema.Routine{
+		RoutineName: rd.Name,
+		SchemaName:  rd.Schema,
+		RoutineType: kind,
+		Language:    rd.Language,
+		ReturnType:  rd.Returns,
+		Comment:     rd.Comment,
+	}
+	if !redactDefinitions && rd.Definition != "" {
+		def := rd.Definition
+		routine.Definition = &def
+	}
+	for _, a := range rd.Arguments {
+		routine.Arguments = append(routine.Arguments, schema.Argument{
+			Name:     a.Name,
+			DataType: a.Type,
+			Mode:     strings.ToUpper(a.Mode),
+			Default:  a.Default,
+		})
+	}
+
+	return routine
+}
+
+func buildType(td TypeDoc) *schema.Type {
+	t := &schema.Type{
+		TypeName:   td.Name,
+		SchemaName: td.Schema,
+		TypeKind:   strings.ToLower(td.Kind),
+		BaseType:   td.BaseType,
+		Comment:    td.Comment,
+	}
+	if td.Check != "" {
+		check := td.Check
+		t.Constraint = &check
+	}
+	for _, a := range td.Attributes {
+		t.Attributes = append(t.Attributes, schema.TypeAttribute{Name: a.Name, DataType: a.Type})
+	}
+	return t
+}
+
+// table looks up a table document by schema and name.
+func (d *Document) table(schemaName, name string) *TableDoc {
+	for i := range d.Tables {
+		if d.Tables[i].Schema == schemaName && d.Tables[i].Name == name {
+			return &d.Tables[i]
+		}
+	}
+	return nil
+}
+
+// fkAction normalizes a referential action, defaulting to NO ACTION.
+func fkAction(action string) string {
+	if action == "" {
+		return "NO ACTION"
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(action), " "))
+}
+
+func lowerAll(values []string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strings.ToLower(v)
+	}
+	return result
+}
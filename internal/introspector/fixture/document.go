@@ -0,0 +1,192 @@
+package fixture
+
+// Document is the declarative fixture file layout. Fields use snake_case keys
+// in both YAML and JSON; objects without a schema default to "public".
+type Document struct {
+	Database string `yaml:"database" json:"database"`
+	Engine   string `yaml:"engine,omitempty" json:"engine,omitempty"`
+	Version  string `yaml:"version,omitempty" json:"version,omitempty"`
+
+	Schemas   []SchemaDoc   `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	Tables    []TableDoc    `yaml:"tables,omitempty" json:"tables,omitempty"`
+	Views     []ViewDoc     `yaml:"views,omitempty" json:"views,omitempty"`
+	Routines  []RoutineDoc  `yaml:"routines,omitempty" json:"routines,omitempty"`
+	Enums     []EnumDoc     `yaml:"enums,omitempty" json:"enums,omitempty"`
+	Sequences []SequenceDoc `yaml:"sequences,omitempty" json:"sequences,omitempty"`
+	Types     []TypeDoc     `yaml:"types,omitempty" json:"types,omitempty"`
+}
+
+// SchemaDoc describes a namespace.
+type SchemaDoc struct {
+	Name    string `yaml:"name" json:"name"`
+	Owner   string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Comment string `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// TableDoc describes a base table and everything attached to it.
+type TableDoc struct {
+	Name     string `yaml:"name" json:"name"`
+	Schema   string `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Comment  string `yaml:"comment,omitempty" json:"comment,omitempty"`
+	RowCount *int64 `yaml:"row_count,omitempty" json:"row_count,omitempty"`
+
+	Columns     []ColumnDoc     `yaml:"columns" json:"columns"`
+	PrimaryKey  []string        `yaml:"primary_key,omitempty" json:"primary_key,omitempty"`
+	PKName      string          `yaml:"primary_key_name,omitempty" json:"primary_key_name,omitempty"`
+	Unique      []UniqueDoc     `yaml:"unique,omitempty" json:"unique,omitempty"`
+	Checks      []CheckDoc      `yaml:"checks,omitempty" json:"checks,omitempty"`
+	Indexes     []IndexDoc      `yaml:"indexes,omitempty" json:"indexes,omitempty"`
+	ForeignKeys []ForeignKeyDoc `yaml:"foreign_keys,omitempty" json:"foreign_keys,omitempty"`
+	Triggers    []TriggerDoc    `yaml:"triggers,omitempty" json:"triggers,omitempty"`
+	Inherits    []string        `yaml:"inherits,omitempty" json:"inherits,omitempty"`
+}
+
+// ColumnDoc describes a column. Type accepts SQL spellings such as
+// "varchar(255)", "numeric(10,2)", "timestamptz", "text[]" or "bigserial".
+type ColumnDoc struct {
+	Name      string  `yaml:"name" json:"name"`
+	Type      string  `yaml:"type" json:"type"`
+	Nullable  *bool   `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	Default   *string `yaml:"default,omitempty" json:"default,omitempty"`
+	Identity  string  `yaml:"identity,omitempty" json:"identity,omitempty"`
+	Generated string  `yaml:"generated,omitempty" json:"generated,omitempty"`
+	Collation string  `yaml:"collation,omitempty" json:"collation,omitempty"`
+	Comment   string  `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// UniqueDoc describes a UNIQUE constraint.
+type UniqueDoc struct {
+	Name             string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Columns          []string `yaml:"columns" json:"columns"`
+	NullsNotDistinct bool     `yaml:"nulls_not_distinct,omitempty" json:"nulls_not_distinct,omitempty"`
+}
+
+// CheckDoc describes a CHECK constraint.
+type CheckDoc struct {
+	Name       string `yaml:"name,omitempty" json:"name,omitempty"`
+	Expression string `yaml:"expression" json:"expression"`
+}
+
+// IndexDoc describes a secondary index.
+type IndexDoc struct {
+	Name       string   `yaml:"name" json:"name"`
+	Columns    []string `yaml:"columns,omitempty" json:"columns,omitempty"`
+	Expression string   `yaml:"expression,omitempty" json:"expression,omitempty"`
+	Include    []string `yaml:"include,omitempty" json:"include,omitempty"`
+	Unique     bool     `yaml:"unique,omitempty" json:"unique,omitempty"`
+	Method     string   `yaml:"method,omitempty" json:"method,omitempty"`
+	OpClass    string   `yaml:"opclass,omitempty" json:"opclass,omitempty"`
+	Where      string   `yaml:"where,omitempty" json:"where,omitempty"`
+	Comment    string   `yaml:"comment,omitempty" json:"comment,omitempty"`
+
+	// Keys lists the index keys in order with their ordering; when set it
+	// describes the keys instead of Columns, Expression and OpClass
+	Keys []IndexKeyDoc `yaml:"keys,omitempty" json:"keys,omitempty"`
+
+	// Definition overrides the synthesized CREATE INDEX statement
+	Definition string `yaml:"definition,omitempty" json:"definition,omitempty"`
+}
+
+// IndexKeyDoc describes one index key: a column or an expression.
+type IndexKeyDoc struct {
+	Column     string `yaml:"column,omitempty" json:"column,omitempty"`
+	Expression string `yaml:"expression,omitempty" json:"expression,omitempty"`
+	Order      string `yaml:"order,omitempty" json:"order,omitempty"` // asc or desc
+	Nulls      string `yaml:"nulls,omitempty" json:"nulls,omitempty"` // first or last
+	OpClass    string `yaml:"opclass,omitempty" json:"opclass,omitempty"`
+	Collation  string `yaml:"collation,omitempty" json:"collation,omitempty"`
+}
+
+// ForeignKeyDoc describes a foreign key. References is "table(col, ...)" or
+// "schema.table(col, ...)"; the column list defaults to the target's primary key.
+type ForeignKeyDoc struct {
+	Name       string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Columns    []string `yaml:"columns" json:"columns"`
+	References string   `yaml:"references" json:"references"`
+	OnDelete   string   `yaml:"on_delete,omitempty" json:"on_delete,omitempty"`
+	OnUpdate   string   `yaml:"on_update,omitempty" json:"on_update,omitempty"`
+	Match      string   `yaml:"match,omitempty" json:"match,omitempty"`
+	Deferrable bool     `yaml:"deferrable,omitempty" json:"deferrable,omitempty"`
+	Deferred   bool     `yaml:"initially_deferred,omitempty" json:"initially_deferred,omitempty"`
+	NotValid   bool     `yaml:"not_valid,omitempty" json:"not_valid,omitempty"`
+}
+
+// TriggerDoc describes a trigger.
+type TriggerDoc struct {
+	Name     string   `yaml:"name" json:"name"`
+	Timing   string   `yaml:"timing" json:"timing"`
+	Events   []string `yaml:"events" json:"events"`
+	Function string   `yaml:"function" json:"function"`
+
+	// Definition overrides the synthesized CREATE TRIGGER statement
+	Definition string `yaml:"definition,omitempty" json:"definition,omitempty"`
+}
+
+// ViewDoc describes a view.
+type ViewDoc struct {
+	Name       string      `yaml:"name" json:"name"`
+	Schema     string      `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Definition string      `yaml:"definition,omitempty" json:"definition,omitempty"`
+	Comment    string      `yaml:"comment,omitempty" json:"comment,omitempty"`
+	Columns    []ColumnDoc `yaml:"columns,omitempty" json:"columns,omitempty"`
+	DependsOn  []string    `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+}
+
+// RoutineDoc describes a function or procedure.
+type RoutineDoc struct {
+	Name       string        `yaml:"name" json:"name"`
+	Schema     string        `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Kind       string        `yaml:"kind,omitempty" json:"kind,omitempty"`
+	Language   string        `yaml:"language,omitempty" json:"language,omitempty"`
+	Arguments  []ArgumentDoc `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+	Returns    string        `yaml:"returns,omitempty" json:"returns,omitempty"`
+	Definition string        `yaml:"definition,omitempty" json:"definition,omitempty"`
+	Comment    string        `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// ArgumentDoc describes a routine argument.
+type ArgumentDoc struct {
+	Name    string  `yaml:"name,omitempty" json:"name,omitempty"`
+	Type    string  `yaml:"type" json:"type"`
+	Mode    string  `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Default *string `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// EnumDoc describes an enum type.
+type EnumDoc struct {
+	Name    string   `yaml:"name" json:"name"`
+	Schema  string   `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Values  []string `yaml:"values" json:"values"`
+	Comment string   `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// SequenceDoc describes a standalone or owned sequence.
+type SequenceDoc struct {
+	Name      string `yaml:"name" json:"name"`
+	Schema    string `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Type      string `yaml:"type,omitempty" json:"type,omitempty"`
+	Start     *int64 `yaml:"start,omitempty" json:"start,omitempty"`
+	Increment *int64 `yaml:"increment,omitempty" json:"increment,omitempty"`
+	Min       *int64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max       *int64 `yaml:"max,omitempty" json:"max,omitempty"`
+	Cycle     bool   `yaml:"cycle,omitempty" json:"cycle,omitempty"`
+	OwnedBy   string `yaml:"owned_by,omitempty" json:"owned_by,omitempty"`
+	Comment   string `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// TypeDoc describes a composite or domain type.
+type TypeDoc struct {
+	Name       string         `yaml:"name" json:"name"`
+	Schema     string         `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Kind       string         `yaml:"kind" json:"kind"`
+	Attributes []AttributeDoc `yaml:"attributes,omitempty" json:"attributes,omitempty"`
+	BaseType   string         `yaml:"base_type,omitempty" json:"base_type,omitempty"`
+	Check      string         `yaml:"check,omitempty" json:"check,omitempty"`
+	Comment    string         `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// AttributeDoc describes a composite type attribute.
+type AttributeDoc struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+}
@@ -0,0 +1,320 @@
+// Package fixture implements an introspector that reads a declarative YAML or
+// JSON description of a database instead of connecting to one.
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultSchema = "public"
+
+// Introspector builds a schema.Database from a fixture document.
+type Introspector struct {
+	doc *Document
+}
+
+// New loads a fixture file. YAML and JSON are both accepted.
+func New(path string) (*Introspector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+
+	return FromDocument(doc)
+}
+
+// FromDocument creates an introspector over an already-built document.
+func FromDocument(doc *Document) (*Introspector, error) {
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return &Introspector{doc: doc}, nil
+}
+
+// Parse decodes a YAML or JSON fixture document. Unknown keys are rejected so
+// typos surface instead of silently dropping metadata.
+func Parse(data []byte) (*Document, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	doc.applyDefaults()
+	return &doc, nil
+}
+
+// DatabaseName returns the fixture's database name.
+func (i *Introspector) DatabaseName() string {
+	return i.doc.Database
+}
+
+// Version returns the fixture's declared server version.
+func (i *Introspector) Version() string {
+	return i.doc.Version
+}
+
+// Close is a no-op; fixtures hold no connections.
+func (i *Introspector) Close() error {
+	return nil
+}
+
+// Introspect builds the database model, applying the same schema scoping and
+// redaction the postgres introspector applies.
+func (i *Introspector) Introspect(ctx context.Context, cfg *config.Config) (*schema.Database, error) {
+	doc := i.doc
+	inScope := schemaFilter(cfg.Schemas)
+
+	db := &schema.Database{
+		Name:    doc.Database,
+		Engine:  doc.Engine,
+		Version: doc.Version,
+		Schemas: cfg.Schemas,
+	}
+
+	db.Namespaces = buildNamespaces(doc, inScope, cfg.RedactComments)
+
+	tableMap := make(map[string]*schema.Table)
+	for _, td := range doc.Tables {
+		if !inScope(td.Schema) {
+			continue
+		}
+		table := buildTable(td, cfg.RedactComments)
+		tableMap[td.Schema+"."+td.Name] = table
+		db.Tables = append(db.Tables, table)
+	}
+
+	for _, td := range doc.Tables {
+		table, ok := tableMap[td.Schema+"."+td.Name]
+		if !ok {
+			continue
+		}
+		table.OutgoingForeignKeys = buildForeignKeys(doc, td, table)
+		applyParents(td, table, tableMap)
+	}
+	buildIncomingForeignKeys(db.Tables)
+
+	if cfg.IncludeViews {
+		for _, vd := range doc.Views {
+			if inScope(vd.Schema) {
+				db.Views = append(db.Views, buildView(doc, vd, cfg))
+			}
+		}
+	}
+
+	if cfg.IncludeRoutines {
+		for _, rd := range doc.Routines {
+			if inScope(rd.Schema) {
+				db.Routines = append(db.Routines, buildRoutine(rd, cfg.RedactDefinitions))
+			}
+		}
+	}
+
+	for _, ed := range doc.Enums {
+		if inScope(ed.Schema) {
+			db.Enums = append(db.Enums, &schema.Enum{
+				EnumName:   ed.Name,
+				SchemaName: ed.Schema,
+				Values:     ed.Values,
+				Comment:    ed.Comment,
+			})
+		}
+	}
+
+	for _, sd := range doc.Sequences {
+		if inScope(sd.Schema) {
+			db.Sequences = append(db.Sequences, &schema.Sequence{
+				SequenceName: sd.Name,
+				SchemaName:   sd.Schema,
+				DataType:     sd.Type,
+				StartValue:   sd.Start,
+				MinValue:     sd.Min,
+				MaxValue:     sd.Max,
+				Increment:    sd.Increment,
+				CycleOption:  sd.Cycle,
+				OwnedBy:      sd.OwnedBy,
+				Comment:      sd.Comment,
+			})
+		}
+	}
+
+	for _, td := range doc.Types {
+		if inScope(td.Schema) {
+			db.Types = append(db.Types, buildType(td))
+		}
+	}
+
+	sortObjects(db)
+	return db, nil
+}
+
+// applyDefaults fills the schema name and engine the way postgres would report
+// them when a fixture leaves them out.
+func (d *Document) applyDefaults() {
+	if d.Engine == "" {
+		d.Engine = "postgresql"
+	}
+	for i := range d.Tables {
+		if d.Tables[i].Schema == "" {
+			d.Tables[i].Schema = defaultSchema
+		}
+	}
+	for i := range d.Views {
+		if d.Views[i].Schema == "" {
+			d.Views[i].Schema = defaultSchema
+		}
+	}
+	for i := range d.Routines {
+		if d.Routines[i].Schema == "" {
+			d.Routines[i].Schema = defaultSchema
+		}
+	}
+	for i := range d.Enums {
+		if d.Enums[i].Schema == "" {
+			d.Enums[i].Schema = defaultSchema
+		}
+	}
+	for i := range d.Sequences {
+		if d.Sequences[i].Schema == "" {
+			d.Sequences[i].Schema = defaultSchema
+		}
+	}
+	for i := range d.Types {
+		if d.Types[i].Schema == "" {
+			d.Types[i].Schema = defaultSchema
+		}
+	}
+}
+
+// Validate checks that names are unique and that every column, key and
+// reference points at something declared in the document.
+func (d *Document) Validate() error {
+	if d.Database == "" {
+		return fmt.Errorf("fixture: database name is required")
+	}
+
+	tables := make(map[string]*TableDoc)
+	for i := range d.Tables {
+		td := &d.Tables[i]
+		if td.Name == "" {
+			return fmt.Errorf("fixture: table %d has no name", i)
+		}
+		key := td.Schema + "." + td.Name
+		if _, dup := tables[key]; dup {
+			return fmt.Errorf("fixture: duplicate table %s", key)
+		}
+		tables[key] = td
+	}
+
+	for _, td := range d.Tables {
+		key := td.Schema + "." + td.Name
+		cols := make(map[string]bool)
+		for _, c := range td.Columns {
+			if c.Name == "" || c.Type == "" {
+				return fmt.Errorf("fixture: table %s has a column without name or type", key)
+			}
+			if cols[c.Name] {
+				return fmt.Errorf("fixture: table %s: duplicate column %s", key, c.Name)
+			}
+			cols[c.Name] = true
+		}
+
+		check := func(what string, names []string) error {
+			for _, n := range names {
+				if !cols[n] {
+					return fmt.Errorf("fixture: table %s: %s references unknown column %s", key, what, n)
+				}
+			}
+			return nil
+		}
+
+		if err := check("primary key", td.PrimaryKey); err != nil {
+			return err
+		}
+		for _, u := range td.Unique {
+			if err := check("unique constraint", u.Columns); err != nil {
+				return err
+			}
+		}
+		for _, idx := range td.Indexes {
+			if err := check("index "+idx.Name, append(append([]string{}, idx.Columns...), idx.Include...)); err != nil {
+				return err
+			}
+		}
+		for _, fk := range td.ForeignKeys {
+			if err := check("foreign key", fk.Columns); err != nil {
+				return err
+			}
+			target, targetCols := parseReference(fk.References, td.Schema)
+			targetDoc, ok := tables[target]
+			if !ok {
+				return fmt.Errorf("fixture: table %s: foreign key references unknown table %s", key, target)
+			}
+			if len(targetCols) == 0 {
+				targetCols = targetDoc.PrimaryKey
+			}
+			if len(targetCols) != len(fk.Columns) {
+				return fmt.Errorf("fixture: table %s: foreign key on (%s) does not match %s columns", key, strings.Join(fk.Columns, ", "), fk.References)
+			}
+		}
+		for _, parent := range td.Inherits {
+			if _, ok := tables[qualify(parent, td.Schema)]; !ok {
+				return fmt.Errorf("fixture: table %s inherits unknown table %s", key, parent)
+			}
+		}
+	}
+
+	return nil
+}
+
+// schemaFilter reports whether a schema is included by the configured list.
+func schemaFilter(schemas []string) func(string) bool {
+	if len(schemas) == 0 {
+		return func(string) bool { return true }
+	}
+	set := make(map[string]bool, len(schemas))
+	for _, s := range schemas {
+		set[s] = true
+	}
+	return func(s string) bool { return set[s] }
+}
+
+// parseReference splits "schema.table(a, b)" into a qualified table key and
+// its column list.
+func parseReference(ref, defaultSchemaName string) (string, []string) {
+	ref = strings.TrimSpace(ref)
+	var cols []string
+	if open := strings.Index(ref, "("); open >= 0 {
+		inner := strings.TrimSuffix(strings.TrimSpace(ref[open+1:]), ")")
+		for _, c := range strings.Split(inner, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				cols = append(cols, c)
+			}
+		}
+		ref = strings.TrimSpace(ref[:open])
+	}
+	return qualify(ref, defaultSchemaName), cols
+}
+
+// qualify prefixes a bare table name with the given schema.
+func qualify(name, schemaName string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return schemaName + "." + name
+}
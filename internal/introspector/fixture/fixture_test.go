@@ -0,0 +1,195 @@
+package fixture
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+)
+
+const shopYAML = `
+database: shop
+version: "16.2"
+schemas:
+  - name: public
+    comment: Storefront
+tables:
+  - name: users
+    comment: Registered users
+    columns:
+      - {name: id, type: bigserial}
+      - {name: email, type: varchar(255), nullable: false}
+      - {name: nickname, type: text}
+    primary_key: [id]
+    unique:
+      - columns: [email]
+  - name: orders
+    row_count: 1200
+    columns:
+      - {name: id, type: bigint, identity: always}
+      - {name: user_id, type: bigint}
+      - {name: total, type: "numeric(10,2)", nullable: false}
+      - {name: status, type: order_status, default: "'pending'::order_status"}
+    primary_key: [id]
+    checks:
+      - expression: total >= 0
+    indexes:
+      - {name: orders_user_id_idx, columns: [user_id], where: user_id IS NOT NULL}
+    foreign_keys:
+      - columns: [user_id]
+        references: users
+        on_delete: cascade
+enums:
+  - name: order_status
+    values: [pending, paid]
+views:
+  - name: big_orders
+    definition: SELECT * FROM orders WHERE total > 100
+    depends_on: [orders]
+`
+
+func TestIntrospect(t *testing.T) {
+	doc, err := Parse([]byte(shopYAML))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	intro, err := FromDocument(doc)
+	if err != nil {
+		t.Fatalf("FromDocument() error = %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.IncludeViews = true
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	if db.Name != "shop" || db.Engine != "postgresql" || db.Version != "16.2" {
+		t.Errorf("metadata = %q %q %q", db.Name, db.Engine, db.Version)
+	}
+	if len(db.Tables) != 2 || len(db.Enums) != 1 || len(db.Views) != 1 {
+		t.Fatalf("got %d tables, %d enums, %d views", len(db.Tables), len(db.Enums), len(db.Views))
+	}
+
+	// Tables are ordered by schema and name, as pg_class is queried
+	orders, users := db.Tables[0], db.Tables[1]
+
+	id := users.Columns[0]
+	if id.Nullable || id.DataType != "bigint" || id.DefaultValue == nil || *id.DefaultValue != "nextval('users_id_seq'::regclass)" {
+		t.Errorf("users.id = %+v", id)
+	}
+	if email := users.Columns[1]; email.CharacterMaxLength == nil || *email.CharacterMaxLength != 255 {
+		t.Errorf("users.email = %+v", email)
+	}
+	if !users.Columns[2].Nullable {
+		t.Error("users.nickname should default to nullable")
+	}
+	// Indexes are ordered by name, as pg_index is queried
+	if len(users.Indexes) != 2 || users.Indexes[0].IndexName != "users_email_key" || !users.Indexes[1].Primary {
+		t.Errorf("users indexes = %+v", users.Indexes)
+	}
+
+	if len(orders.OutgoingForeignKeys) != 1 {
+		t.Fatalf("orders foreign keys = %d, want 1", len(orders.OutgoingForeignKeys))
+	}
+	fk := orders.OutgoingForeignKeys[0]
+	if fk.ConstraintName != "orders_user_id_fkey" || fk.OnDelete != "CASCADE" || fk.OnUpdate != "NO ACTION" ||
+		!fk.Nullable || strings.Join(fk.ToColumns, ",") != "id" {
+		t.Errorf("orders foreign key = %+v", fk)
+	}
+	if len(users.IncomingForeignKeys) != 1 || users.IncomingForeignKeys[0].FromTable != "orders" {
+		t.Errorf("users incoming = %+v", users.IncomingForeignKeys)
+	}
+
+	if status := orders.Columns[3]; status.DataType != "USER-DEFINED" || status.UDTName != "order_status" {
+		t.Errorf("orders.status = %+v", status)
+	}
+	if orders.Constraints[1].ConstraintName != "orders_check" {
+		t.Errorf("check constraint = %+v", orders.Constraints[1])
+	}
+	if idx := orders.Indexes[1]; idx.Definition != "CREATE INDEX orders_user_id_idx ON public.orders USING btree (user_id) WHERE (user_id IS NOT NULL)" {
+		t.Errorf("index definition = %q", idx.Definition)
+	}
+	if deps := db.Views[0].DependsOnTables; len(deps) != 1 || deps[0] != "orders" {
+		t.Errorf("view dependencies = %v", deps)
+	}
+}
+
+func TestIntrospect_ScopeAndRedaction(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"database": "app",
+		"tables": [
+			{"name": "a", "comment": "kept?", "columns": [{"name": "id", "type": "int"}]},
+			{"name": "b", "schema": "audit", "columns": [{"name": "id", "type": "int"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	intro, err := FromDocument(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.RedactComments = true
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.Tables) != 1 || db.Tables[0].TableName != "a" {
+		t.Fatalf("tables = %+v, want only public.a", db.Tables)
+	}
+	if db.Tables[0].Comment != "" {
+		t.Errorf("comment = %q, want redacted", db.Tables[0].Comment)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "unknown key",
+			input:   "database: x\ntables:\n  - name: t\n    colums: []\n",
+			wantErr: "field colums not found",
+		},
+		{
+			name:    "missing database",
+			input:   "tables: []\n",
+			wantErr: "database name is required",
+		},
+		{
+			name:    "unknown primary key column",
+			input:   "database: x\ntables:\n  - name: t\n    columns: [{name: id, type: int}]\n    primary_key: [uid]\n",
+			wantErr: "primary key references unknown column uid",
+		},
+		{
+			name:    "unknown reference",
+			input:   "database: x\ntables:\n  - name: t\n    columns: [{name: u, type: int}]\n    foreign_keys: [{columns: [u], references: users}]\n",
+			wantErr: "references unknown table public.users",
+		},
+		{
+			name:    "duplicate table",
+			input:   "database: x\ntables:\n  - {name: t, columns: []}\n  - {name: t, schema: public, columns: []}\n",
+			wantErr: "duplicate table public.t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.input))
+			if err == nil {
+				_, err = FromDocument(doc)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package fixture
+
+import (
+	"regexp"
+	"strings"
+)
+
+var plainIdentRe = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// quoteIdent quotes an identifier the way PostgreSQL's quote_ident does:
+// only when it is not a plain lower-case name or is a reserved keyword.
+func quoteIdent(name string) string {
+	if plainIdentRe.MatchString(name) && !reservedKeywords[name] {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteIdents quotes and joins a column list.
+func quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for n, name := range names {
+		quoted[n] = quoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// reservedKeywords are the keywords quote_ident quotes: every category but
+// the unreserved keywords.
+var reservedKeywords = map[string]bool{
+	// Reserved
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true, "as": true,
+	"asc": true, "asymmetric": true, "both": true, "case": true, "cast": true, "check": true,
+	"collate": true, "column": true, "constraint": true, "create": true, "current_catalog": true,
+	"current_date": true, "current_role": true, "current_time": true, "current_timestamp": true,
+	"current_user": true, "default": true, "deferrable": true, "desc": true, "distinct": true,
+	"do": true, "else": true, "end": true, "except": true, "false": true, "fetch": true, "for": true,
+	"foreign": true, "from": true, "grant": true, "group": true, "having": true, "in": true,
+	"initially": true, "intersect": true, "into": true, "lateral": true, "leading": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "not": true, "null": true,
+	"offset": true, "on": true, "only": true, "or": true, "order": true, "placing": true,
+	"primary": true, "references": true, "returning": true, "select": true, "session_user": true,
+	"some": true, "symmetric": true, "system_user": true, "table": true, "then": true, "to": true,
+	"trailing": true, "true": true, "union": true, "unique": true, "user": true, "using": true,
+	"variadic": true, "when": true, "where": true, "window": true, "with": true,
+
+	// Reserved, can be function or type
+	"authorization": true, "binary": true, "collation": true, "concurrently": true, "cross": true,
+	"current_schema": true, "freeze": true, "full": true, "ilike": true, "inner": true, "is": true,
+	"isnull": true, "join": true, "left": true, "like": true, "natural": true, "notnull": true,
+	"outer": true, "overlaps": true, "right": true, "similar": true, "tablesample": true,
+	"verbose": true,
+
+	// Non-reserved, cannot be function or type
+	"between": true, "bigint": true, "bit": true, "boolean": true, "char": true, "character": true,
+	"coalesce": true, "dec": true, "decimal": true, "exists": true, "extract": true, "float": true,
+	"greatest": true, "grouping": true, "inout": true, "int": true, "integer": true,
+	"interval": true, "json": true, "json_array": true, "json_arrayagg": true, "json_exists": true,
+	"json_object": true, "json_objectagg": true, "json_query": true, "json_scalar": true,
+	"json_serialize": true, "json_table": true, "json_value": true, "least": true,
+	"merge_action": true, "national": true, "nchar": true, "none": true, "normalize": true,
+	"nullif": true, "numeric": true, "out": true, "overlay": true, "position": true,
+	"precision": true, "real": true, "row": true, "setof": true, "smallint": true,
+	"substring": true, "time": true, "timestamp": true, "treat": true, "trim": true,
+	"values": true, "varchar": true, "xmlattributes": true, "xmlconcat": true, "xmlelement": true,
+	"xmlexists": true, "xmlforest": true, "xmlnamespaces": true, "xmlparse": true, "xmlpi": true,
+	"xmlroot": true, "xmlserialize": true, "xmltable": true,
+}
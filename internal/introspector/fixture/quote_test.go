@@ -0,0 +1,27 @@
+package fixture
+
+import "testing"
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"email", "email"},
+		{"_col$1", "_col$1"},
+		{"Order", `"Order"`},
+		{"user", `"user"`},
+		{"name", "name"},
+		{"1st", `"1st"`},
+		{"has space", `"has space"`},
+		{`say "hi"`, `"say ""hi"""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := quoteIdent(tt.input); got != tt.expected {
+				t.Errorf("quoteIdent(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
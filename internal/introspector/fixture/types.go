@@ -0,0 +1,187 @@
+package fixture
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TypeInfo is a SQL type spelling resolved to the information_schema
+// representation the postgres introspector produces.
+type TypeInfo struct {
+	DataType           string
+	UDTName            string
+	CharacterMaxLength *int
+	NumericPrecision   *int
+	NumericScale       *int
+
+	// Serial is true for serial/bigserial/smallserial pseudo-types, which
+	// imply a NOT NULL column with a nextval() default.
+	Serial bool
+}
+
+type builtinType struct {
+	dataType  string
+	udtName   string
+	precision int
+}
+
+// builtinTypes maps accepted spellings to their information_schema names.
+var builtinTypes = map[string]builtinType{
+	"smallint":                    {"smallint", "int2", 16},
+	"int2":                        {"smallint", "int2", 16},
+	"integer":                     {"integer", "int4", 32},
+	"int":                         {"integer", "int4", 32},
+	"int4":                        {"integer", "int4", 32},
+	"bigint":                      {"bigint", "int8", 64},
+	"int8":                        {"bigint", "int8", 64},
+	"real":                        {"real", "float4", 24},
+	"float4":                      {"real", "float4", 24},
+	"double precision":            {"double precision", "float8", 53},
+	"float8":                      {"double precision", "float8", 53},
+	"float":                       {"double precision", "float8", 53},
+	"numeric":                     {"numeric", "numeric", 0},
+	"decimal":                     {"numeric", "numeric", 0},
+	"money":                       {"money", "money", 0},
+	"boolean":                     {"boolean", "bool", 0},
+	"bool":                        {"boolean", "bool", 0},
+	"text":                        {"text", "text", 0},
+	"character varying":           {"character varying", "varchar", 0},
+	"varchar":                     {"character varying", "varchar", 0},
+	"character":                   {"character", "bpchar", 0},
+	"char":                        {"character", "bpchar", 0},
+	"bpchar":                      {"character", "bpchar", 0},
+	"citext":                      {"USER-DEFINED", "citext", 0},
+	"bytea":                       {"bytea", "bytea", 0},
+	"uuid":                        {"uuid", "uuid", 0},
+	"json":                        {"json", "json", 0},
+	"jsonb":                       {"jsonb", "jsonb", 0},
+	"xml":                         {"xml", "xml", 0},
+	"date":                        {"date", "date", 0},
+	"time":                        {"time without time zone", "time", 0},
+	"time without time zone":      {"time without time zone", "time", 0},
+	"timetz":                      {"time with time zone", "timetz", 0},
+	"time with time zone":         {"time with time zone", "timetz", 0},
+	"timestamp":                   {"timestamp without time zone", "timestamp", 0},
+	"timestamp without time zone": {"timestamp without time zone", "timestamp", 0},
+	"timestamptz":                 {"timestamp with time zone", "timestamptz", 0},
+	"timestamp with time zone":    {"timestamp with time zone", "timestamptz", 0},
+	"interval":                    {"interval", "interval", 0},
+	"inet":                        {"inet", "inet", 0},
+	"cidr":                        {"cidr", "cidr", 0},
+	"macaddr":                     {"macaddr", "macaddr", 0},
+	"tsvector":                    {"tsvector", "tsvector", 0},
+	"tsquery":                     {"tsquery", "tsquery", 0},
+	"point":                       {"point", "point", 0},
+	"int4range":                   {"int4range", "int4range", 0},
+	"int8range":                   {"int8range", "int8range", 0},
+	"tstzrange":                   {"tstzrange", "tstzrange", 0},
+	"daterange":                   {"daterange", "daterange", 0},
+}
+
+var serialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+}
+
+// ResolveType converts a SQL type spelling into information_schema terms.
+// Unknown names (enums, domains, extension types) resolve to USER-DEFINED.
+func ResolveType(spelling string) TypeInfo {
+	name := strings.ToLower(strings.Join(strings.Fields(spelling), " "))
+
+	// Arrays: text[], integer[][], or "integer array"
+	if strings.HasSuffix(name, "[]") || strings.HasSuffix(name, " array") {
+		elem := strings.TrimSuffix(strings.TrimSuffix(name, " array"), "[]")
+		for strings.HasSuffix(elem, "[]") {
+			elem = strings.TrimSuffix(elem, "[]")
+		}
+		inner := ResolveType(elem)
+		return TypeInfo{DataType: "ARRAY", UDTName: "_" + inner.UDTName}
+	}
+
+	base, mods := splitModifiers(name)
+
+	if target, ok := serialTypes[base]; ok {
+		info := ResolveType(target)
+		info.Serial = true
+		return info
+	}
+
+	bt, ok := builtinTypes[base]
+	if !ok {
+		return TypeInfo{DataType: "USER-DEFINED", UDTName: udtName(spelling)}
+	}
+
+	info := TypeInfo{DataType: bt.dataType, UDTName: bt.udtName}
+	switch bt.udtName {
+	case "int2", "int4", "int8":
+		info.NumericPrecision = intPtr(bt.precision)
+		info.NumericScale = intPtr(0)
+	case "float4", "float8":
+		info.NumericPrecision = intPtr(bt.precision)
+	case "numeric":
+		if len(mods) > 0 {
+			info.NumericPrecision = intPtr(mods[0])
+			info.NumericScale = intPtr(0)
+		}
+		if len(mods) > 1 {
+			info.NumericScale = intPtr(mods[1])
+		}
+	case "varchar":
+		if len(mods) > 0 {
+			info.CharacterMaxLength = intPtr(mods[0])
+		}
+	case "bpchar":
+		// char without a length is char(1)
+		info.CharacterMaxLength = intPtr(1)
+		if len(mods) > 0 {
+			info.CharacterMaxLength = intPtr(mods[0])
+		}
+	}
+
+	return info
+}
+
+// splitModifiers separates "numeric(10,2)" into "numeric" and [10 2]. The
+// modifier may sit mid-name, as in "timestamp(3) with time zone".
+func splitModifiers(name string) (string, []int) {
+	open := strings.Index(name, "(")
+	if open < 0 {
+		return name, nil
+	}
+	end := strings.Index(name[open:], ")")
+	if end < 0 {
+		return name, nil
+	}
+	end += open
+
+	var mods []int
+	for _, part := range strings.Split(name[open+1:end], ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			mods = append(mods, n)
+		}
+	}
+
+	base := strings.TrimSpace(name[:open]) + " " + strings.TrimSpace(name[end+1:])
+	return strings.TrimSpace(base), mods
+}
+
+// udtName reduces a possibly schema-qualified, possibly quoted type name to
+// the bare udt_name postgres reports. Unquoted names fold to lower case.
+func udtName(spelling string) string {
+	name := strings.TrimSpace(spelling)
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) && len(name) > 1 {
+		return name[1 : len(name)-1]
+	}
+	return strings.ToLower(name)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,52 @@
+package fixture
+
+import "testing"
+
+func TestResolveType(t *testing.T) {
+	tests := []struct {
+		input     string
+		dataType  string
+		udtName   string
+		maxLength int
+		precision int
+		scale     int
+		serial    bool
+	}{
+		{"int", "integer", "int4", 0, 32, 0, false},
+		{"BIGSERIAL", "bigint", "int8", 0, 64, 0, true},
+		{"varchar(255)", "character varying", "varchar", 255, 0, 0, false},
+		{"character varying", "character varying", "varchar", 0, 0, 0, false},
+		{"char", "character", "bpchar", 1, 0, 0, false},
+		{"numeric(10, 2)", "numeric", "numeric", 0, 10, 2, false},
+		{"timestamptz", "timestamp with time zone", "timestamptz", 0, 0, 0, false},
+		{"timestamp(3) with time zone", "timestamp with time zone", "timestamptz", 0, 0, 0, false},
+		{"text[]", "ARRAY", "_text", 0, 0, 0, false},
+		{"integer array", "ARRAY", "_int4", 0, 0, 0, false},
+		{"public.order_status", "USER-DEFINED", "order_status", 0, 0, 0, false},
+		{`"Mood"`, "USER-DEFINED", "Mood", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ResolveType(tt.input)
+			if got.DataType != tt.dataType || got.UDTName != tt.udtName || got.Serial != tt.serial {
+				t.Errorf("ResolveType(%q) = %s/%s serial=%v, want %s/%s serial=%v",
+					tt.input, got.DataType, got.UDTName, got.Serial, tt.dataType, tt.udtName, tt.serial)
+			}
+			if deref(got.CharacterMaxLength) != tt.maxLength {
+				t.Errorf("CharacterMaxLength = %d, want %d", deref(got.CharacterMaxLength), tt.maxLength)
+			}
+			if deref(got.NumericPrecision) != tt.precision || deref(got.NumericScale) != tt.scale {
+				t.Errorf("precision/scale = %d/%d, want %d/%d",
+					deref(got.NumericPrecision), deref(got.NumericScale), tt.precision, tt.scale)
+			}
+		})
+	}
+}
+
+func deref(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// Minimum server_version_num for catalog features that are not available on
+// every supported PostgreSQL release (10 through 17).
+const (
+	versionProkind          = 110000 // pg_proc.prokind, procedures
+	versionIndexKeyAtts     = 110000 // pg_index.indnkeyatts, INCLUDE columns
+	versionNullsNotDistinct = 150000 // pg_index.indnullsnotdistinct
+	versionFKSetColumns     = 150000 // pg_constraint.confdelsetcols
+)
+
+// capabilities selects catalog query variants for a server version.
+type capabilities struct {
+	versionNum int
+}
+
+func newCapabilities(versionNum int) capabilities {
+	return capabilities{versionNum: versionNum}
+}
+
+// atLeast reports whether the server is at least the given server_version_num.
+// An unknown version (0) is treated as current so queries use modern catalogs.
+func (c capabilities) atLeast(versionNum int) bool {
+	return c.versionNum == 0 || c.versionNum >= versionNum
+}
+
+// indexKeyCount is the number of key (non-INCLUDE) columns of pg_index ix.
+func (c capabilities) indexKeyCount() string {
+	if c.atLeast(versionIndexKeyAtts) {
+		return "ix.indnkeyatts"
+	}
+	return "ix.indnatts"
+}
+
+// nullsNotDistinct is the NULLS NOT DISTINCT flag of pg_index ix.
+func (c capabilities) nullsNotDistinct() string {
+	if c.atLeast(versionNullsNotDistinct) {
+		return "ix.indnullsnotdistinct"
+	}
+	return "false"
+}
+
+// fkSetColumns is the ON DELETE SET NULL/DEFAULT column list of pg_constraint con.
+func (c capabilities) fkSetColumns() string {
+	if c.atLeast(versionFKSetColumns) {
+		return "con.confdelsetcols"
+	}
+	return "ARRAY[]::int2[]"
+}
+
+// routineKind maps pg_proc p to a routine type ('f', 'p', 'a', 'w').
+func (c capabilities) routineKind() string {
+	if c.atLeast(versionProkind) {
+		return "p.prokind"
+	}
+	return "(CASE WHEN p.proisagg THEN 'a' WHEN p.proiswindow THEN 'w' ELSE 'f' END)"
+}
+
+// unavailableFeatures lists the catalog features this server cannot provide.
+func (c capabilities) unavailableFeatures() []schema.UnavailableFeature {
+	var features []schema.UnavailableFeature
+
+	add := func(feature string, versionNum int) {
+		if !c.atLeast(versionNum) {
+			features = append(features, schema.UnavailableFeature{
+				Feature: feature,
+				Reason:  fmt.Sprintf("requires PostgreSQL %d+", versionNum/10000),
+			})
+		}
+	}
+
+	add("procedures", versionProkind)
+	add("index_include_columns", versionIndexKeyAtts)
+	add("nulls_not_distinct", versionNullsNotDistinct)
+	add("on_delete_set_columns", versionFKSetColumns)
+
+	return features
+}
+
+// catalogQueries returns the version-dependent catalog queries by name. It is
+// used by the compatibility tests to record each variant.
+func catalogQueries(c capabilities) map[string]string {
+	return map[string]string{
+		"constraints":  constraintsQuery(c),
+		"foreign_keys": foreignKeysQuery(c),
+		"indexes":      indexesQuery(c),
+		"namespaces":   namespacesQuery(c),
+		"routines":     routinesQuery(c),
+	}
+}
@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// regconfigRe matches text search config literals in column expressions,
+// e.g. to_tsvector('english'::regconfig, title).
+var regconfigRe = regexp.MustCompile(`'([^']+)'::regconfig`)
+
+func (i *Introspector) introspectCollations(ctx context.Context, schemas []string, tables []*schema.Table) ([]*schema.Collation, error) {
+	// Locale and determinism columns moved between versions, so read them
+	// through to_jsonb rather than naming them directly.
+	query := `
+		SELECT
+			n.nspname as schema_name,
+			c.collname as collation_name,
+			c.collprovider::text as provider,
+			COALESCE(to_jsonb(c) ->> 'colllocale', to_jsonb(c) ->> 'colliculocale', c.collcollate, '') as locale,
+			COALESCE(c.collctype, '') as ctype,
+			COALESCE((to_jsonb(c) ->> 'collisdeterministic')::boolean, true) as deterministic,
+			COALESCE(obj_description(c.oid, 'pg_collation'), '') as comment
+		FROM pg_collation c
+		JOIN pg_namespace n ON n.oid = c.collnamespace
+		WHERE n.nspname = ANY($1)
+		   OR n.nspname || '.' || c.collname = ANY($2)
+		ORDER BY n.nspname, c.collname
+	`
+
+	rows, err := i.pool.Query(ctx, query, schemas, columnCollationNames(tables))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collations: %w", err)
+	}
+	defer rows.Close()
+
+	var collations []*schema.Collation
+	for rows.Next() {
+		var provider string
+		coll := &schema.Collation{}
+		err := rows.Scan(
+			&coll.SchemaName, &coll.CollationName, &provider,
+			&coll.Locale, &coll.Ctype, &coll.Deterministic, &coll.Comment,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collation row: %w", err)
+		}
+		coll.Provider = mapCollationProvider(provider)
+		collations = append(collations, coll)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collation rows: %w", err)
+	}
+
+	linkCollationColumns(collations, tables)
+
+	return collations, nil
+}
+
+func (i *Introspector) introspectTextSearch(ctx context.Context, schemas []string, tables []*schema.Table) ([]*schema.TextSearchConfig, []*schema.TextSearchDictionary, error) {
+	// Expressions name a config the way regconfig prints it: bare when it
+	// is visible on the search path, schema-qualified otherwise. Matching
+	// on the regconfig text resolves either form to the config it means.
+	configQuery := `
+		SELECT
+			n.nspname as schema_name,
+			c.cfgname as config_name,
+			p.prsname as parser,
+			ARRAY(
+				SELECT DISTINCT d.dictname
+				FROM pg_ts_config_map m
+				JOIN pg_ts_dict d ON d.oid = m.mapdict
+				WHERE m.mapcfg = c.oid
+				ORDER BY d.dictname
+			) as dictionaries,
+			ARRAY(
+				SELECT DISTINCT dn.nspname || '.' || d.dictname
+				FROM pg_ts_config_map m
+				JOIN pg_ts_dict d ON d.oid = m.mapdict
+				JOIN pg_namespace dn ON dn.oid = d.dictnamespace
+				WHERE m.mapcfg = c.oid
+			) as dictionary_keys,
+			c.oid::regconfig::text as reference,
+			c.oid::regconfig::text = current_setting('default_text_search_config')
+				OR n.nspname || '.' || c.cfgname = current_setting('default_text_search_config') as is_default,
+			COALESCE(obj_description(c.oid, 'pg_ts_config'), '') as comment
+		FROM pg_ts_config c
+		JOIN pg_namespace n ON n.oid = c.cfgnamespace
+		JOIN pg_ts_parser p ON p.oid = c.cfgparser
+		WHERE n.nspname = ANY($1)
+		   OR c.oid::regconfig::text = ANY($2)
+		   OR c.oid::regconfig::text = current_setting('default_text_search_config')
+		   OR n.nspname || '.' || c.cfgname = current_setting('default_text_search_config')
+		ORDER BY n.nspname, c.cfgname
+	`
+
+	rows, err := i.pool.Query(ctx, configQuery, schemas, columnTextSearchConfigNames(tables))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query text search configurations: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*schema.TextSearchConfig
+	byReference := make(map[string]*schema.TextSearchConfig)
+	dictKeys := make(map[string]bool)
+	for rows.Next() {
+		var (
+			keys      []string
+			reference string
+		)
+		cfg := &schema.TextSearchConfig{}
+		err := rows.Scan(
+			&cfg.SchemaName, &cfg.ConfigName, &cfg.Parser,
+			&cfg.Dictionaries, &keys, &reference, &cfg.IsDefault, &cfg.Comment,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan text search configuration row: %w", err)
+		}
+		configs = append(configs, cfg)
+		byReference[reference] = cfg
+		for _, key := range keys {
+			dictKeys[key] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating text search configuration rows: %w", err)
+	}
+
+	linkTextSearchColumns(byReference, tables)
+
+	// Dictionaries: those defined in the included schemas plus any used by
+	// the configurations above.
+
+	dictQuery := `
+		SELECT
+			n.nspname as schema_name,
+			d.dictname as dictionary_name,
+			t.tmplname as template,
+			COALESCE(d.dictinitoption, '') as options,
+			COALESCE(obj_description(d.oid, 'pg_ts_dict'), '') as comment
+		FROM pg_ts_dict d
+		JOIN pg_namespace n ON n.oid = d.dictnamespace
+		JOIN pg_ts_template t ON t.oid = d.dicttemplate
+		WHERE n.nspname = ANY($1)
+		   OR n.nspname || '.' || d.dictname = ANY($2)
+		ORDER BY n.nspname, d.dictname
+	`
+
+	dictRows, err := i.pool.Query(ctx, dictQuery, schemas, sortedKeys(dictKeys))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query text search dictionaries: %w", err)
+	}
+	defer dictRows.Close()
+
+	var dicts []*schema.TextSearchDictionary
+	for dictRows.Next() {
+		dict := &schema.TextSearchDictionary{}
+		err := dictRows.Scan(
+			&dict.SchemaName, &dict.DictionaryName, &dict.Template,
+			&dict.Options, &dict.Comment,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan text search dictionary row: %w", err)
+		}
+		dicts = append(dicts, dict)
+	}
+
+	if err := dictRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating text search dictionary rows: %w", err)
+	}
+
+	return configs, dicts, nil
+}
+
+// columnCollationNames returns the distinct non-default collations used by
+// columns, as schema.name.
+func columnCollationNames(tables []*schema.Table) []string {
+	seen := make(map[string]bool)
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			if key := collationKey(col); key != "" {
+				seen[key] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// collationKey returns schema.name for a column's collation, or "" when the
+// column uses its type's default.
+func collationKey(col *schema.Column) string {
+	if col.Collation == nil || *col.Collation == "" {
+		return ""
+	}
+	return col.CollationSchema + "." + *col.Collation
+}
+
+// columnTextSearchConfigNames returns the text search configs referenced by
+// generated columns or defaults (e.g. to_tsvector('english'::regconfig, ...)),
+// as written in the expression, schema qualifier included.
+func columnTextSearchConfigNames(tables []*schema.Table) []string {
+	seen := make(map[string]bool)
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			for _, name := range regconfigNames(col) {
+				seen[name] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+func regconfigNames(col *schema.Column) []string {
+	var names []string
+	for _, expr := range []*string{col.GenerationExpression, col.DefaultValue} {
+		if expr == nil {
+			continue
+		}
+		for _, m := range regconfigRe.FindAllStringSubmatch(*expr, -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+func linkCollationColumns(collations []*schema.Collation, tables []*schema.Table) {
+	byKey := make(map[string]*schema.Collation)
+	for _, c := range collations {
+		byKey[c.SchemaName+"."+c.CollationName] = c
+	}
+
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			if c, ok := byKey[collationKey(col)]; ok {
+				c.UsedBy = append(c.UsedBy, qualifiedColumn(t.SchemaName, t.TableName, col.ColumnName))
+			}
+		}
+	}
+}
+
+// linkTextSearchColumns records the columns using each config, keyed by the
+// config's regconfig text.
+func linkTextSearchColumns(byReference map[string]*schema.TextSearchConfig, tables []*schema.Table) {
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			for _, name := range regconfigNames(col) {
+				if c, ok := byReference[name]; ok {
+					c.UsedBy = append(c.UsedBy, qualifiedColumn(t.SchemaName, t.TableName, col.ColumnName))
+				}
+			}
+		}
+	}
+}
+
+func mapCollationProvider(char string) string {
+	switch char {
+	case "c":
+		return "libc"
+	case "i":
+		return "icu"
+	case "b":
+		return "builtin"
+	case "d":
+		return "default"
+	default:
+		return char
+	}
+}
+
+// qualifiedColumn renders table.column, prefixing the schema when it is not public.
+func qualifiedColumn(schemaName, tableName, columnName string) string {
+	name := tableName + "." + columnName
+	if schemaName != "" && schemaName != "public" {
+		name = schemaName + "." + name
+	}
+	return name
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestColumnTextSearchConfigNames(t *testing.T) {
+	tables := []*schema.Table{
+		{
+			TableName: "articles",
+			Columns: []*schema.Column{
+				{ColumnName: "title"},
+				{
+					ColumnName:           "search",
+					GenerationExpression: ptr("to_tsvector('english'::regconfig, COALESCE(title, ''::text))"),
+				},
+				{
+					ColumnName:           "search_de",
+					GenerationExpression: ptr("to_tsvector('public.german_unaccent'::regconfig, body)"),
+				},
+			},
+		},
+	}
+
+	result := columnTextSearchConfigNames(tables)
+	expected := []string{"english", "public.german_unaccent"}
+
+	if len(result) != len(expected) {
+		t.Fatalf("got %v, want %v", result, expected)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("result[%d] = %q, want %q", i, result[i], expected[i])
+		}
+	}
+}
+
+func TestLinkCollationColumns(t *testing.T) {
+	ci := &schema.Collation{SchemaName: "public", CollationName: "case_insensitive"}
+	other := &schema.Collation{SchemaName: "billing", CollationName: "case_insensitive"}
+	tables := []*schema.Table{
+		{
+			TableName:  "users",
+			SchemaName: "public",
+			Columns: []*schema.Column{
+				{ColumnName: "email", Collation: ptr("case_insensitive"), CollationSchema: "public"},
+				{ColumnName: "name"},
+			},
+		},
+		{
+			TableName:  "accounts",
+			SchemaName: "billing",
+			Columns: []*schema.Column{
+				{ColumnName: "handle", Collation: ptr("case_insensitive"), CollationSchema: "public"},
+				{ColumnName: "code", Collation: ptr("case_insensitive"), CollationSchema: "billing"},
+			},
+		},
+	}
+
+	if got, want := columnCollationNames(tables), []string{"billing.case_insensitive", "public.case_insensitive"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("columnCollationNames() = %v, want %v", got, want)
+	}
+
+	linkCollationColumns([]*schema.Collation{ci, other}, tables)
+
+	if want := []string{"users.email", "billing.accounts.handle"}; !reflect.DeepEqual(ci.UsedBy, want) {
+		t.Errorf("public.case_insensitive used by %v, want %v", ci.UsedBy, want)
+	}
+	if want := []string{"billing.accounts.code"}; !reflect.DeepEqual(other.UsedBy, want) {
+		t.Errorf("billing.case_insensitive used by %v, want %v", other.UsedBy, want)
+	}
+}
+
+func TestLinkTextSearchColumns(t *testing.T) {
+	english := &schema.TextSearchConfig{SchemaName: "pg_catalog", ConfigName: "english"}
+	custom := &schema.TextSearchConfig{SchemaName: "search", ConfigName: "english"}
+	tables := []*schema.Table{
+		{
+			TableName:  "articles",
+			SchemaName: "public",
+			Columns: []*schema.Column{
+				{ColumnName: "search", GenerationExpression: ptr("to_tsvector('english'::regconfig, title)")},
+				{ColumnName: "search_custom", GenerationExpression: ptr("to_tsvector('search.english'::regconfig, title)")},
+			},
+		},
+	}
+
+	linkTextSearchColumns(map[string]*schema.TextSearchConfig{
+		"english":        english,
+		"search.english": custom,
+	}, tables)
+
+	if want := []string{"articles.search"}; !reflect.DeepEqual(english.UsedBy, want) {
+		t.Errorf("pg_catalog.english used by %v, want %v", english.UsedBy, want)
+	}
+	if want := []string{"articles.search_custom"}; !reflect.DeepEqual(custom.UsedBy, want) {
+		t.Errorf("search.english used by %v, want %v", custom.UsedBy, want)
+	}
+}
@@ -23,8 +23,14 @@ func (i *Introspector) introspectColumns(ctx context.Context, schemaName, tableN
 			COALESCE(is_generated, 'NEVER') as is_generated,
 			generation_expression,
 			collation_name,
-			ordinal_position
+			COALESCE(collation_schema, '') as collation_schema,
+			ordinal_position,
+			COALESCE(a.attinhcount > 0 AND NOT a.attislocal, false) as inherited,
+			COALESCE(a.atttypmod, -1) as type_modifier
 		FROM information_schema.columns
+		LEFT JOIN pg_attribute a
+			ON a.attrelid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass
+			AND a.attname = column_name
 		WHERE table_schema = $1 AND table_name = $2
 		ORDER BY ordinal_position
 	`
@@ -39,18 +45,20 @@ func (i *Introspector) introspectColumns(ctx context.Context, schemaName, tableN
 	for rows.Next() {
 		var (
 			columnName, dataType, udtName, isNullable string
-			isIdentity, isGenerated                   string
+			isIdentity, isGenerated, collationSchema  string
 			ordinalPosition                           int
 			columnDefault, identityGeneration         *string
 			generationExpression, collation           *string
 			charMaxLength, numPrecision, numScale     *int
+			inherited                                 bool
+			typeModifier                              int
 		)
 
 		err := rows.Scan(
 			&columnName, &dataType, &udtName, &isNullable,
 			&columnDefault, &charMaxLength, &numPrecision, &numScale,
 			&isIdentity, &identityGeneration, &isGenerated, &generationExpression,
-			&collation, &ordinalPosition,
+			&collation, &collationSchema, &ordinalPosition, &inherited, &typeModifier,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan column row: %w", err)
@@ -66,10 +74,13 @@ func (i *Introspector) introspectColumns(ctx context.Context, schemaName, tableN
 			GenerationExpression: generationExpression,
 			IsIdentity:           isIdentity == "YES",
 			Collation:            collation,
+			CollationSchema:      collationSchema,
 			CharacterMaxLength:   charMaxLength,
 			NumericPrecision:     numPrecision,
 			NumericScale:         numScale,
 			OrdinalPosition:      ordinalPosition,
+			Inherited:            inherited,
+			TypeModifier:         typeModifier,
 		}
 
 		if identityGeneration != nil {
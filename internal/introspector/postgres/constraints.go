@@ -8,31 +8,7 @@ import (
 )
 
 func (i *Introspector) introspectConstraints(ctx context.Context, schemaName, tableName string) ([]*schema.Constraint, error) {
-	query := `
-		SELECT
-			con.conname as constraint_name,
-			con.contype::text as constraint_type,
-			ARRAY(
-				SELECT a.attname
-				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
-				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
-				ORDER BY k.ord
-			) as columns,
-			pg_get_constraintdef(con.oid) as definition
-		FROM pg_constraint con
-		JOIN pg_class c ON c.oid = con.conrelid
-		JOIN pg_namespace n ON n.oid = c.relnamespace
-		WHERE n.nspname = $1 AND c.relname = $2
-		  AND con.contype IN ('p', 'u', 'c', 'x')
-		ORDER BY
-			CASE con.contype
-				WHEN 'p' THEN 1
-				WHEN 'u' THEN 2
-				WHEN 'c' THEN 3
-				WHEN 'x' THEN 4
-			END,
-			con.conname
-	`
+	query := constraintsQuery(i.caps)
 
 	rows, err := i.pool.Query(ctx, query, schemaName, tableName)
 	if err != nil {
@@ -45,9 +21,14 @@ func (i *Introspector) introspectConstraints(ctx context.Context, schemaName, ta
 		var (
 			constraintName, constraintType, definition string
 			columns                                    []string
+			deferrable, deferred, validated, noInherit bool
+			nullsNotDistinct                           bool
 		)
 
-		err := rows.Scan(&constraintName, &constraintType, &columns, &definition)
+		err := rows.Scan(
+			&constraintName, &constraintType, &columns, &definition,
+			&deferrable, &deferred, &validated, &noInherit, &nullsNotDistinct,
+		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan constraint row: %w", err)
 		}
@@ -55,10 +36,15 @@ func (i *Introspector) introspectConstraints(ctx context.Context, schemaName, ta
 		conType := mapConstraintType(constraintType)
 
 		con := &schema.Constraint{
-			ConstraintName: constraintName,
-			ConstraintType: conType,
-			Columns:        columns,
-			Definition:     &definition,
+			ConstraintName:    constraintName,
+			ConstraintType:    conType,
+			Columns:           columns,
+			Definition:        &definition,
+			Deferrable:        deferrable,
+			InitiallyDeferred: deferred,
+			NotValid:          !validated,
+			NoInherit:         noInherit,
+			NullsNotDistinct:  nullsNotDistinct,
 		}
 
 		// Extract check expression for check constraints
@@ -92,3 +78,45 @@ func mapConstraintType(pgType string) string {
 		return pgType
 	}
 }
+
+// constraintsQuery selects primary key, unique, check and exclusion constraints.
+func constraintsQuery(c capabilities) string {
+	// pg_index is only joined for unique constraints, so the flag is NULL
+	// for the others.
+	nullsNotDistinct := "false"
+	if c.atLeast(versionNullsNotDistinct) {
+		nullsNotDistinct = "COALESCE(" + c.nullsNotDistinct() + ", false)"
+	}
+
+	return fmt.Sprintf(`
+			SELECT
+				con.conname as constraint_name,
+				con.contype::text as constraint_type,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as columns,
+				pg_get_constraintdef(con.oid) as definition,
+				con.condeferrable as deferrable,
+				con.condeferred as initially_deferred,
+				con.convalidated as validated,
+				con.connoinherit as no_inherit,
+				%s as nulls_not_distinct
+			FROM pg_constraint con
+			JOIN pg_class c ON c.oid = con.conrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			LEFT JOIN pg_index ix ON ix.indexrelid = con.conindid AND con.contype = 'u'
+			WHERE n.nspname = $1 AND c.relname = $2
+			  AND con.contype IN ('p', 'u', 'c', 'x')
+			ORDER BY
+				CASE con.contype
+					WHEN 'p' THEN 1
+					WHEN 'u' THEN 2
+					WHEN 'c' THEN 3
+					WHEN 'x' THEN 4
+				END,
+				con.conname
+		`, nullsNotDistinct)
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// environmentSettings is the curated set of pg_settings that change how
+// queries behave or how results are formatted. client_encoding is left out:
+// it describes the session X-Rai connected with, not the database. The rest
+// are read as the defaults a new session gets, not as X-Rai's own session
+// has them; see introspectSettings.
+var environmentSettings = []string{
+	"TimeZone",
+	"DateStyle",
+	"IntervalStyle",
+	"search_path",
+	"default_transaction_isolation",
+	"default_transaction_read_only",
+	"default_text_search_config",
+	"default_tablespace",
+	"max_identifier_length",
+	"server_encoding",
+	"standard_conforming_strings",
+	"lc_messages",
+	"lc_monetary",
+	"lc_numeric",
+	"lc_time",
+	"extra_float_digits",
+	"bytea_output",
+	"row_security",
+	"statement_timeout",
+	"lock_timeout",
+	"idle_in_transaction_session_timeout",
+	"max_connections",
+	"work_mem",
+	"jit",
+}
+
+func (i *Introspector) introspectEnvironment(ctx context.Context) (*schema.Environment, error) {
+	env := &schema.Environment{
+		ServerVersion: i.version,
+		VersionString: i.versionString,
+	}
+
+	// Locale provider and ICU locale columns vary across versions (datlocale
+	// replaced daticulocale in PG17), so read them through to_jsonb.
+	query := `
+		SELECT
+			pg_encoding_to_char(d.encoding) as encoding,
+			d.datcollate as lc_collate,
+			d.datctype as lc_ctype,
+			COALESCE(to_jsonb(d) ->> 'datlocprovider', '') as locale_provider,
+			COALESCE(to_jsonb(d) ->> 'datlocale', to_jsonb(d) ->> 'daticulocale', '') as icu_locale
+		FROM pg_database d
+		WHERE d.datname = current_database()
+	`
+
+	var provider string
+	err := i.pool.QueryRow(ctx, query).Scan(
+		&env.Encoding, &env.Collate, &env.Ctype, &provider, &env.ICULocale,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database properties: %w", err)
+	}
+	env.LocaleProvider = mapLocaleProvider(provider)
+
+	// Database size requires CONNECT privilege; treat failure as non-fatal
+	var size int64
+	var sizePretty string
+	row := i.pool.QueryRow(ctx, "SELECT pg_database_size(current_database()), pg_size_pretty(pg_database_size(current_database()))")
+	if err := row.Scan(&size, &sizePretty); err == nil {
+		env.SizeBytes = &size
+		env.SizePretty = sizePretty
+	}
+
+	settings, err := i.introspectSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	env.Settings = settings
+
+	return env, nil
+}
+
+// introspectSettings reads each setting's reset value, which ignores SET
+// commands run in this session. Values a driver sent in the startup packet
+// (source "client", typically TimeZone, DateStyle and extra_float_digits)
+// are replaced by the database or role default from pg_db_role_setting,
+// and dropped when there is none, since the server-wide value is hidden.
+func (i *Introspector) introspectSettings(ctx context.Context) ([]schema.Setting, error) {
+	query := `
+		SELECT
+			s.name,
+			COALESCE(d.value, s.reset_val) as value,
+			CASE WHEN d.value IS NULL OR d.value ~ '^-?[0-9.]+$' THEN COALESCE(s.unit, '') ELSE '' END as unit,
+			COALESCE(d.source, s.reset_source) as source
+		FROM pg_settings s
+		LEFT JOIN LATERAL (
+			SELECT
+				substr(cfg, strpos(cfg, '=') + 1) as value,
+				CASE
+					WHEN r.setdatabase <> 0 AND r.setrole <> 0 THEN 'database user'
+					WHEN r.setrole <> 0 THEN 'user'
+					WHEN r.setdatabase <> 0 THEN 'database'
+					ELSE 'global'
+				END as source
+			FROM pg_db_role_setting r
+			CROSS JOIN LATERAL unnest(r.setconfig) cfg
+			WHERE r.setdatabase IN (0, (SELECT oid FROM pg_database WHERE datname = current_database()))
+			  AND r.setrole IN (0, (SELECT oid FROM pg_roles WHERE rolname = current_user))
+			  AND lower(split_part(cfg, '=', 1)) = lower(s.name)
+			ORDER BY r.setdatabase <> 0 AND r.setrole <> 0 DESC, r.setrole <> 0 DESC, r.setdatabase <> 0 DESC
+			LIMIT 1
+		) d ON s.reset_source = 'client'
+		WHERE s.name = ANY($1)
+		  AND (s.reset_source <> 'client' OR d.value IS NOT NULL)
+		ORDER BY s.name
+	`
+
+	rows, err := i.pool.Query(ctx, query, environmentSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settings: %w", err)
+	}
+	defer rows.Close()
+
+	var settings []schema.Setting
+	for rows.Next() {
+		var s schema.Setting
+		if err := rows.Scan(&s.Name, &s.Value, &s.Unit, &s.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan setting row: %w", err)
+		}
+		settings = append(settings, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating setting rows: %w", err)
+	}
+
+	return settings, nil
+}
+
+func mapLocaleProvider(char string) string {
+	switch char {
+	case "c":
+		return "libc"
+	case "i":
+		return "icu"
+	case "b":
+		return "builtin"
+	default:
+		return char
+	}
+}
@@ -0,0 +1,480 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fixtureFormatVersion is bumped when the fixture file layout changes.
+const fixtureFormatVersion = 1
+
+// querier is the subset of pgxpool.Pool used for catalog queries. It lets the
+// introspector run against a live pool, a recorder or a fixture replayer.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Fixture is a recording of every catalog query issued during introspection
+// together with its result rows.
+type Fixture struct {
+	FormatVersion int            `json:"format_version"`
+	RecordedAt    string         `json:"recorded_at,omitempty"`
+	Queries       []FixtureQuery `json:"queries"`
+}
+
+// FixtureQuery is a single recorded query and its result.
+type FixtureQuery struct {
+	SQL     string              `json:"sql"`
+	Args    json.RawMessage     `json:"args,omitempty"`
+	Columns []string            `json:"columns,omitempty"`
+	Rows    [][]json.RawMessage `json:"rows,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	Code    string              `json:"code,omitempty"` // SQLSTATE of a server error
+}
+
+// LoadFixture reads a recorded fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+
+	if f.FormatVersion != fixtureFormatVersion {
+		return nil, fmt.Errorf("unsupported fixture format version %d", f.FormatVersion)
+	}
+
+	return &f, nil
+}
+
+// Save writes the fixture as indented JSON.
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// recorder wraps a querier and captures every query and its rows.
+type recorder struct {
+	q       querier
+	mu      sync.Mutex
+	fixture Fixture
+	err     error // first value that could not be recorded
+}
+
+func newRecorder(q querier) *recorder {
+	return &recorder{
+		q: q,
+		fixture: Fixture{
+			FormatVersion: fixtureFormatVersion,
+			RecordedAt:    time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+func (r *recorder) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	entry := FixtureQuery{
+		SQL:  normalizeSQL(sql),
+		Args: marshalArgs(args),
+	}
+
+	rows, err := r.q.Query(ctx, sql, args...)
+	if err != nil {
+		entry.setError(err)
+		r.add(entry)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for _, fd := range rows.FieldDescriptions() {
+		entry.Columns = append(entry.Columns, fd.Name)
+	}
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			entry.setError(err)
+			r.add(entry)
+			return nil, err
+		}
+
+		row := make([]json.RawMessage, len(values))
+		for j, v := range values {
+			raw, err := encodeValue(v)
+			if err != nil {
+				err = fmt.Errorf("failed to record column %s of %q: %w", entry.Columns[j], truncateSQL(entry.SQL, 80), err)
+				r.fail(err)
+				return nil, err
+			}
+			row[j] = raw
+		}
+		entry.Rows = append(entry.Rows, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		entry.setError(err)
+		r.add(entry)
+		return nil, err
+	}
+
+	r.add(entry)
+	return newFixtureRows(&entry), nil
+}
+
+func (r *recorder) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := r.Query(ctx, sql, args...)
+	return &fixtureRow{rows: rows, err: err}
+}
+
+// setError records err, keeping the SQLSTATE of server errors so replay can
+// tell a missing catalog object from other failures.
+func (q *FixtureQuery) setError(err error) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		q.Error = pgErr.Message
+		q.Code = pgErr.Code
+		return
+	}
+	q.Error = err.Error()
+}
+
+// err returns the recorded error, or nil if the query succeeded.
+func (q *FixtureQuery) err() error {
+	switch {
+	case q.Code != "":
+		return &pgconn.PgError{Severity: "ERROR", Code: q.Code, Message: q.Error}
+	case q.Error != "":
+		return errors.New(q.Error)
+	}
+	return nil
+}
+
+func (r *recorder) add(entry FixtureQuery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Queries = append(r.fixture.Queries, entry)
+}
+
+// fail marks the recording incomplete so it cannot be saved.
+func (r *recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// encodeValue encodes a value returned by pgx.Rows.Values so that it scans
+// back into the destinations pgx would accept. Values JSON cannot represent
+// (NaN and infinite numbers, infinite timestamps, intervals) are stored as
+// their PostgreSQL text form.
+func encodeValue(v any) (json.RawMessage, error) {
+	switch v := v.(type) {
+	case float64:
+		return encodeFloat(v)
+	case float32:
+		return encodeFloat(float64(v))
+	case pgtype.InfinityModifier:
+		return json.Marshal(v.String())
+	case pgtype.Numeric:
+		switch {
+		case !v.Valid:
+			return json.RawMessage("null"), nil
+		case v.NaN:
+			return json.RawMessage(`"NaN"`), nil
+		case v.InfinityModifier == pgtype.Infinity:
+			return json.RawMessage(`"Infinity"`), nil
+		case v.InfinityModifier == pgtype.NegativeInfinity:
+			return json.RawMessage(`"-Infinity"`), nil
+		}
+		return v.MarshalJSON()
+	case []any:
+		elems := make([]json.RawMessage, len(v))
+		for j, elem := range v {
+			raw, err := encodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[j] = raw
+		}
+		return json.Marshal(elems)
+	case json.Marshaler:
+		return v.MarshalJSON()
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil {
+			return nil, err
+		}
+		return encodeValue(value)
+	}
+
+	return json.Marshal(v)
+}
+
+func encodeFloat(f float64) (json.RawMessage, error) {
+	switch {
+	case math.IsNaN(f):
+		return json.RawMessage(`"NaN"`), nil
+	case math.IsInf(f, 1):
+		return json.RawMessage(`"Infinity"`), nil
+	case math.IsInf(f, -1):
+		return json.RawMessage(`"-Infinity"`), nil
+	}
+	return json.Marshal(f)
+}
+
+// scanValue decodes a recorded value into dest. Values recorded as text
+// (see encodeValue) are parsed the way pgx parses them from the server.
+func scanValue(raw json.RawMessage, dest any) error {
+	err := json.Unmarshal(raw, dest)
+	if err == nil {
+		return nil
+	}
+
+	var text string
+	if json.Unmarshal(raw, &text) != nil {
+		// A number scanned as text, or a type that takes its text form
+		text = string(raw)
+	}
+
+	switch d := dest.(type) {
+	case *float64:
+		f, perr := strconv.ParseFloat(text, 64)
+		if perr != nil {
+			return err
+		}
+		*d = f
+		return nil
+	case *float32:
+		f, perr := strconv.ParseFloat(text, 32)
+		if perr != nil {
+			return err
+		}
+		*d = float32(f)
+		return nil
+	case *string:
+		var n json.Number
+		if json.Unmarshal(raw, &n) == nil {
+			*d = n.String()
+			return nil
+		}
+	case sql.Scanner:
+		return d.Scan(text)
+	}
+	return err
+}
+
+// replayer serves recorded results in place of a live database. Queries are
+// matched on normalized SQL and arguments; repeated queries are served in
+// recorded order.
+type replayer struct {
+	mu      sync.Mutex
+	entries map[string][]*FixtureQuery
+	served  map[string]int
+}
+
+func newReplayer(f *Fixture) *replayer {
+	r := &replayer{
+		entries: make(map[string][]*FixtureQuery),
+		served:  make(map[string]int),
+	}
+	for idx := range f.Queries {
+		q := &f.Queries[idx]
+		key := fixtureKey(q.SQL, q.Args)
+		r.entries[key] = append(r.entries[key], q)
+	}
+	return r
+}
+
+func (r *replayer) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	normalized := normalizeSQL(sql)
+	key := fixtureKey(normalized, marshalArgs(args))
+
+	r.mu.Lock()
+	entries := r.entries[key]
+	n := r.served[key]
+	r.served[key] = n + 1
+	r.mu.Unlock()
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("fixture: no recorded result for query %q", truncateSQL(normalized, 80))
+	}
+	if n >= len(entries) {
+		n = len(entries) - 1
+	}
+
+	q := entries[n]
+	if err := q.err(); err != nil {
+		return nil, err
+	}
+	return newFixtureRows(q), nil
+}
+
+func (r *replayer) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := r.Query(ctx, sql, args...)
+	return &fixtureRow{rows: rows, err: err}
+}
+
+// fixtureRows implements pgx.Rows over recorded values. Values are stored as
+// JSON and decoded into the scan destinations.
+type fixtureRows struct {
+	q      *FixtureQuery
+	pos    int
+	closed bool
+}
+
+func newFixtureRows(q *FixtureQuery) *fixtureRows {
+	return &fixtureRows{q: q, pos: -1}
+}
+
+func (r *fixtureRows) Close() {
+	r.closed = true
+}
+
+func (r *fixtureRows) Err() error {
+	return nil
+}
+
+func (r *fixtureRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag(fmt.Sprintf("SELECT %d", len(r.q.Rows)))
+}
+
+func (r *fixtureRows) FieldDescriptions() []pgconn.FieldDescription {
+	fds := make([]pgconn.FieldDescription, len(r.q.Columns))
+	for j, name := range r.q.Columns {
+		fds[j] = pgconn.FieldDescription{Name: name}
+	}
+	return fds
+}
+
+func (r *fixtureRows) Next() bool {
+	if r.closed {
+		return false
+	}
+	r.pos++
+	if r.pos >= len(r.q.Rows) {
+		r.closed = true
+		return false
+	}
+	return true
+}
+
+func (r *fixtureRows) Scan(dest ...any) error {
+	row := r.q.Rows[r.pos]
+	if len(dest) != len(row) {
+		return fmt.Errorf("fixture: number of field descriptions must equal number of destinations, got %d and %d", len(row), len(dest))
+	}
+
+	for j, d := range dest {
+		if d == nil {
+			continue
+		}
+		if err := scanValue(row[j], d); err != nil {
+			return fmt.Errorf("fixture: failed to scan column %d: %w", j, err)
+		}
+	}
+	return nil
+}
+
+func (r *fixtureRows) Values() ([]any, error) {
+	row := r.q.Rows[r.pos]
+	values := make([]any, len(row))
+	for j, raw := range row {
+		if err := json.Unmarshal(raw, &values[j]); err != nil {
+			return nil, fmt.Errorf("fixture: failed to decode column %d: %w", j, err)
+		}
+	}
+	return values, nil
+}
+
+func (r *fixtureRows) RawValues() [][]byte {
+	row := r.q.Rows[r.pos]
+	raw := make([][]byte, len(row))
+	for j, v := range row {
+		raw[j] = v
+	}
+	return raw
+}
+
+func (r *fixtureRows) Conn() *pgx.Conn {
+	return nil
+}
+
+// fixtureRow implements pgx.Row on top of fixtureRows.
+type fixtureRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *fixtureRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		return pgx.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
+// normalizeSQL collapses whitespace so recorded queries are stable across
+// indentation changes.
+func normalizeSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+func marshalArgs(args []any) json.RawMessage {
+	if len(args) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", fmt.Sprint(args)))
+	}
+	return data
+}
+
+// fixtureKey identifies a query by SQL and arguments. Arguments are compacted
+// because saved fixtures are indented.
+func fixtureKey(sql string, args json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, args); err != nil {
+		return sql + "\x00" + string(args)
+	}
+	return sql + "\x00" + buf.String()
+}
+
+func truncateSQL(sql string, n int) string {
+	if len(sql) <= n {
+		return sql
+	}
+	return sql[:n] + "..."
+}
@@ -0,0 +1,365 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nenorrell/X-Rai/internal/config"
+)
+
+// fakeCatalog answers catalog queries for a small two-table database. Queries
+// it does not recognise return no rows. It is synthetic: the rows are written
+// by hand, not captured from a server.
+type fakeCatalog struct {
+	// versionNum is the server_version_num it reports; zero means 160002.
+	// Queries naming a catalog column that release does not have fail with
+	// undefined_column, as they would on that server.
+	versionNum int
+
+	// fail makes queries containing a key return its error.
+	fail map[string]error
+}
+
+// catalogColumnReleases maps version-dependent catalog columns to the release
+// that added them and, for proisagg, the release that removed it.
+var catalogColumnReleases = map[string][2]int{
+	"prokind":             {110000, 0},
+	"proisagg":            {0, 110000},
+	"indnkeyatts":         {110000, 0},
+	"indnullsnotdistinct": {150000, 0},
+	"confdelsetcols":      {150000, 0},
+}
+
+func (f fakeCatalog) version() int {
+	if f.versionNum == 0 {
+		return 160002
+	}
+	return f.versionNum
+}
+
+func (f fakeCatalog) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	for fragment, err := range f.fail {
+		if strings.Contains(sql, fragment) {
+			return nil, err
+		}
+	}
+	for column, releases := range catalogColumnReleases {
+		added, removed := releases[0], releases[1]
+		if strings.Contains(sql, column) && (f.version() < added || (removed != 0 && f.version() >= removed)) {
+			return nil, &pgconn.PgError{Severity: "ERROR", Code: "42703", Message: fmt.Sprintf("column %s does not exist", column)}
+		}
+	}
+
+	var rows [][]any
+	table := ""
+	if len(args) == 2 {
+		table, _ = args[1].(string)
+	}
+
+	major, minor := f.version()/10000, f.version()%10000
+	switch {
+	case strings.Contains(sql, "FROM pg_settings s"):
+		rows = [][]any{{"TimeZone", "UTC", "", "database"}, {"work_mem", "4096", "kB", "default"}}
+	case strings.Contains(sql, "FROM pg_database d"):
+		rows = [][]any{{"UTF8", "en_US.UTF-8", "en_US.UTF-8", "c", ""}}
+	case strings.Contains(sql, "current_database()"):
+		rows = [][]any{{"shop", fmt.Sprintf("PostgreSQL %d.%d on x86_64-pc-linux-gnu", major, minor)}}
+	case strings.Contains(sql, "SHOW server_version_num"):
+		rows = [][]any{{strconv.Itoa(f.version())}}
+	case strings.Contains(sql, "SHOW server_version"):
+		rows = [][]any{{fmt.Sprintf("%d.%d", major, minor)}}
+	case strings.Contains(sql, "pg_get_userbyid(n.nspowner)"):
+		rows = [][]any{{"public", "postgres", "", 2, 0, 0, 0, 0, 0, 0, 1, 0}}
+	case strings.Contains(sql, "FROM pg_default_acl d"):
+		rows = [][]any{{"postgres", "r", "reporting", []string{"SELECT"}}}
+	case strings.Contains(sql, "FROM information_schema.tables"):
+		rows = [][]any{
+			{"public", "orders", "", "p", "", "heap", nil, nil, "d", false, false},
+			{"public", "users", "Registered users", "p", "", "heap", nil, nil, "d", false, false},
+		}
+	case strings.Contains(sql, "SELECT column_name, is_nullable"):
+		if table == "orders" {
+			rows = [][]any{{"id", "NO"}, {"user_id", "NO"}}
+		}
+	case strings.Contains(sql, "FROM information_schema.columns"):
+		switch table {
+		case "orders":
+			rows = [][]any{
+				{"id", "bigint", "int8", "NO", nil, nil, 64, 0, "YES", "ALWAYS", "NEVER", nil, nil, "", 1, false, -1},
+				{"user_id", "bigint", "int8", "NO", nil, nil, 64, 0, "NO", nil, "NEVER", nil, nil, "", 2, false, -1},
+			}
+		case "users":
+			rows = [][]any{
+				{"id", "bigint", "int8", "NO", nil, nil, 64, 0, "YES", "ALWAYS", "NEVER", nil, nil, "", 1, false, -1},
+				{"email", "text", "text", "NO", nil, nil, nil, nil, "NO", nil, "NEVER", nil, nil, "", 2, false, -1},
+			}
+		}
+	case strings.Contains(sql, "FROM pg_index ix"):
+		rows = [][]any{
+			{table + "_pkey", true, true, "btree", "CREATE UNIQUE INDEX " + table + "_pkey ON public." + table + " USING btree (id)",
+				[]string{"id"}, nil, nil, []string{"id"}, []int32{0}, []string{""}, []string{""},
+				true, true, true, false, nil, ""},
+		}
+	case strings.Contains(sql, "con.contype = 'f'"):
+		if table == "orders" {
+			rows = [][]any{
+				{"orders_user_id_fkey", []string{"user_id"}, "public", "users", []string{"id"}, "a", "c", nil, "s", false, false, true},
+			}
+		}
+	case strings.Contains(sql, "con.contype IN"):
+		rows = [][]any{
+			{table + "_pkey", "p", []string{"id"}, "PRIMARY KEY (id)", false, false, true, false, false},
+		}
+	case strings.Contains(sql, "SELECT reltuples"):
+		rows = [][]any{{int64(42)}}
+	case strings.Contains(sql, "FROM pg_extension"):
+		rows = [][]any{{""}}
+	case strings.Contains(sql, "FROM pg_enum"):
+		rows = [][]any{{"public", "order_status", []string{"pending", "paid"}, ""}}
+	}
+
+	q := &FixtureQuery{}
+	for _, row := range rows {
+		raw := make([]json.RawMessage, len(row))
+		for j, v := range row {
+			raw[j], _ = json.Marshal(v)
+		}
+		q.Rows = append(q.Rows, raw)
+	}
+	return newFixtureRows(q), nil
+}
+
+func (f fakeCatalog) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := f.Query(ctx, sql, args...)
+	return &fixtureRow{rows: rows, err: err}
+}
+
+// TestFixture_RecordReplay records fakeCatalog through the recorder and
+// replays testdata/fixtures/shop.json. That fixture is synthetic: -update
+// regenerates it from fakeCatalog's hand-written rows, not from a server, so
+// it checks the record/replay plumbing rather than real catalog output.
+// Catalogs recorded from real servers live in testdata/compat.
+func TestFixture_RecordReplay(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	path := filepath.Join("testdata", "fixtures", "shop.json")
+
+	rec := newRecorder(fakeCatalog{})
+	live, err := newIntrospector(ctx, rec, nil)
+	if err != nil {
+		t.Fatalf("newIntrospector() error = %v", err)
+	}
+	want, err := live.Introspect(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	if *updateFixtures {
+		rec.fixture.RecordedAt = ""
+		if err := rec.fixture.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replay, err := NewReplay(ctx, path)
+	if err != nil {
+		t.Fatalf("NewReplay() error = %v", err)
+	}
+	got, err := replay.Introspect(ctx, cfg)
+	if err != nil {
+		t.Fatalf("replayed Introspect() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed database differs from recorded run; re-run with -update")
+	}
+
+	if got.Name != "shop" || got.Version != "16.2" {
+		t.Errorf("metadata = %s %s, want shop 16.2", got.Name, got.Version)
+	}
+	if len(got.Tables) != 2 {
+		t.Fatalf("tables = %d, want 2", len(got.Tables))
+	}
+	users := got.Tables[1]
+	if len(users.Columns) != 2 || users.Comment != "Registered users" {
+		t.Errorf("users = %+v", users)
+	}
+	if len(users.IncomingForeignKeys) != 1 || users.IncomingForeignKeys[0].OnDelete != "CASCADE" {
+		t.Errorf("users incoming foreign keys = %+v", users.IncomingForeignKeys)
+	}
+	if len(got.Enums) != 1 || len(got.Enums[0].Values) != 2 {
+		t.Errorf("enums = %+v", got.Enums)
+	}
+}
+
+func TestFixture_ReplayUnrecordedQuery(t *testing.T) {
+	r := newReplayer(&Fixture{FormatVersion: fixtureFormatVersion})
+
+	_, err := r.Query(context.Background(), "SELECT 1")
+	if err == nil || !strings.Contains(err.Error(), "no recorded result") {
+		t.Errorf("Query() error = %v, want unrecorded query error", err)
+	}
+
+	var n int
+	if err := r.QueryRow(context.Background(), "SELECT 1").Scan(&n); err == nil {
+		t.Error("QueryRow().Scan() error = nil, want unrecorded query error")
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	got := normalizeSQL("\n\t\tSELECT a,\n\t\t       b\n\t\tFROM t\n\t")
+	if got != "SELECT a, b FROM t" {
+		t.Errorf("normalizeSQL() = %q", got)
+	}
+}
+
+func TestFixtureQuery_ServerError(t *testing.T) {
+	original := &pgconn.PgError{Severity: "ERROR", Code: "42P01", Message: `relation "geometry_columns" does not exist`}
+
+	var q FixtureQuery
+	q.setError(fmt.Errorf("query failed: %w", original))
+
+	err := q.err()
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "42P01" {
+		t.Fatalf("err() = %v, want SQLSTATE 42P01", err)
+	}
+	if err.Error() != original.Error() {
+		t.Errorf("err() = %q, want %q", err.Error(), original.Error())
+	}
+
+	q = FixtureQuery{}
+	q.setError(errors.New("conn closed"))
+	if err := q.err(); err == nil || err.Error() != "conn closed" || errors.As(err, &pgErr) {
+		t.Errorf("err() = %v, want plain conn closed", err)
+	}
+}
+
+// valueRows returns fixed Go values from Values, as pgx does for a live
+// connection.
+type valueRows struct {
+	*fixtureRows
+	values [][]any
+}
+
+func (r *valueRows) Values() ([]any, error) {
+	return r.values[r.pos], nil
+}
+
+// valueQuerier answers every query with the same columns and values.
+type valueQuerier struct {
+	columns []string
+	values  [][]any
+}
+
+func (q valueQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	fq := &FixtureQuery{Columns: q.columns, Rows: make([][]json.RawMessage, len(q.values))}
+	return &valueRows{fixtureRows: newFixtureRows(fq), values: q.values}, nil
+}
+
+func (q valueQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := q.Query(ctx, sql, args...)
+	return &fixtureRow{rows: rows, err: err}
+}
+
+func numeric(t *testing.T, s string) pgtype.Numeric {
+	t.Helper()
+	var n pgtype.Numeric
+	if err := n.Scan(s); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// TestFixture_ScanTypes records values of the types pgx returns for numeric,
+// float, timestamptz and interval columns, including NaN and infinities, and
+// checks they scan back into the same destinations after a save and replay.
+func TestFixture_ScanTypes(t *testing.T) {
+	placed := time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	interval := pgtype.Interval{Months: 1, Days: 2, Microseconds: 3723000000, Valid: true}
+
+	tests := []struct {
+		name  string
+		value any
+		dest  func() any
+		want  any
+	}{
+		{"numeric as numeric", numeric(t, "12.34"), func() any { return new(pgtype.Numeric) }, numeric(t, "12.34")},
+		{"numeric as float", numeric(t, "12.34"), func() any { return new(float64) }, 12.34},
+		{"numeric as string", numeric(t, "12.34"), func() any { return new(string) }, "12.34"},
+		{"numeric NaN", numeric(t, "NaN"), func() any { return new(pgtype.Numeric) }, pgtype.Numeric{NaN: true, Valid: true}},
+		{"numeric NaN as float", numeric(t, "NaN"), func() any { return new(float64) }, math.NaN()},
+		{"numeric infinity", numeric(t, "Infinity"), func() any { return new(pgtype.Numeric) }, numeric(t, "Infinity")},
+		{"numeric -infinity as float", numeric(t, "-Infinity"), func() any { return new(float64) }, math.Inf(-1)},
+		{"float NaN", math.NaN(), func() any { return new(float64) }, math.NaN()},
+		{"float infinity", math.Inf(1), func() any { return new(float64) }, math.Inf(1)},
+		{"real -infinity", float32(math.Inf(-1)), func() any { return new(float32) }, float32(math.Inf(-1))},
+		{"timestamptz", placed, func() any { return new(time.Time) }, placed},
+		{"timestamptz as pgtype", placed, func() any { return new(pgtype.Timestamptz) }, pgtype.Timestamptz{Time: placed, Valid: true}},
+		{"timestamptz infinity", pgtype.Infinity, func() any { return new(pgtype.Timestamptz) }, pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}},
+		{"timestamptz -infinity", pgtype.NegativeInfinity, func() any { return new(pgtype.Timestamptz) }, pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}},
+		{"interval", interval, func() any { return new(pgtype.Interval) }, interval},
+		{"interval as string", interval, func() any { return new(string) }, "1 mon 2 day 01:02:03.000000"},
+		{"text array", []any{"a", "b"}, func() any { return new([]string) }, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			rec := newRecorder(valueQuerier{columns: []string{"v"}, values: [][]any{{tt.value}}})
+
+			check := func(stage string, row pgx.Row) {
+				t.Helper()
+				dest := tt.dest()
+				if err := row.Scan(dest); err != nil {
+					t.Fatalf("%s: Scan() error = %v", stage, err)
+				}
+				got := reflect.ValueOf(dest).Elem().Interface()
+				if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", tt.want) {
+					t.Errorf("%s: got %+v, want %+v", stage, got, tt.want)
+				}
+			}
+			check("record", rec.QueryRow(ctx, "SELECT v"))
+
+			data, err := json.Marshal(rec.fixture)
+			if err != nil {
+				t.Fatalf("failed to marshal fixture: %v", err)
+			}
+			var saved Fixture
+			if err := json.Unmarshal(data, &saved); err != nil {
+				t.Fatal(err)
+			}
+			check("replay", newReplayer(&saved).QueryRow(ctx, "SELECT v"))
+		})
+	}
+}
+
+func TestFixture_UnencodableValue(t *testing.T) {
+	ctx := context.Background()
+	rec := newRecorder(valueQuerier{columns: []string{"v"}, values: [][]any{{complex(1, 2)}}})
+
+	live, err := newIntrospector(ctx, rec, nil)
+	if err == nil {
+		t.Fatalf("newIntrospector() = %+v, want error for a value that cannot be recorded", live)
+	}
+	if rec.err == nil {
+		t.Fatal("recorder did not mark the recording incomplete")
+	}
+
+	intro := &Introspector{pool: rec, recorder: rec}
+	if err := intro.SaveFixture(filepath.Join(t.TempDir(), "f.json")); err == nil {
+		t.Error("SaveFixture() saved an incomplete recording")
+	}
+}
@@ -9,29 +9,7 @@ import (
 )
 
 func (i *Introspector) introspectIndexes(ctx context.Context, schemaName, tableName string) ([]*schema.Index, error) {
-	query := `
-		SELECT
-			i.relname as index_name,
-			ix.indisunique as is_unique,
-			ix.indisprimary as is_primary,
-			am.amname as index_type,
-			pg_get_indexdef(ix.indexrelid) as index_def,
-			ARRAY(
-				SELECT a.attname
-				FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
-				JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
-				ORDER BY k.ord
-			) as columns,
-			pg_get_expr(ix.indpred, ix.indrelid) as predicate,
-			pg_get_expr(ix.indexprs, ix.indrelid) as expression
-		FROM pg_index ix
-		JOIN pg_class t ON t.oid = ix.indrelid
-		JOIN pg_class i ON i.oid = ix.indexrelid
-		JOIN pg_namespace n ON n.oid = t.relnamespace
-		JOIN pg_am am ON am.oid = i.relam
-		WHERE n.nspname = $1 AND t.relname = $2
-		ORDER BY i.relname
-	`
+	query := indexesQuery(i.caps)
 
 	rows, err := i.pool.Query(ctx, query, schemaName, tableName)
 	if err != nil {
@@ -42,29 +20,43 @@ func (i *Introspector) introspectIndexes(ctx context.Context, schemaName, tableN
 	var indexes []*schema.Index
 	for rows.Next() {
 		var (
-			indexName, indexType, indexDef string
-			isUnique, isPrimary            bool
-			columns                        []string
-			predicate, expression          *string
+			indexName, indexType, indexDef, comment string
+			isUnique, isPrimary                     bool
+			columns                                 []string
+			predicate, expression                   *string
+			keyDefs, keyOpClasses, keyCollations    []string
+			keyOptions                              []int32
+			canOrder, isValid, isReady              bool
+			nullsNotDistinct                        bool
+			reloptions                              []string
 		)
 
 		err := rows.Scan(
 			&indexName, &isUnique, &isPrimary, &indexType,
 			&indexDef, &columns, &predicate, &expression,
+			&keyDefs, &keyOptions, &keyOpClasses, &keyCollations,
+			&canOrder, &isValid, &isReady, &nullsNotDistinct,
+			&reloptions, &comment,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan index row: %w", err)
 		}
 
 		idx := &schema.Index{
-			IndexName:  indexName,
-			Unique:     isUnique,
-			Primary:    isPrimary,
-			IndexType:  indexType,
-			Columns:    columns,
-			Predicate:  predicate,
-			Expression: expression,
-			Definition: indexDef,
+			IndexName:         indexName,
+			Unique:            isUnique,
+			Primary:           isPrimary,
+			IndexType:         indexType,
+			Columns:           columns,
+			Predicate:         predicate,
+			Expression:        expression,
+			Keys:              buildIndexKeys(keyDefs, keyOptions, keyOpClasses, keyCollations, canOrder),
+			NullsNotDistinct:  nullsNotDistinct,
+			Invalid:           !isValid,
+			NotReady:          !isReady,
+			StorageParameters: parseReloptions(reloptions),
+			Comment:           comment,
+			Definition:        indexDef,
 		}
 
 		// Extract INCLUDE columns from definition if present
@@ -80,6 +72,41 @@ func (i *Introspector) introspectIndexes(ctx context.Context, schemaName, tableN
 	return indexes, nil
 }
 
+// buildIndexKeys assembles per-key details from the parallel arrays returned
+// by the index query. Sort and nulls order are only meaningful for access
+// methods that support ordering (btree).
+func buildIndexKeys(defs []string, options []int32, opclasses, collations []string, canOrder bool) []schema.IndexKey {
+	if len(defs) == 0 {
+		return nil
+	}
+
+	keys := make([]schema.IndexKey, len(defs))
+	for i, def := range defs {
+		keys[i] = schema.IndexKey{Column: def}
+
+		if canOrder && i < len(options) {
+			// indoption bit 0 = DESC, bit 1 = NULLS FIRST
+			keys[i].SortOrder = "ASC"
+			keys[i].NullsOrder = "LAST"
+			if options[i]&1 != 0 {
+				keys[i].SortOrder = "DESC"
+			}
+			if options[i]&2 != 0 {
+				keys[i].NullsOrder = "FIRST"
+			}
+		}
+
+		if i < len(opclasses) {
+			keys[i].OpClass = opclasses[i]
+		}
+		if i < len(collations) {
+			keys[i].Collation = collations[i]
+		}
+	}
+
+	return keys
+}
+
 // extractIncludeColumns parses INCLUDE columns from index definition
 func extractIncludeColumns(def string) []string {
 	// Look for INCLUDE (col1, col2, ...)
@@ -107,3 +134,64 @@ func extractIncludeColumns(def string) []string {
 	}
 	return cols
 }
+
+// indexesQuery selects indexes and their per-key details for a table.
+func indexesQuery(c capabilities) string {
+	return fmt.Sprintf(`
+			SELECT
+				i.relname as index_name,
+				ix.indisunique as is_unique,
+				ix.indisprimary as is_primary,
+				am.amname as index_type,
+				pg_get_indexdef(ix.indexrelid) as index_def,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as columns,
+				pg_get_expr(ix.indpred, ix.indrelid) as predicate,
+				pg_get_expr(ix.indexprs, ix.indrelid) as expression,
+				ARRAY(
+					SELECT pg_get_indexdef(ix.indexrelid, k.ord, true)
+					FROM generate_series(1, %[1]s) AS k(ord)
+					ORDER BY k.ord
+				) as key_defs,
+				ARRAY(
+					SELECT ix.indoption[k.ord - 1]::int
+					FROM generate_series(1, %[1]s) AS k(ord)
+					ORDER BY k.ord
+				) as key_options,
+				ARRAY(
+					SELECT COALESCE(opc.opcname, '')
+					FROM generate_series(1, %[1]s) AS k(ord)
+					LEFT JOIN pg_opclass opc ON opc.oid = ix.indclass[k.ord - 1]
+					ORDER BY k.ord
+				) as key_opclasses,
+				ARRAY(
+					SELECT CASE
+						WHEN ix.indcollation[k.ord - 1] <> 0
+						 AND ix.indcollation[k.ord - 1] <> COALESCE(a.attcollation, 100)
+						THEN COALESCE(coll.collname, '')
+						ELSE ''
+					END
+					FROM generate_series(1, %[1]s) AS k(ord)
+					LEFT JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = ix.indkey[k.ord - 1]
+					LEFT JOIN pg_collation coll ON coll.oid = ix.indcollation[k.ord - 1]
+					ORDER BY k.ord
+				) as key_collations,
+				pg_indexam_has_property(am.oid, 'can_order') as can_order,
+				ix.indisvalid as is_valid,
+				ix.indisready as is_ready,
+				%[2]s as nulls_not_distinct,
+				i.reloptions as reloptions,
+				COALESCE(obj_description(i.oid, 'pg_class'), '') as comment
+			FROM pg_index ix
+			JOIN pg_class t ON t.oid = ix.indrelid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			JOIN pg_am am ON am.oid = i.relam
+			WHERE n.nspname = $1 AND t.relname = $2
+			ORDER BY i.relname
+		`, c.indexKeyCount(), c.nullsNotDistinct())
+}
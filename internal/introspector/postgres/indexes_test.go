@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestBuildIndexKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		defs       []string
+		options    []int32
+		opclasses  []string
+		collations []string
+		canOrder   bool
+		expected   []schema.IndexKey
+	}{
+		{
+			name:     "empty",
+			expected: nil,
+		},
+		{
+			name:       "btree asc and desc nulls first",
+			defs:       []string{"created_at", "id"},
+			options:    []int32{3, 0},
+			opclasses:  []string{"timestamptz_ops", "int4_ops"},
+			collations: []string{"", ""},
+			canOrder:   true,
+			expected: []schema.IndexKey{
+				{Column: "created_at", SortOrder: "DESC", NullsOrder: "FIRST", OpClass: "timestamptz_ops"},
+				{Column: "id", SortOrder: "ASC", NullsOrder: "LAST", OpClass: "int4_ops"},
+			},
+		},
+		{
+			name:       "gin has no ordering",
+			defs:       []string{"lower(name)"},
+			options:    []int32{0},
+			opclasses:  []string{"gin_trgm_ops"},
+			collations: []string{"C"},
+			canOrder:   false,
+			expected: []schema.IndexKey{
+				{Column: "lower(name)", OpClass: "gin_trgm_ops", Collation: "C"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildIndexKeys(tt.defs, tt.options, tt.opclasses, tt.collations, tt.canOrder)
+
+			if len(result) != len(tt.expected) {
+				t.Fatalf("got %d keys, want %d", len(result), len(tt.expected))
+			}
+
+			for i, key := range result {
+				if key != tt.expected[i] {
+					t.Errorf("key[%d] = %+v, want %+v", i, key, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseReloptions(t *testing.T) {
+	result := parseReloptions([]string{"fillfactor=70", "fastupdate=off", "m=16"})
+
+	expected := map[string]string{"fillfactor": "70", "fastupdate": "off", "m": "16"}
+	if len(result) != len(expected) {
+		t.Fatalf("got %d options, want %d", len(result), len(expected))
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("parseReloptions()[%q] = %q, want %q", k, result[k], v)
+		}
+	}
+
+	if parseReloptions(nil) != nil {
+		t.Error("expected nil for empty reloptions")
+	}
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// inheritanceLink is a single parent/child row from pg_inherits.
+type inheritanceLink struct {
+	Child  schema.TableRef
+	Parent schema.TableRef
+}
+
+func (i *Introspector) introspectInheritance(ctx context.Context, schemas []string) ([]inheritanceLink, error) {
+	// Declarative partitions are also tracked in pg_inherits; skip them so
+	// only classic INHERITS hierarchies are reported.
+	query := `
+		SELECT
+			cn.nspname as child_schema,
+			c.relname as child_table,
+			pn.nspname as parent_schema,
+			p.relname as parent_table
+		FROM pg_inherits inh
+		JOIN pg_class c ON c.oid = inh.inhrelid
+		JOIN pg_namespace cn ON cn.oid = c.relnamespace
+		JOIN pg_class p ON p.oid = inh.inhparent
+		JOIN pg_namespace pn ON pn.oid = p.relnamespace
+		WHERE (cn.nspname = ANY($1) OR pn.nspname = ANY($1))
+		  AND c.relkind = 'r'
+		  AND p.relkind = 'r'
+		  AND NOT c.relispartition
+		ORDER BY cn.nspname, c.relname, inh.inhseqno
+	`
+
+	rows, err := i.pool.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inheritance: %w", err)
+	}
+	defer rows.Close()
+
+	var links []inheritanceLink
+	for rows.Next() {
+		var link inheritanceLink
+		err := rows.Scan(
+			&link.Child.SchemaName, &link.Child.TableName,
+			&link.Parent.SchemaName, &link.Parent.TableName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inheritance row: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inheritance rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// applyInheritance populates InheritsFrom/InheritedBy on the introspected tables.
+func applyInheritance(tables []*schema.Table, links []inheritanceLink) {
+	tableMap := make(map[string]*schema.Table)
+	for _, t := range tables {
+		tableMap[t.SchemaName+"."+t.TableName] = t
+	}
+
+	for _, link := range links {
+		if child, ok := tableMap[link.Child.SchemaName+"."+link.Child.TableName]; ok {
+			child.InheritsFrom = append(child.InheritsFrom, link.Parent)
+		}
+		if parent, ok := tableMap[link.Parent.SchemaName+"."+link.Parent.TableName]; ok {
+			parent.InheritedBy = append(parent.InheritedBy, link.Child)
+		}
+	}
+}
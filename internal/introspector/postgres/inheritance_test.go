@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestApplyInheritance(t *testing.T) {
+	events := &schema.Table{TableName: "events", SchemaName: "public"}
+	clicks := &schema.Table{TableName: "click_events", SchemaName: "public"}
+	views := &schema.Table{TableName: "view_events", SchemaName: "public"}
+
+	links := []inheritanceLink{
+		{
+			Child:  schema.TableRef{TableName: "click_events", SchemaName: "public"},
+			Parent: schema.TableRef{TableName: "events", SchemaName: "public"},
+		},
+		{
+			Child:  schema.TableRef{TableName: "view_events", SchemaName: "public"},
+			Parent: schema.TableRef{TableName: "events", SchemaName: "public"},
+		},
+		{
+			// Parent outside the introspected schemas
+			Child:  schema.TableRef{TableName: "events", SchemaName: "public"},
+			Parent: schema.TableRef{TableName: "base_log", SchemaName: "legacy"},
+		},
+	}
+
+	applyInheritance([]*schema.Table{events, clicks, views}, links)
+
+	if len(events.InheritedBy) != 2 {
+		t.Errorf("expected events to have 2 children, got %v", events.InheritedBy)
+	}
+	if len(events.InheritsFrom) != 1 || events.InheritsFrom[0].SchemaName != "legacy" {
+		t.Errorf("expected events to inherit from legacy.base_log, got %v", events.InheritsFrom)
+	}
+	if len(clicks.InheritsFrom) != 1 || clicks.InheritsFrom[0].TableName != "events" {
+		t.Errorf("expected click_events to inherit from events, got %v", clicks.InheritsFrom)
+	}
+	if len(views.InheritedBy) != 0 {
+		t.Errorf("expected view_events to have no children, got %v", views.InheritedBy)
+	}
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func (i *Introspector) introspectNamespaces(ctx context.Context, schemas []string, redactComments bool) ([]*schema.Namespace, error) {
+	query := namespacesQuery(i.caps)
+
+	rows, err := i.pool.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var namespaces []*schema.Namespace
+	for rows.Next() {
+		ns := &schema.Namespace{}
+		c := &ns.ObjectCounts
+
+		err := rows.Scan(
+			&ns.SchemaName, &ns.Owner, &ns.Comment,
+			&c.Tables, &c.Views, &c.MaterializedViews, &c.ForeignTables, &c.Sequences,
+			&c.Functions, &c.Procedures, &c.Enums, &c.Types,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schema row: %w", err)
+		}
+
+		if redactComments {
+			ns.Comment = ""
+		}
+
+		namespaces = append(namespaces, ns)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schema rows: %w", err)
+	}
+
+	return namespaces, nil
+}
+
+func (i *Introspector) introspectDefaultPrivileges(ctx context.Context, schemaName string) ([]schema.DefaultPrivilege, error) {
+	query := `
+		SELECT
+			pg_get_userbyid(d.defaclrole) as role,
+			d.defaclobjtype::text as object_type,
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END as grantee,
+			array_agg(a.privilege_type ORDER BY a.privilege_type) as privileges
+		FROM pg_default_acl d
+		JOIN pg_namespace n ON n.oid = d.defaclnamespace
+		CROSS JOIN LATERAL aclexplode(d.defaclacl) a
+		WHERE n.nspname = $1
+		GROUP BY d.defaclrole, d.defaclobjtype, a.grantee
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := i.pool.Query(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query default privileges: %w", err)
+	}
+	defer rows.Close()
+
+	var privs []schema.DefaultPrivilege
+	for rows.Next() {
+		var p schema.DefaultPrivilege
+		var objType string
+		if err := rows.Scan(&p.Role, &objType, &p.Grantee, &p.Privileges); err != nil {
+			return nil, fmt.Errorf("failed to scan default privilege row: %w", err)
+		}
+		p.ObjectType = mapDefaultACLObjectType(objType)
+		privs = append(privs, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating default privilege rows: %w", err)
+	}
+
+	return privs, nil
+}
+
+func mapDefaultACLObjectType(char string) string {
+	switch char {
+	case "r":
+		return "tables"
+	case "S":
+		return "sequences"
+	case "f":
+		return "functions"
+	case "T":
+		return "types"
+	case "n":
+		return "schemas"
+	default:
+		return char
+	}
+}
+
+// namespacesQuery selects schema metadata and object counts by kind.
+func namespacesQuery(c capabilities) string {
+	return fmt.Sprintf(`
+			SELECT
+				n.nspname as schema_name,
+				pg_get_userbyid(n.nspowner) as owner,
+				COALESCE(obj_description(n.oid, 'pg_namespace'), '') as comment,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind IN ('r', 'p'))::int as tables,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'v')::int as views,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'm')::int as materialized_views,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'f')::int as foreign_tables,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'S')::int as sequences,
+				(SELECT count(*) FROM pg_proc p WHERE p.pronamespace = n.oid AND %[1]s = 'f')::int as functions,
+				(SELECT count(*) FROM pg_proc p WHERE p.pronamespace = n.oid AND %[1]s = 'p')::int as procedures,
+				(SELECT count(*) FROM pg_type t WHERE t.typnamespace = n.oid AND t.typtype = 'e')::int as enums,
+				(
+					SELECT count(*)
+					FROM pg_type t
+					WHERE t.typnamespace = n.oid
+					  AND t.typtype IN ('c', 'd', 'r')
+					  AND NOT EXISTS (
+						SELECT 1 FROM pg_class c WHERE c.reltype = t.oid AND c.relkind IN ('r', 'v', 'm', 'p')
+					  )
+				)::int as types
+			FROM pg_namespace n
+			WHERE n.nspname = ANY($1)
+			ORDER BY n.nspname
+		`, c.routineKind())
+}
@@ -2,9 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nenorrell/X-Rai/internal/config"
 	"github.com/nenorrell/X-Rai/internal/schema"
@@ -12,13 +16,55 @@ import (
 
 // Introspector implements database introspection for PostgreSQL.
 type Introspector struct {
-	pool         *pgxpool.Pool
-	databaseName string
-	version      string
+	pool          querier
+	closeFn       func()
+	recorder      *recorder
+	databaseName  string
+	version       string
+	versionString string
+	caps          capabilities
 }
 
 // New creates a new PostgreSQL introspector.
 func New(ctx context.Context, dsn string) (*Introspector, error) {
+	pool, err := connect(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return newIntrospector(ctx, pool, pool.Close)
+}
+
+// NewRecording creates a PostgreSQL introspector that records every catalog
+// query and its result. Call SaveFixture after introspection to persist them.
+func NewRecording(ctx context.Context, dsn string) (*Introspector, error) {
+	pool, err := connect(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := newRecorder(pool)
+	i, err := newIntrospector(ctx, rec, pool.Close)
+	if err != nil {
+		return nil, err
+	}
+	i.recorder = rec
+
+	return i, nil
+}
+
+// NewReplay creates a PostgreSQL introspector that serves catalog queries
+// from a recorded fixture file instead of a live database.
+func NewReplay(ctx context.Context, path string) (*Introspector, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newIntrospector(ctx, newReplayer(fixture), nil)
+}
+
+func connect(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -29,20 +75,37 @@ func New(ctx context.Context, dsn string) (*Introspector, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	i := &Introspector{pool: pool}
+	return pool, nil
+}
+
+func newIntrospector(ctx context.Context, q querier, closeFn func()) (*Introspector, error) {
+	i := &Introspector{pool: q, closeFn: closeFn}
 
 	if err := i.fetchMetadata(ctx); err != nil {
-		pool.Close()
+		i.Close()
 		return nil, err
 	}
 
 	return i, nil
 }
 
+// SaveFixture writes the queries captured by a recording introspector.
+func (i *Introspector) SaveFixture(path string) error {
+	if i.recorder == nil {
+		return fmt.Errorf("introspector is not recording")
+	}
+
+	i.recorder.mu.Lock()
+	defer i.recorder.mu.Unlock()
+	if i.recorder.err != nil {
+		return fmt.Errorf("recording is incomplete: %w", i.recorder.err)
+	}
+	return i.recorder.fixture.Save(path)
+}
+
 func (i *Introspector) fetchMetadata(ctx context.Context) error {
 	row := i.pool.QueryRow(ctx, "SELECT current_database(), version()")
-	var fullVersion string
-	if err := row.Scan(&i.databaseName, &fullVersion); err != nil {
+	if err := row.Scan(&i.databaseName, &i.versionString); err != nil {
 		return fmt.Errorf("failed to fetch database metadata: %w", err)
 	}
 
@@ -53,6 +116,18 @@ func (i *Introspector) fetchMetadata(ctx context.Context) error {
 		i.version = ""
 	}
 
+	// Numeric version drives catalog query variants
+	var versionNum string
+	row = i.pool.QueryRow(ctx, "SHOW server_version_num")
+	if err := row.Scan(&versionNum); err != nil {
+		return fmt.Errorf("failed to fetch server_version_num: %w", err)
+	}
+	n, err := strconv.Atoi(versionNum)
+	if err != nil {
+		return fmt.Errorf("failed to parse server_version_num %q: %w", versionNum, err)
+	}
+	i.caps = newCapabilities(n)
+
 	return nil
 }
 
@@ -68,7 +143,9 @@ func (i *Introspector) Version() string {
 
 // Close closes the database connection pool.
 func (i *Introspector) Close() error {
-	i.pool.Close()
+	if i.closeFn != nil {
+		i.closeFn()
+	}
 	return nil
 }
 
@@ -79,6 +156,29 @@ func (i *Introspector) Introspect(ctx context.Context, cfg *config.Config) (*sch
 		Engine:  "postgresql",
 		Version: i.version,
 		Schemas: cfg.Schemas,
+
+		Unavailable: i.caps.unavailableFeatures(),
+	}
+
+	// Introspect schema metadata
+	namespaces, err := i.introspectNamespaces(ctx, cfg.Schemas, cfg.RedactComments)
+	if err != nil {
+		if err := markUnavailable(db, "schemas", err); err != nil {
+			return nil, err
+		}
+	}
+	db.Namespaces = namespaces
+
+	// Fetch default privileges for each schema
+	for _, ns := range namespaces {
+		privs, err := i.introspectDefaultPrivileges(ctx, ns.SchemaName)
+		if err != nil {
+			if err := markUnavailable(db, "default_privileges", err); err != nil {
+				return nil, err
+			}
+			break
+		}
+		ns.DefaultPrivileges = privs
 	}
 
 	// Introspect tables
@@ -149,6 +249,35 @@ func (i *Introspector) Introspect(ctx context.Context, cfg *config.Config) (*sch
 	// Build incoming foreign key references
 	i.buildIncomingForeignKeys(db.Tables)
 
+	// Attach PostGIS metadata when the extension is installed
+	postgisSchema, err := i.extensionSchema(ctx, "postgis")
+	if err != nil {
+		if err := markUnavailable(db, "spatial", err); err != nil {
+			return nil, err
+		}
+	}
+	if postgisSchema != "" {
+		spatial, err := i.introspectSpatialColumns(ctx, postgisSchema, cfg.Schemas)
+		if err != nil {
+			if err := markUnavailable(db, "spatial", err); err != nil {
+				return nil, err
+			}
+		}
+		applySpatialInfo(db.Tables, spatial)
+	}
+
+	// Detect pgvector columns and their ANN indexes
+	applyVectorInfo(db.Tables)
+
+	// Link INHERITS parents and children
+	links, err := i.introspectInheritance(ctx, cfg.Schemas)
+	if err != nil {
+		if err := markUnavailable(db, "inheritance", err); err != nil {
+			return nil, err
+		}
+	}
+	applyInheritance(db.Tables, links)
+
 	// Introspect views if enabled
 	if cfg.IncludeViews {
 		views, err := i.introspectViews(ctx, cfg.Schemas, cfg.RedactDefinitions, cfg.RedactComments)
@@ -188,9 +317,60 @@ func (i *Introspector) Introspect(ctx context.Context, cfg *config.Config) (*sch
 	}
 	db.Types = types
 
+	// Introspect collations and text search configuration
+	collations, err := i.introspectCollations(ctx, cfg.Schemas, db.Tables)
+	if err != nil {
+		if err := markUnavailable(db, "collations", err); err != nil {
+			return nil, err
+		}
+	}
+	db.Collations = collations
+
+	tsConfigs, tsDicts, err := i.introspectTextSearch(ctx, cfg.Schemas, db.Tables)
+	if err != nil {
+		if err := markUnavailable(db, "text_search", err); err != nil {
+			return nil, err
+		}
+	}
+	db.TextSearchConfigs = tsConfigs
+	db.TextSearchDictionaries = tsDicts
+
+	// Capture server settings and database-level properties
+	env, err := i.introspectEnvironment(ctx)
+	if err != nil {
+		if err := markUnavailable(db, "environment", err); err != nil {
+			return nil, err
+		}
+	}
+	db.Environment = env
+
 	return db, nil
 }
 
+// markUnavailable records an optional artifact whose catalog objects do not
+// exist on this server (undefined table, column or function) so the run can
+// continue and the manifest reports the gap. Any other error is returned.
+func markUnavailable(db *schema.Database, feature string, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || !featureAbsentCodes[pgErr.Code] {
+		return fmt.Errorf("failed to introspect %s: %w", feature, err)
+	}
+
+	db.Unavailable = append(db.Unavailable, schema.UnavailableFeature{
+		Feature: feature,
+		Reason:  err.Error(),
+	})
+	return nil
+}
+
+// featureAbsentCodes are the SQLSTATEs raised when a catalog object an
+// optional query relies on does not exist.
+var featureAbsentCodes = map[string]bool{
+	"42P01": true, // undefined_table
+	"42703": true, // undefined_column
+	"42883": true, // undefined_function
+}
+
 // buildIncomingForeignKeys populates IncomingForeignKeys for each table
 func (i *Introspector) buildIncomingForeignKeys(tables []*schema.Table) {
 	tableMap := make(map[string]*schema.Table)
@@ -204,17 +384,22 @@ func (i *Introspector) buildIncomingForeignKeys(tables []*schema.Table) {
 			targetKey := fk.ToSchema + "." + fk.ToTable
 			if targetTable, ok := tableMap[targetKey]; ok {
 				incoming := &schema.ForeignKey{
-					ConstraintName: fk.ConstraintName,
-					FromSchema:     t.SchemaName,
-					FromTable:      t.TableName,
-					FromColumns:    fk.FromColumns,
-					ToSchema:       fk.ToSchema,
-					ToTable:        fk.ToTable,
-					ToColumns:      fk.ToColumns,
-					OnUpdate:       fk.OnUpdate,
-					OnDelete:       fk.OnDelete,
-					Nullable:       fk.Nullable,
-					Cardinality:    fk.Cardinality,
+					ConstraintName:     fk.ConstraintName,
+					FromSchema:         t.SchemaName,
+					FromTable:          t.TableName,
+					FromColumns:        fk.FromColumns,
+					ToSchema:           fk.ToSchema,
+					ToTable:            fk.ToTable,
+					ToColumns:          fk.ToColumns,
+					OnUpdate:           fk.OnUpdate,
+					OnDelete:           fk.OnDelete,
+					OnDeleteSetColumns: fk.OnDeleteSetColumns,
+					MatchType:          fk.MatchType,
+					Deferrable:         fk.Deferrable,
+					InitiallyDeferred:  fk.InitiallyDeferred,
+					NotValid:           fk.NotValid,
+					Nullable:           fk.Nullable,
+					Cardinality:        fk.Cardinality,
 				}
 				targetTable.IncomingForeignKeys = append(targetTable.IncomingForeignKeys, incoming)
 			}
@@ -233,3 +418,17 @@ func scanNullString(rows pgx.Rows, dest *string) error {
 	}
 	return nil
 }
+
+// parseReloptions converts a pg_class.reloptions array ("key=value") into a map.
+func parseReloptions(opts []string) map[string]string {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(opts))
+	for _, opt := range opts {
+		key, value, _ := strings.Cut(opt, "=")
+		result[key] = value
+	}
+	return result
+}
@@ -8,34 +8,7 @@ import (
 )
 
 func (i *Introspector) introspectForeignKeys(ctx context.Context, schemaName, tableName string) ([]*schema.ForeignKey, error) {
-	query := `
-		SELECT
-			con.conname as constraint_name,
-			ARRAY(
-				SELECT a.attname
-				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
-				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
-				ORDER BY k.ord
-			) as from_columns,
-			nf.nspname as to_schema,
-			cf.relname as to_table,
-			ARRAY(
-				SELECT a.attname
-				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
-				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
-				ORDER BY k.ord
-			) as to_columns,
-			con.confupdtype::text as on_update,
-			con.confdeltype::text as on_delete
-		FROM pg_constraint con
-		JOIN pg_class c ON c.oid = con.conrelid
-		JOIN pg_namespace n ON n.oid = c.relnamespace
-		JOIN pg_class cf ON cf.oid = con.confrelid
-		JOIN pg_namespace nf ON nf.oid = cf.relnamespace
-		WHERE n.nspname = $1 AND c.relname = $2
-		  AND con.contype = 'f'
-		ORDER BY con.conname
-	`
+	query := foreignKeysQuery(i.caps)
 
 	rows, err := i.pool.Query(ctx, query, schemaName, tableName)
 	if err != nil {
@@ -49,26 +22,39 @@ func (i *Introspector) introspectForeignKeys(ctx context.Context, schemaName, ta
 			constraintName, toSchema, toTable string
 			fromColumns, toColumns            []string
 			onUpdateChar, onDeleteChar        string
+			onDeleteSetColumns                []string
+			matchTypeChar                     string
+			deferrable, deferred, validated   bool
 		)
 
 		err := rows.Scan(
 			&constraintName, &fromColumns, &toSchema, &toTable, &toColumns,
-			&onUpdateChar, &onDeleteChar,
+			&onUpdateChar, &onDeleteChar, &onDeleteSetColumns, &matchTypeChar,
+			&deferrable, &deferred, &validated,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan foreign key row: %w", err)
 		}
 
 		fk := &schema.ForeignKey{
-			ConstraintName: constraintName,
-			FromSchema:     schemaName,
-			FromTable:      tableName,
-			FromColumns:    fromColumns,
-			ToSchema:       toSchema,
-			ToTable:        toTable,
-			ToColumns:      toColumns,
-			OnUpdate:       mapFKAction(onUpdateChar),
-			OnDelete:       mapFKAction(onDeleteChar),
+			ConstraintName:    constraintName,
+			FromSchema:        schemaName,
+			FromTable:         tableName,
+			FromColumns:       fromColumns,
+			ToSchema:          toSchema,
+			ToTable:           toTable,
+			ToColumns:         toColumns,
+			OnUpdate:          mapFKAction(onUpdateChar),
+			OnDelete:          mapFKAction(onDeleteChar),
+			MatchType:         mapFKMatchType(matchTypeChar),
+			Deferrable:        deferrable,
+			InitiallyDeferred: deferred,
+			NotValid:          !validated,
+		}
+
+		// Column lists only apply to ON DELETE SET NULL / SET DEFAULT
+		if len(onDeleteSetColumns) > 0 {
+			fk.OnDeleteSetColumns = onDeleteSetColumns
 		}
 
 		fks = append(fks, fk)
@@ -141,3 +127,58 @@ func mapFKAction(char string) string {
 		return ""
 	}
 }
+
+func mapFKMatchType(char string) string {
+	switch char {
+	case "f":
+		return "FULL"
+	case "p":
+		return "PARTIAL"
+	case "s":
+		return "SIMPLE"
+	default:
+		return ""
+	}
+}
+
+// foreignKeysQuery selects outgoing foreign keys for a table.
+func foreignKeysQuery(c capabilities) string {
+	return fmt.Sprintf(`
+			SELECT
+				con.conname as constraint_name,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as from_columns,
+				nf.nspname as to_schema,
+				cf.relname as to_table,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as to_columns,
+				con.confupdtype::text as on_update,
+				con.confdeltype::text as on_delete,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(%s) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as on_delete_set_columns,
+				con.confmatchtype::text as match_type,
+				con.condeferrable as deferrable,
+				con.condeferred as initially_deferred,
+				con.convalidated as validated
+			FROM pg_constraint con
+			JOIN pg_class c ON c.oid = con.conrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			JOIN pg_class cf ON cf.oid = con.confrelid
+			JOIN pg_namespace nf ON nf.oid = cf.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2
+			  AND con.contype = 'f'
+			ORDER BY con.conname
+		`, c.fkSetColumns())
+}
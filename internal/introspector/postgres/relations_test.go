@@ -0,0 +1,47 @@
+package postgres
+
+import "testing"
+
+func TestMapFKAction(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a", "NO ACTION"},
+		{"r", "RESTRICT"},
+		{"c", "CASCADE"},
+		{"n", "SET NULL"},
+		{"d", "SET DEFAULT"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := mapFKAction(tt.input)
+			if result != tt.expected {
+				t.Errorf("mapFKAction(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMapFKMatchType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f", "FULL"},
+		{"p", "PARTIAL"},
+		{"s", "SIMPLE"},
+		{"x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := mapFKMatchType(tt.input)
+			if result != tt.expected {
+				t.Errorf("mapFKMatchType(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
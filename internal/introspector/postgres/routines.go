@@ -9,32 +9,7 @@ import (
 )
 
 func (i *Introspector) introspectRoutines(ctx context.Context, schemas []string, redactDef bool) ([]*schema.Routine, error) {
-	query := `
-		SELECT
-			n.nspname as schema_name,
-			p.proname as routine_name,
-			CASE p.prokind
-				WHEN 'f' THEN 'function'
-				WHEN 'p' THEN 'procedure'
-				WHEN 'a' THEN 'aggregate'
-				WHEN 'w' THEN 'window'
-				ELSE 'function'
-			END as routine_type,
-			l.lanname as language,
-			pg_get_function_result(p.oid) as return_type,
-			pg_get_functiondef(p.oid) as definition,
-			COALESCE(obj_description(p.oid, 'pg_proc'), '') as comment,
-			p.proargnames as arg_names,
-			p.proargtypes::regtype[]::text[] as arg_types,
-			p.proargmodes::text[] as arg_modes,
-			p.pronargdefaults as num_defaults
-		FROM pg_proc p
-		JOIN pg_namespace n ON n.oid = p.pronamespace
-		JOIN pg_language l ON l.oid = p.prolang
-		WHERE n.nspname = ANY($1)
-		  AND p.prokind IN ('f', 'p')
-		ORDER BY n.nspname, p.proname
-	`
+	query := routinesQuery(i.caps)
 
 	rows, err := i.pool.Query(ctx, query, schemas)
 	if err != nil {
@@ -137,3 +112,33 @@ func derefString(s *string) string {
 	}
 	return *s
 }
+
+// routinesQuery selects functions and procedures.
+func routinesQuery(c capabilities) string {
+	return fmt.Sprintf(`
+			SELECT
+				n.nspname as schema_name,
+				p.proname as routine_name,
+				CASE %[1]s
+					WHEN 'f' THEN 'function'
+					WHEN 'p' THEN 'procedure'
+					WHEN 'a' THEN 'aggregate'
+					WHEN 'w' THEN 'window'
+					ELSE 'function'
+				END as routine_type,
+				l.lanname as language,
+				pg_get_function_result(p.oid) as return_type,
+				pg_get_functiondef(p.oid) as definition,
+				COALESCE(obj_description(p.oid, 'pg_proc'), '') as comment,
+				p.proargnames as arg_names,
+				p.proargtypes::regtype[]::text[] as arg_types,
+				p.proargmodes::text[] as arg_modes,
+				p.pronargdefaults as num_defaults
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			JOIN pg_language l ON l.oid = p.prolang
+			WHERE n.nspname = ANY($1)
+			  AND %[1]s IN ('f', 'p')
+			ORDER BY n.nspname, p.proname
+		`, c.routineKind())
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// extensionSchema returns the schema the named extension is installed in,
+// or "" if it is not installed.
+func (i *Introspector) extensionSchema(ctx context.Context, name string) (string, error) {
+	query := `
+		SELECT COALESCE((
+			SELECT n.nspname::text
+			FROM pg_extension e
+			JOIN pg_namespace n ON n.oid = e.extnamespace
+			WHERE e.extname = $1
+		), '')
+	`
+
+	var schemaName string
+	if err := i.pool.QueryRow(ctx, query, name).Scan(&schemaName); err != nil {
+		return "", fmt.Errorf("failed to check extension %s: %w", name, err)
+	}
+	return schemaName, nil
+}
+
+// introspectSpatialColumns reads PostGIS geometry/geography column metadata,
+// keyed by schema.table.column. The metadata views live in the schema PostGIS
+// was installed in, which need not be on the search_path.
+func (i *Introspector) introspectSpatialColumns(ctx context.Context, postgisSchema string, schemas []string) (map[string]*schema.SpatialInfo, error) {
+	geometryColumns := pgx.Identifier{postgisSchema, "geometry_columns"}.Sanitize()
+	geographyColumns := pgx.Identifier{postgisSchema, "geography_columns"}.Sanitize()
+	query := `
+		SELECT
+			f_table_schema::text,
+			f_table_name::text,
+			f_geometry_column::text,
+			'geometry' as kind,
+			type::text,
+			srid,
+			coord_dimension
+		FROM ` + geometryColumns + `
+		WHERE f_table_schema = ANY($1)
+		UNION ALL
+		SELECT
+			f_table_schema::text,
+			f_table_name::text,
+			f_geography_column::text,
+			'geography' as kind,
+			type::text,
+			srid,
+			coord_dimension
+		FROM ` + geographyColumns + `
+		WHERE f_table_schema = ANY($1)
+	`
+
+	rows, err := i.pool.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spatial columns: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*schema.SpatialInfo)
+	for rows.Next() {
+		var schemaName, tableName, columnName string
+		info := &schema.SpatialInfo{}
+
+		err := rows.Scan(
+			&schemaName, &tableName, &columnName,
+			&info.Kind, &info.GeometryType, &info.SRID, &info.CoordDimension,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spatial column row: %w", err)
+		}
+
+		result[schemaName+"."+tableName+"."+columnName] = info
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating spatial column rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// applySpatialInfo attaches spatial metadata to columns and flags indexes
+// that can serve spatial predicates (GiST and SP-GiST on a spatial column).
+// BRIN only prunes by bounding box and is not treated as a spatial index.
+func applySpatialInfo(tables []*schema.Table, spatial map[string]*schema.SpatialInfo) {
+	for _, t := range tables {
+		spatialCols := make(map[string]bool)
+		for _, col := range t.Columns {
+			if info, ok := spatial[t.SchemaName+"."+t.TableName+"."+col.ColumnName]; ok {
+				col.Spatial = info
+				spatialCols[col.ColumnName] = true
+			}
+		}
+
+		if len(spatialCols) == 0 {
+			continue
+		}
+
+		for _, idx := range t.Indexes {
+			switch idx.IndexType {
+			case "gist", "spgist":
+			default:
+				continue
+			}
+			for _, col := range idx.Columns {
+				if spatialCols[col] {
+					idx.Spatial = true
+					break
+				}
+			}
+		}
+	}
+}
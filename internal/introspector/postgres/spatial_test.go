@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestApplySpatialInfo(t *testing.T) {
+	geom := &schema.Column{ColumnName: "geom"}
+	table := &schema.Table{
+		SchemaName: "public",
+		TableName:  "parcels",
+		Columns:    []*schema.Column{{ColumnName: "id"}, geom},
+		Indexes: []*schema.Index{
+			{IndexName: "parcels_geom_gist", IndexType: "gist", Columns: []string{"geom"}},
+			{IndexName: "parcels_geom_spgist", IndexType: "spgist", Columns: []string{"geom"}},
+			{IndexName: "parcels_geom_brin", IndexType: "brin", Columns: []string{"geom"}},
+			{IndexName: "parcels_id_gist", IndexType: "gist", Columns: []string{"id"}},
+		},
+	}
+	info := &schema.SpatialInfo{Kind: "geometry", GeometryType: "POLYGON", SRID: 4326, CoordDimension: 2}
+
+	applySpatialInfo([]*schema.Table{table}, map[string]*schema.SpatialInfo{"public.parcels.geom": info})
+
+	if geom.Spatial != info {
+		t.Errorf("geom.Spatial = %+v, want %+v", geom.Spatial, info)
+	}
+
+	expected := map[string]bool{
+		"parcels_geom_gist":   true,
+		"parcels_geom_spgist": true,
+		"parcels_geom_brin":   false,
+		"parcels_id_gist":     false,
+	}
+	for _, idx := range table.Indexes {
+		if idx.Spatial != expected[idx.IndexName] {
+			t.Errorf("%s Spatial = %v, want %v", idx.IndexName, idx.Spatial, expected[idx.IndexName])
+		}
+	}
+}
+
+func TestIntrospectSpatialColumns_ExtensionSchema(t *testing.T) {
+	ctx := context.Background()
+	rec := newRecorder(fakeCatalog{})
+	i := &Introspector{pool: rec}
+
+	if _, err := i.introspectSpatialColumns(ctx, "gis", []string{"public"}); err != nil {
+		t.Fatalf("introspectSpatialColumns() error = %v", err)
+	}
+
+	if len(rec.fixture.Queries) != 1 {
+		t.Fatalf("recorded %d queries, want 1", len(rec.fixture.Queries))
+	}
+	sql := rec.fixture.Queries[0].SQL
+	for _, view := range []string{`"gis"."geometry_columns"`, `"gis"."geography_columns"`} {
+		if !strings.Contains(sql, view) {
+			t.Errorf("query does not read %s: %s", view, sql)
+		}
+	}
+}
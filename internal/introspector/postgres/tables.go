@@ -13,8 +13,20 @@ func (i *Introspector) introspectTables(ctx context.Context, schemas []string) (
 		SELECT
 			t.table_schema,
 			t.table_name,
-			COALESCE(obj_description((t.table_schema || '.' || t.table_name)::regclass), '') as table_comment
+			COALESCE(obj_description((t.table_schema || '.' || t.table_name)::regclass), '') as table_comment,
+			c.relpersistence::text as persistence,
+			COALESCE(ts.spcname, '') as tablespace,
+			COALESCE(am.amname, '') as access_method,
+			c.reloptions as reloptions,
+			tc.reloptions as toast_reloptions,
+			c.relreplident::text as replica_identity,
+			c.relrowsecurity as rls_enabled,
+			c.relforcerowsecurity as rls_forced
 		FROM information_schema.tables t
+		JOIN pg_class c ON c.oid = (quote_ident(t.table_schema) || '.' || quote_ident(t.table_name))::regclass
+		LEFT JOIN pg_tablespace ts ON ts.oid = c.reltablespace
+		LEFT JOIN pg_am am ON am.oid = c.relam
+		LEFT JOIN pg_class tc ON tc.oid = c.reltoastrelid
 		WHERE t.table_schema = ANY($1)
 		  AND t.table_type = 'BASE TABLE'
 		ORDER BY t.table_schema, t.table_name
@@ -28,16 +40,36 @@ func (i *Introspector) introspectTables(ctx context.Context, schemas []string) (
 
 	var tables []*schema.Table
 	for rows.Next() {
-		var schemaName, tableName, comment string
-		if err := rows.Scan(&schemaName, &tableName, &comment); err != nil {
+		var (
+			schemaName, tableName, comment      string
+			persistence, tablespace, accessMeth string
+			replicaIdentity                     string
+			reloptions, toastReloptions         []string
+			rlsEnabled, rlsForced               bool
+		)
+
+		err := rows.Scan(
+			&schemaName, &tableName, &comment,
+			&persistence, &tablespace, &accessMeth,
+			&reloptions, &toastReloptions, &replicaIdentity,
+			&rlsEnabled, &rlsForced,
+		)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan table row: %w", err)
 		}
 
 		tables = append(tables, &schema.Table{
-			TableName:  tableName,
-			SchemaName: schemaName,
-			TableType:  "BASE TABLE",
-			Comment:    comment,
+			TableName:         tableName,
+			SchemaName:        schemaName,
+			TableType:         "BASE TABLE",
+			Comment:           comment,
+			Persistence:       mapPersistence(persistence),
+			Tablespace:        tablespace,
+			AccessMethod:      accessMeth,
+			StorageParameters: mergeToastReloptions(parseReloptions(reloptions), toastReloptions),
+			ReplicaIdentity:   mapReplicaIdentity(replicaIdentity),
+			RLSEnabled:        rlsEnabled,
+			RLSForced:         rlsForced,
 		})
 	}
 
@@ -70,6 +102,51 @@ func (i *Introspector) introspectRowCount(ctx context.Context, schemaName, table
 	return &count, nil
 }
 
+func mapPersistence(char string) string {
+	switch char {
+	case "p":
+		return "permanent"
+	case "u":
+		return "unlogged"
+	case "t":
+		return "temporary"
+	default:
+		return ""
+	}
+}
+
+func mapReplicaIdentity(char string) string {
+	switch char {
+	case "d":
+		return "default"
+	case "n":
+		return "nothing"
+	case "f":
+		return "full"
+	case "i":
+		return "index"
+	default:
+		return ""
+	}
+}
+
+// mergeToastReloptions adds the TOAST table's reloptions to a table's storage
+// parameters using the "toast." prefix accepted by ALTER TABLE ... SET.
+func mergeToastReloptions(params map[string]string, toastOpts []string) map[string]string {
+	toast := parseReloptions(toastOpts)
+	if len(toast) == 0 {
+		return params
+	}
+
+	if params == nil {
+		params = make(map[string]string, len(toast))
+	}
+	for k, v := range toast {
+		params["toast."+k] = v
+	}
+	return params
+}
+
 // schemaPlaceholders generates $1, $2, ... for schema list
 func schemaPlaceholders(schemas []string) string {
 	placeholders := make([]string, len(schemas))
@@ -0,0 +1,71 @@
+package postgres
+
+import "testing"
+
+func TestMapPersistence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"p", "permanent"},
+		{"u", "unlogged"},
+		{"t", "temporary"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := mapPersistence(tt.input)
+			if result != tt.expected {
+				t.Errorf("mapPersistence(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMapReplicaIdentity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"d", "default"},
+		{"n", "nothing"},
+		{"f", "full"},
+		{"i", "index"},
+		{"x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := mapReplicaIdentity(tt.input)
+			if result != tt.expected {
+				t.Errorf("mapReplicaIdentity(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeToastReloptions(t *testing.T) {
+	params := mergeToastReloptions(
+		parseReloptions([]string{"fillfactor=80", "autovacuum_vacuum_scale_factor=0.01"}),
+		[]string{"autovacuum_enabled=false"},
+	)
+
+	expected := map[string]string{
+		"fillfactor":                     "80",
+		"autovacuum_vacuum_scale_factor": "0.01",
+		"toast.autovacuum_enabled":       "false",
+	}
+	if len(params) != len(expected) {
+		t.Fatalf("got %d params, want %d: %v", len(params), len(expected), params)
+	}
+	for k, v := range expected {
+		if params[k] != v {
+			t.Errorf("params[%q] = %q, want %q", k, params[k], v)
+		}
+	}
+
+	if mergeToastReloptions(nil, nil) != nil {
+		t.Error("expected nil when table and toast have no options")
+	}
+}
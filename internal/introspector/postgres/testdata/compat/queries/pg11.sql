@@ -0,0 +1,178 @@
+-- name: constraints
+			SELECT
+				con.conname as constraint_name,
+				con.contype::text as constraint_type,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as columns,
+				pg_get_constraintdef(con.oid) as definition,
+				con.condeferrable as deferrable,
+				con.condeferred as initially_deferred,
+				con.convalidated as validated,
+				con.connoinherit as no_inherit,
+				false as nulls_not_distinct
+			FROM pg_constraint con
+			JOIN pg_class c ON c.oid = con.conrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			LEFT JOIN pg_index ix ON ix.indexrelid = con.conindid AND con.contype = 'u'
+			WHERE n.nspname = $1 AND c.relname = $2
+			  AND con.contype IN ('p', 'u', 'c', 'x')
+			ORDER BY
+				CASE con.contype
+					WHEN 'p' THEN 1
+					WHEN 'u' THEN 2
+					WHEN 'c' THEN 3
+					WHEN 'x' THEN 4
+				END,
+				con.conname
+		
+-- name: foreign_keys
+			SELECT
+				con.conname as constraint_name,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as from_columns,
+				nf.nspname as to_schema,
+				cf.relname as to_table,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as to_columns,
+				con.confupdtype::text as on_update,
+				con.confdeltype::text as on_delete,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(ARRAY[]::int2[]) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as on_delete_set_columns,
+				con.confmatchtype::text as match_type,
+				con.condeferrable as deferrable,
+				con.condeferred as initially_deferred,
+				con.convalidated as validated
+			FROM pg_constraint con
+			JOIN pg_class c ON c.oid = con.conrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			JOIN pg_class cf ON cf.oid = con.confrelid
+			JOIN pg_namespace nf ON nf.oid = cf.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2
+			  AND con.contype = 'f'
+			ORDER BY con.conname
+		
+-- name: indexes
+			SELECT
+				i.relname as index_name,
+				ix.indisunique as is_unique,
+				ix.indisprimary as is_primary,
+				am.amname as index_type,
+				pg_get_indexdef(ix.indexrelid) as index_def,
+				ARRAY(
+					SELECT a.attname
+					FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+					ORDER BY k.ord
+				) as columns,
+				pg_get_expr(ix.indpred, ix.indrelid) as predicate,
+				pg_get_expr(ix.indexprs, ix.indrelid) as expression,
+				ARRAY(
+					SELECT pg_get_indexdef(ix.indexrelid, k.ord, true)
+					FROM generate_series(1, ix.indnkeyatts) AS k(ord)
+					ORDER BY k.ord
+				) as key_defs,
+				ARRAY(
+					SELECT ix.indoption[k.ord - 1]::int
+					FROM generate_series(1, ix.indnkeyatts) AS k(ord)
+					ORDER BY k.ord
+				) as key_options,
+				ARRAY(
+					SELECT COALESCE(opc.opcname, '')
+					FROM generate_series(1, ix.indnkeyatts) AS k(ord)
+					LEFT JOIN pg_opclass opc ON opc.oid = ix.indclass[k.ord - 1]
+					ORDER BY k.ord
+				) as key_opclasses,
+				ARRAY(
+					SELECT CASE
+						WHEN ix.indcollation[k.ord - 1] <> 0
+						 AND ix.indcollation[k.ord - 1] <> COALESCE(a.attcollation, 100)
+						THEN COALESCE(coll.collname, '')
+						ELSE ''
+					END
+					FROM generate_series(1, ix.indnkeyatts) AS k(ord)
+					LEFT JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = ix.indkey[k.ord - 1]
+					LEFT JOIN pg_collation coll ON coll.oid = ix.indcollation[k.ord - 1]
+					ORDER BY k.ord
+				) as key_collations,
+				pg_indexam_has_property(am.oid, 'can_order') as can_order,
+				ix.indisvalid as is_valid,
+				ix.indisready as is_ready,
+				false as nulls_not_distinct,
+				i.reloptions as reloptions,
+				COALESCE(obj_description(i.oid, 'pg_class'), '') as comment
+			FROM pg_index ix
+			JOIN pg_class t ON t.oid = ix.indrelid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			JOIN pg_am am ON am.oid = i.relam
+			WHERE n.nspname = $1 AND t.relname = $2
+			ORDER BY i.relname
+		
+-- name: namespaces
+			SELECT
+				n.nspname as schema_name,
+				pg_get_userbyid(n.nspowner) as owner,
+				COALESCE(obj_description(n.oid, 'pg_namespace'), '') as comment,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind IN ('r', 'p'))::int as tables,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'v')::int as views,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'm')::int as materialized_views,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'f')::int as foreign_tables,
+				(SELECT count(*) FROM pg_class c WHERE c.relnamespace = n.oid AND c.relkind = 'S')::int as sequences,
+				(SELECT count(*) FROM pg_proc p WHERE p.pronamespace = n.oid AND p.prokind = 'f')::int as functions,
+				(SELECT count(*) FROM pg_proc p WHERE p.pronamespace = n.oid AND p.prokind = 'p')::int as procedures,
+				(SELECT count(*) FROM pg_type t WHERE t.typnamespace = n.oid AND t.typtype = 'e')::int as enums,
+				(
+					SELECT count(*)
+					FROM pg_type t
+					WHERE t.typnamespace = n.oid
+					  AND t.typtype IN ('c', 'd', 'r')
+					  AND NOT EXISTS (
+						SELECT 1 FROM pg_class c WHERE c.reltype = t.oid AND c.relkind IN ('r', 'v', 'm', 'p')
+					  )
+				)::int as types
+			FROM pg_namespace n
+			WHERE n.nspname = ANY($1)
+			ORDER BY n.nspname
+		
+-- name: routines
+			SELECT
+				n.nspname as schema_name,
+				p.proname as routine_name,
+				CASE p.prokind
+					WHEN 'f' THEN 'function'
+					WHEN 'p' THEN 'procedure'
+					WHEN 'a' THEN 'aggregate'
+					WHEN 'w' THEN 'window'
+					ELSE 'function'
+				END as routine_type,
+				l.lanname as language,
+				pg_get_function_result(p.oid) as return_type,
+				pg_get_functiondef(p.oid) as definition,
+				COALESCE(obj_description(p.oid, 'pg_proc'), '') as comment,
+				p.proargnames as arg_names,
+				p.proargtypes::regtype[]::text[] as arg_types,
+				p.proargmodes::text[] as arg_modes,
+				p.pronargdefaults as num_defaults
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			JOIN pg_language l ON l.oid = p.prolang
+			WHERE n.nspname = ANY($1)
+			  AND p.prokind IN ('f', 'p')
+			ORDER BY n.nspname, p.proname
+		
@@ -0,0 +1,91 @@
+-- Seed schema for the per-version catalog recordings in this directory.
+-- `make compat-fixtures` loads it into each PostgreSQL release from 10 to 17
+-- and records the catalog with --record-fixture. Features newer than 10 are
+-- created from DO blocks gated on server_version_num.
+
+CREATE SCHEMA archive;
+COMMENT ON SCHEMA archive IS 'Closed orders';
+
+CREATE TYPE order_status AS ENUM ('pending', 'paid', 'shipped');
+
+CREATE COLLATION compat_c (locale = 'C');
+
+CREATE TEXT SEARCH CONFIGURATION compat_english (COPY = english);
+
+CREATE TABLE customers (
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    email text NOT NULL UNIQUE,
+    name text COLLATE compat_c NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE customers IS 'Registered customers';
+COMMENT ON COLUMN customers.email IS 'Login address';
+
+CREATE TABLE orders (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    customer_id bigint REFERENCES customers (id) ON DELETE CASCADE,
+    status order_status NOT NULL DEFAULT 'pending',
+    total numeric(12, 2) NOT NULL CHECK (total >= 0),
+    tags text[],
+    note text,
+    placed_at timestamptz NOT NULL DEFAULT now()
+) WITH (fillfactor = 90);
+
+CREATE INDEX orders_placed_at_idx ON orders (placed_at DESC NULLS LAST, id);
+CREATE INDEX orders_lower_note_idx ON orders (lower(note));
+CREATE INDEX orders_note_fts_idx ON orders USING gin (to_tsvector('compat_english'::regconfig, COALESCE(note, '')));
+
+-- archive.orders inherits every column of orders and declares note itself,
+-- so note is both inherited and local.
+CREATE TABLE archive.orders (
+    note text,
+    archived_at timestamptz NOT NULL DEFAULT now()
+) INHERITS (orders);
+
+CREATE TABLE order_events (
+    order_id bigint NOT NULL,
+    happened_at timestamptz NOT NULL,
+    payload jsonb
+) PARTITION BY RANGE (happened_at);
+
+CREATE TABLE order_events_2024 PARTITION OF order_events
+    FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+
+CREATE UNLOGGED TABLE session_cache (
+    key text PRIMARY KEY,
+    value jsonb
+);
+
+CREATE VIEW open_orders AS
+    SELECT id, customer_id, total FROM orders WHERE status <> 'shipped';
+
+CREATE FUNCTION order_count(customer bigint) RETURNS bigint
+    LANGUAGE sql STABLE
+    AS $$ SELECT count(*) FROM orders WHERE customer_id = customer $$;
+
+CREATE FUNCTION order_count(customer bigint, since timestamptz) RETURNS bigint
+    LANGUAGE sql STABLE
+    AS $$ SELECT count(*) FROM orders WHERE customer_id = customer AND placed_at >= since $$;
+
+DO $$
+BEGIN
+    IF current_setting('server_version_num')::int >= 110000 THEN
+        EXECUTE 'CREATE PROCEDURE close_order(target bigint) LANGUAGE sql AS $p$ UPDATE orders SET status = ''shipped'' WHERE id = target $p$';
+        EXECUTE 'CREATE INDEX orders_customer_covering_idx ON orders (customer_id) INCLUDE (total)';
+    END IF;
+
+    IF current_setting('server_version_num')::int >= 120000 THEN
+        EXECUTE 'ALTER TABLE customers ADD COLUMN email_domain text GENERATED ALWAYS AS (split_part(email, ''@'', 2)) STORED';
+        BEGIN
+            EXECUTE 'CREATE COLLATION case_insensitive (provider = icu, locale = ''und-u-ks-level2'', deterministic = false)';
+        EXCEPTION WHEN feature_not_supported THEN
+            RAISE NOTICE 'server built without ICU; skipping case_insensitive';
+        END;
+    END IF;
+
+    IF current_setting('server_version_num')::int >= 150000 THEN
+        EXECUTE 'CREATE TABLE coupons (code text, customer_id bigint, UNIQUE NULLS NOT DISTINCT (code))';
+        EXECUTE 'ALTER TABLE coupons ADD FOREIGN KEY (customer_id) REFERENCES customers (id) ON DELETE SET NULL (customer_id)';
+    END IF;
+END
+$$;
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// vectorTypes are the pgvector column types whose typmod carries the dimension.
+var vectorTypes = map[string]bool{
+	"vector":    true,
+	"halfvec":   true,
+	"sparsevec": true,
+}
+
+// vectorDistances maps pgvector operator class suffixes to the distance
+// function and the ORDER BY operator that can use the index.
+var vectorDistances = []struct {
+	suffix   string
+	distance string
+	operator string
+}{
+	{"_l2_ops", "l2", "<->"},
+	{"_ip_ops", "inner_product", "<#>"},
+	{"_cosine_ops", "cosine", "<=>"},
+	{"_l1_ops", "l1", "<+>"},
+	{"_hamming_ops", "hamming", "<~>"},
+	{"_jaccard_ops", "jaccard", "<%>"},
+}
+
+// applyVectorInfo detects pgvector columns, extracts their dimension from the
+// type modifier and links HNSW/IVFFlat indexes built on them.
+func applyVectorInfo(tables []*schema.Table) {
+	for _, t := range tables {
+		vectorCols := make(map[string]*schema.VectorInfo)
+		for _, col := range t.Columns {
+			if !vectorTypes[col.UDTName] {
+				continue
+			}
+			info := &schema.VectorInfo{Type: col.UDTName}
+			if col.TypeModifier > 0 {
+				info.Dimensions = col.TypeModifier
+			}
+			col.Vector = info
+			vectorCols[col.ColumnName] = info
+		}
+
+		if len(vectorCols) == 0 {
+			continue
+		}
+
+		for _, idx := range t.Indexes {
+			if idx.IndexType != "hnsw" && idx.IndexType != "ivfflat" {
+				continue
+			}
+			if len(idx.Columns) == 0 || len(idx.Keys) == 0 {
+				continue
+			}
+			info, ok := vectorCols[idx.Columns[0]]
+			if !ok {
+				continue
+			}
+			info.Indexes = append(info.Indexes, buildVectorIndex(idx))
+		}
+	}
+}
+
+func buildVectorIndex(idx *schema.Index) schema.VectorIndex {
+	vi := schema.VectorIndex{
+		IndexName: idx.IndexName,
+		Method:    idx.IndexType,
+		OpClass:   idx.Keys[0].OpClass,
+	}
+
+	for _, d := range vectorDistances {
+		if strings.HasSuffix(vi.OpClass, d.suffix) {
+			vi.Distance = d.distance
+			vi.Operator = d.operator
+			break
+		}
+	}
+
+	vi.M = atoiOrZero(idx.StorageParameters["m"])
+	vi.EfConstruction = atoiOrZero(idx.StorageParameters["ef_construction"])
+	vi.Lists = atoiOrZero(idx.StorageParameters["lists"])
+
+	return vi
+}
+
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
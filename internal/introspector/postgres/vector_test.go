@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+func TestApplyVectorInfo(t *testing.T) {
+	embedding := &schema.Column{ColumnName: "embedding", UDTName: "vector", TypeModifier: 1536}
+	sparse := &schema.Column{ColumnName: "keywords", UDTName: "sparsevec", TypeModifier: -1}
+	title := &schema.Column{ColumnName: "title", UDTName: "text", TypeModifier: -1}
+
+	table := &schema.Table{
+		TableName: "documents",
+		Columns:   []*schema.Column{embedding, sparse, title},
+		Indexes: []*schema.Index{
+			{
+				IndexName:         "documents_embedding_hnsw",
+				IndexType:         "hnsw",
+				Columns:           []string{"embedding"},
+				Keys:              []schema.IndexKey{{Column: "embedding", OpClass: "vector_cosine_ops"}},
+				StorageParameters: map[string]string{"m": "16", "ef_construction": "64"},
+			},
+			{
+				IndexName:         "documents_embedding_ivf",
+				IndexType:         "ivfflat",
+				Columns:           []string{"embedding"},
+				Keys:              []schema.IndexKey{{Column: "embedding", OpClass: "vector_ip_ops"}},
+				StorageParameters: map[string]string{"lists": "100"},
+			},
+			{
+				IndexName: "documents_title_idx",
+				IndexType: "btree",
+				Columns:   []string{"title"},
+				Keys:      []schema.IndexKey{{Column: "title", OpClass: "text_ops"}},
+			},
+		},
+	}
+
+	applyVectorInfo([]*schema.Table{table})
+
+	if embedding.Vector == nil || embedding.Vector.Dimensions != 1536 {
+		t.Fatalf("expected vector(1536) info, got %+v", embedding.Vector)
+	}
+	if len(embedding.Vector.Indexes) != 2 {
+		t.Fatalf("expected 2 vector indexes, got %d", len(embedding.Vector.Indexes))
+	}
+
+	hnsw := embedding.Vector.Indexes[0]
+	if hnsw.Distance != "cosine" || hnsw.Operator != "<=>" || hnsw.M != 16 || hnsw.EfConstruction != 64 {
+		t.Errorf("unexpected hnsw index info: %+v", hnsw)
+	}
+
+	ivf := embedding.Vector.Indexes[1]
+	if ivf.Distance != "inner_product" || ivf.Operator != "<#>" || ivf.Lists != 100 {
+		t.Errorf("unexpected ivfflat index info: %+v", ivf)
+	}
+
+	if sparse.Vector == nil || sparse.Vector.Dimensions != 0 || sparse.Vector.Type != "sparsevec" {
+		t.Errorf("expected sparsevec without dimensions, got %+v", sparse.Vector)
+	}
+	if title.Vector != nil {
+		t.Errorf("expected no vector info on text column, got %+v", title.Vector)
+	}
+}
@@ -0,0 +1,77 @@
+package schema
+
+// Bundle represents the schema.bundle output file: the manifest, index,
+// relationship graph and every table in one document, for pasting into a
+// chat. Its table types are flatter than the per-file artifacts so that
+// each column, key and index stays a single tabular row.
+type Bundle struct {
+	Manifest      Manifest          `json:"manifest"`
+	Index         DatabaseIndex     `json:"index"`
+	Relationships RelationshipGraph `json:"relationships"`
+	Tables        []BundleTable     `json:"tables"`
+	Enums         []Enum            `json:"enums,omitempty"`
+	Views         []BundleView      `json:"views,omitempty"`
+	Routines      []Routine         `json:"routines,omitempty"`
+}
+
+// BundleTable is a table's columns, keys and indexes.
+type BundleTable struct {
+	TableName         string             `json:"table_name"`
+	SchemaName        string             `json:"schema_name,omitempty"`
+	Comment           string             `json:"comment,omitempty"`
+	Columns           []BundleColumn     `json:"columns"`
+	PrimaryKey        []string           `json:"primary_key,omitempty"`
+	ForeignKeys       []BundleForeignKey `json:"foreign_keys,omitempty"`
+	UniqueConstraints []BundleConstraint `json:"unique_constraints,omitempty"`
+	CheckConstraints  []BundleConstraint `json:"check_constraints,omitempty"`
+	Indexes           []BundleIndex      `json:"indexes,omitempty"`
+	Stats             *Stats             `json:"stats,omitempty"`
+}
+
+// BundleColumn is a column with its type written as in DDL, e.g.
+// "character varying(255)". Identity and generated columns describe their
+// generation in Default.
+type BundleColumn struct {
+	ColumnName string  `json:"column_name"`
+	Type       string  `json:"type"`
+	Nullable   bool    `json:"nullable"`
+	Default    *string `json:"default"`
+	Comment    *string `json:"comment"`
+}
+
+// BundleForeignKey is an outgoing foreign key. ToTable is schema-qualified
+// when the target lives in another schema.
+type BundleForeignKey struct {
+	ConstraintName string   `json:"constraint_name"`
+	Columns        []string `json:"columns"`
+	ToTable        string   `json:"to_table"`
+	ToColumns      []string `json:"to_columns"`
+	OnDelete       string   `json:"on_delete"`
+}
+
+// BundleConstraint is a unique or check constraint.
+type BundleConstraint struct {
+	ConstraintName string   `json:"constraint_name"`
+	Columns        []string `json:"columns"`
+	Definition     *string  `json:"definition"`
+}
+
+// BundleIndex is an index without its per-key details.
+type BundleIndex struct {
+	IndexName  string   `json:"index_name"`
+	IndexType  string   `json:"index_type"`
+	Unique     bool     `json:"unique"`
+	Columns    []string `json:"columns"`
+	Expression *string  `json:"expression"`
+	Predicate  *string  `json:"predicate"`
+}
+
+// BundleView is a view's columns, dependencies and definition.
+type BundleView struct {
+	ViewName        string         `json:"view_name"`
+	SchemaName      string         `json:"schema_name,omitempty"`
+	Comment         string         `json:"comment,omitempty"`
+	Columns         []BundleColumn `json:"columns"`
+	DependsOnTables []string       `json:"depends_on_tables,omitempty"`
+	Definition      *string        `json:"definition,omitempty"`
+}
@@ -0,0 +1,44 @@
+package schema
+
+// Collation represents a collation object from pg_collation.
+type Collation struct {
+	CollationName string   `json:"collation_name"`
+	SchemaName    string   `json:"schema_name,omitempty"`
+	Provider      string   `json:"provider"`
+	Locale        string   `json:"locale,omitempty"`
+	Ctype         string   `json:"ctype,omitempty"`
+	Deterministic bool     `json:"deterministic"`
+	Comment       string   `json:"comment,omitempty"`
+	UsedBy        []string `json:"used_by_columns,omitempty"`
+}
+
+// CollationsOutput represents the db.collations.json output file.
+type CollationsOutput struct {
+	Collations []Collation `json:"collations"`
+}
+
+// TextSearchConfig represents a full-text search configuration (pg_ts_config).
+type TextSearchConfig struct {
+	ConfigName   string   `json:"config_name"`
+	SchemaName   string   `json:"schema_name,omitempty"`
+	Parser       string   `json:"parser"`
+	Dictionaries []string `json:"dictionaries,omitempty"`
+	IsDefault    bool     `json:"is_default,omitempty"`
+	Comment      string   `json:"comment,omitempty"`
+	UsedBy       []string `json:"used_by_columns,omitempty"`
+}
+
+// TextSearchDictionary represents a full-text search dictionary (pg_ts_dict).
+type TextSearchDictionary struct {
+	DictionaryName string `json:"dictionary_name"`
+	SchemaName     string `json:"schema_name,omitempty"`
+	Template       string `json:"template"`
+	Options        string `json:"options,omitempty"`
+	Comment        string `json:"comment,omitempty"`
+}
+
+// TextSearchOutput represents the db.text-search.json output file.
+type TextSearchOutput struct {
+	Configurations []TextSearchConfig     `json:"configurations"`
+	Dictionaries   []TextSearchDictionary `json:"dictionaries,omitempty"`
+}
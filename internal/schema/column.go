@@ -12,6 +12,17 @@ type Column struct {
 	IsIdentity           bool    `json:"is_identity,omitempty"`
 	IdentityGeneration   string  `json:"identity_generation,omitempty"`
 	Collation            *string `json:"collation,omitempty"`
+	CollationSchema      string  `json:"collation_schema,omitempty"`
+
+	// Spatial holds PostGIS metadata for geometry/geography columns
+	Spatial *SpatialInfo `json:"spatial,omitempty"`
+
+	// Vector holds pgvector metadata for vector/halfvec/sparsevec columns
+	Vector *VectorInfo `json:"vector,omitempty"`
+
+	// Inherited is true when the column comes only from an INHERITS parent;
+	// a column the child also declares itself is local
+	Inherited bool `json:"inherited,omitempty"`
 
 	// Size/precision information
 	CharacterMaxLength *int `json:"character_max_length,omitempty"`
@@ -21,10 +32,40 @@ type Column struct {
 	// Position in table
 	OrdinalPosition int `json:"-"`
 
+	// Raw type modifier (atttypmod), -1 when unspecified
+	TypeModifier int `json:"-"`
+
 	// Comment
 	Comment string `json:"-"`
 }
 
+// SpatialInfo describes a PostGIS geometry or geography column.
+type SpatialInfo struct {
+	Kind           string `json:"kind"`
+	GeometryType   string `json:"geometry_type"`
+	SRID           int    `json:"srid"`
+	CoordDimension int    `json:"coord_dimension"`
+}
+
+// VectorInfo describes a pgvector column and the ANN indexes built on it.
+type VectorInfo struct {
+	Type       string        `json:"type"`
+	Dimensions int           `json:"dimensions,omitempty"`
+	Indexes    []VectorIndex `json:"indexes,omitempty"`
+}
+
+// VectorIndex describes an HNSW or IVFFlat index on a vector column.
+type VectorIndex struct {
+	IndexName      string `json:"index_name"`
+	Method         string `json:"method"`
+	OpClass        string `json:"opclass"`
+	Distance       string `json:"distance"`
+	Operator       string `json:"operator"`
+	M              int    `json:"m,omitempty"`
+	EfConstruction int    `json:"ef_construction,omitempty"`
+	Lists          int    `json:"lists,omitempty"`
+}
+
 // ColumnsOutput represents the table.columns.json output file.
 type ColumnsOutput struct {
 	Columns []Column `json:"columns"`
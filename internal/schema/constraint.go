@@ -2,18 +2,23 @@ package schema
 
 // Constraint represents a database constraint.
 type Constraint struct {
-	ConstraintName string   `json:"constraint_name"`
-	ConstraintType string   `json:"constraint_type"`
-	Columns        []string `json:"columns,omitempty"`
-	Expression     *string  `json:"expression,omitempty"`
-	Definition     *string  `json:"definition,omitempty"`
+	ConstraintName    string   `json:"constraint_name"`
+	ConstraintType    string   `json:"constraint_type"`
+	Columns           []string `json:"columns,omitempty"`
+	Expression        *string  `json:"expression,omitempty"`
+	Definition        *string  `json:"definition,omitempty"`
+	Deferrable        bool     `json:"deferrable,omitempty"`
+	InitiallyDeferred bool     `json:"initially_deferred,omitempty"`
+	NotValid          bool     `json:"not_valid,omitempty"`
+	NoInherit         bool     `json:"no_inherit,omitempty"`
+	NullsNotDistinct  bool     `json:"nulls_not_distinct,omitempty"`
 }
 
 // ConstraintsOutput represents the table.constraints.json output file.
 type ConstraintsOutput struct {
-	PrimaryKey          *Constraint  `json:"primary_key,omitempty"`
-	UniqueConstraints   []Constraint `json:"unique_constraints,omitempty"`
-	CheckConstraints    []Constraint `json:"check_constraints,omitempty"`
+	PrimaryKey           *Constraint  `json:"primary_key,omitempty"`
+	UniqueConstraints    []Constraint `json:"unique_constraints,omitempty"`
+	CheckConstraints     []Constraint `json:"check_constraints,omitempty"`
 	ExclusionConstraints []Constraint `json:"exclusion_constraints,omitempty"`
-	NotNullConstraints  []Constraint `json:"not_null_constraints,omitempty"`
+	NotNullConstraints   []Constraint `json:"not_null_constraints,omitempty"`
 }
@@ -8,16 +8,26 @@ type Database struct {
 	Engine  string `json:"database_engine"`
 	Version string `json:"database_version,omitempty"`
 
-	Schemas   []string    `json:"-"`
-	Tables    []*Table    `json:"-"`
-	Views     []*View     `json:"-"`
-	Routines  []*Routine  `json:"-"`
-	Enums     []*Enum     `json:"-"`
-	Sequences []*Sequence `json:"-"`
-	Types     []*Type     `json:"-"`
+	Schemas    []string     `json:"-"`
+	Namespaces []*Namespace `json:"-"`
+	Tables     []*Table     `json:"-"`
+	Views      []*View      `json:"-"`
+	Routines   []*Routine   `json:"-"`
+	Enums      []*Enum      `json:"-"`
+	Sequences  []*Sequence  `json:"-"`
+	Types      []*Type      `json:"-"`
+
+	Collations             []*Collation            `json:"-"`
+	TextSearchConfigs      []*TextSearchConfig     `json:"-"`
+	TextSearchDictionaries []*TextSearchDictionary `json:"-"`
+
+	Environment *Environment `json:"-"`
+
+	// Features the source database could not provide
+	Unavailable []UnavailableFeature `json:"-"`
 }
 
-// Manifest represents the xrai.manifest.json output file.
+// Manifest represents the xrai.manifest output file, one per format.
 type Manifest struct {
 	GenerationTimestamp string           `json:"generation_timestamp"`
 	DatabaseEngine      string           `json:"database_engine"`
@@ -26,31 +36,80 @@ type Manifest struct {
 	IncludedSchemas     []string         `json:"included_schemas"`
 	IncludedTablesCount int              `json:"included_tables_count"`
 	EnabledArtifacts    EnabledArtifacts `json:"enabled_artifacts"`
+	EnvironmentFile     string           `json:"environment_file,omitempty"`
 	StatsEnabled        bool             `json:"stats_enabled"`
 	UsageEnabled        bool             `json:"usage_enabled"`
+
+	// Formats lists the file formats every artifact was written in.
+	Formats []string `json:"formats,omitempty"`
+
+	// TOONKeyFolding marks snapshots whose files fold single-key objects
+	// into dotted keys (a.b.c: v); readers expand them back.
+	TOONKeyFolding bool `json:"toon_key_folding,omitempty"`
+
+	UnavailableFeatures []UnavailableFeature `json:"unavailable_features,omitempty"`
+
+	Tokens      *TokenCounts `json:"tokens,omitempty"`
+	TokenBudget *TokenBudget `json:"token_budget,omitempty"`
+}
+
+// TokenCounts records the size of every file in the snapshot, in tokens of
+// the named encoding. The manifest itself is not counted.
+type TokenCounts struct {
+	Encoding string       `json:"encoding"`
+	Total    int          `json:"total"`
+	Files    []FileTokens `json:"files"`
+}
+
+// FileTokens is one file's token count; Path is relative to the snapshot.
+type FileTokens struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+}
+
+// TokenBudget records how File was fitted to a token budget: the content
+// dropped, in order, and whether it fits.
+type TokenBudget struct {
+	Budget  int      `json:"budget"`
+	File    string   `json:"file"`
+	Tokens  int      `json:"tokens"`
+	Fits    bool     `json:"fits"`
+	Dropped []string `json:"dropped,omitempty"`
+}
+
+// UnavailableFeature records a catalog feature or artifact that could not be
+// introspected on the source database, and why.
+type UnavailableFeature struct {
+	Feature string `json:"feature"`
+	Reason  string `json:"reason"`
 }
 
 // EnabledArtifacts tracks which artifact types were generated.
 type EnabledArtifacts struct {
-	Tables    bool `json:"tables"`
-	Views     bool `json:"views"`
-	Routines  bool `json:"routines"`
-	Enums     bool `json:"enums"`
-	Sequences bool `json:"sequences"`
-	Types     bool `json:"types"`
-	Stats     bool `json:"stats"`
+	Schemas    bool `json:"schemas"`
+	Tables     bool `json:"tables"`
+	Views      bool `json:"views"`
+	Routines   bool `json:"routines"`
+	Enums      bool `json:"enums"`
+	Sequences  bool `json:"sequences"`
+	Types      bool `json:"types"`
+	Collations bool `json:"collations"`
+	TextSearch bool `json:"text_search"`
+	Stats      bool `json:"stats"`
 }
 
 // DatabaseIndex represents the db.index.json output file.
 type DatabaseIndex struct {
-	Tables                 []TableIndexEntry `json:"tables"`
-	RecommendedStartTables []string          `json:"recommended_start_tables,omitempty"`
+	Tables                 []TableIndexEntry  `json:"tables"`
+	Objects                []ObjectIndexEntry `json:"objects,omitempty"`
+	RecommendedStartTables []string           `json:"recommended_start_tables,omitempty"`
 }
 
 // TableIndexEntry is a single table entry in the database index.
 type TableIndexEntry struct {
 	TableName          string   `json:"table_name"`
 	SchemaName         string   `json:"schema_name,omitempty"`
+	Path               string   `json:"path"`
 	ShortDescription   string   `json:"short_description,omitempty"`
 	RowCountEstimate   *int64   `json:"row_count_estimate,omitempty"`
 	PrimaryKeyColumns  []string `json:"primary_key_columns,omitempty"`
@@ -59,6 +118,15 @@ type TableIndexEntry struct {
 	Tags               []string `json:"tags,omitempty"`
 }
 
+// ObjectIndexEntry locates a view, routine, enum, sequence or type in the
+// snapshot. Path is a view's directory or the object's file.
+type ObjectIndexEntry struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	SchemaName string `json:"schema_name"`
+	Path       string `json:"path"`
+}
+
 // RelationshipGraph represents the db.relationships.json output file.
 type RelationshipGraph struct {
 	Nodes                       []RelationshipNode `json:"nodes"`
@@ -73,17 +141,24 @@ type RelationshipNode struct {
 	SchemaName string `json:"schema_name,omitempty"`
 }
 
-// RelationshipEdge is an edge (foreign key) in the relationship graph.
+// RelationshipEdge is an edge (foreign key or inheritance) in the relationship graph.
 type RelationshipEdge struct {
+	Kind           string   `json:"kind"`
 	FromTable      string   `json:"from_table"`
 	FromSchema     string   `json:"from_schema,omitempty"`
-	FromColumns    []string `json:"from_columns"`
+	FromColumns    []string `json:"from_columns,omitempty"`
 	ToTable        string   `json:"to_table"`
 	ToSchema       string   `json:"to_schema,omitempty"`
-	ToColumns      []string `json:"to_columns"`
-	ConstraintName string   `json:"constraint_name"`
+	ToColumns      []string `json:"to_columns,omitempty"`
+	ConstraintName string   `json:"constraint_name,omitempty"`
 }
 
+// Relationship edge kinds.
+const (
+	EdgeKindForeignKey = "foreign_key"
+	EdgeKindInherits   = "inherits"
+)
+
 // DomainGrouping represents the db.domains.json output file.
 type DomainGrouping struct {
 	Domains []Domain `json:"domains"`
@@ -0,0 +1,24 @@
+package schema
+
+// Environment is a snapshot of server and database-level settings that
+// affect query behavior (time zone, collation, search path, ...).
+type Environment struct {
+	ServerVersion  string    `json:"server_version,omitempty"`
+	VersionString  string    `json:"version_string,omitempty"`
+	Encoding       string    `json:"encoding,omitempty"`
+	Collate        string    `json:"lc_collate,omitempty"`
+	Ctype          string    `json:"lc_ctype,omitempty"`
+	LocaleProvider string    `json:"locale_provider,omitempty"`
+	ICULocale      string    `json:"icu_locale,omitempty"`
+	SizeBytes      *int64    `json:"size_bytes,omitempty"`
+	SizePretty     string    `json:"size_pretty,omitempty"`
+	Settings       []Setting `json:"settings"`
+}
+
+// Setting is a single server configuration parameter from pg_settings.
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Unit   string `json:"unit"`
+	Source string `json:"source"`
+}
@@ -2,15 +2,31 @@ package schema
 
 // Index represents a database index.
 type Index struct {
-	IndexName      string   `json:"index_name"`
-	Unique         bool     `json:"unique"`
-	Primary        bool     `json:"primary,omitempty"`
-	IndexType      string   `json:"index_type,omitempty"`
-	Columns        []string `json:"columns"`
-	IncludeColumns []string `json:"include_columns,omitempty"`
-	Predicate      *string  `json:"predicate,omitempty"`
-	Expression     *string  `json:"expression,omitempty"`
-	Definition     string   `json:"-"`
+	IndexName         string            `json:"index_name"`
+	Unique            bool              `json:"unique"`
+	Primary           bool              `json:"primary,omitempty"`
+	IndexType         string            `json:"index_type,omitempty"`
+	Columns           []string          `json:"columns"`
+	IncludeColumns    []string          `json:"include_columns,omitempty"`
+	Keys              []IndexKey        `json:"keys,omitempty"`
+	Predicate         *string           `json:"predicate,omitempty"`
+	Expression        *string           `json:"expression,omitempty"`
+	NullsNotDistinct  bool              `json:"nulls_not_distinct,omitempty"`
+	Spatial           bool              `json:"spatial,omitempty"`
+	Invalid           bool              `json:"invalid,omitempty"`
+	NotReady          bool              `json:"not_ready,omitempty"`
+	StorageParameters map[string]string `json:"storage_parameters,omitempty"`
+	Comment           string            `json:"comment,omitempty"`
+	Definition        string            `json:"definition,omitempty"`
+}
+
+// IndexKey describes a single key column (or expression) of an index.
+type IndexKey struct {
+	Column     string `json:"column"`
+	SortOrder  string `json:"sort_order,omitempty"`
+	NullsOrder string `json:"nulls_order,omitempty"`
+	OpClass    string `json:"opclass,omitempty"`
+	Collation  string `json:"collation,omitempty"`
 }
 
 // IndexesOutput represents the table.indexes.json output file.
@@ -0,0 +1,31 @@
+package schema
+
+// Namespace represents a database schema (pg_namespace) and its metadata.
+type Namespace struct {
+	SchemaName        string             `json:"schema_name"`
+	Owner             string             `json:"owner,omitempty"`
+	Comment           string             `json:"comment,omitempty"`
+	ObjectCounts      ObjectCounts       `json:"object_counts"`
+	DefaultPrivileges []DefaultPrivilege `json:"default_privileges,omitempty"`
+}
+
+// ObjectCounts tallies the objects contained in a schema by kind.
+type ObjectCounts struct {
+	Tables            int `json:"tables"`
+	Views             int `json:"views,omitempty"`
+	MaterializedViews int `json:"materialized_views,omitempty"`
+	ForeignTables     int `json:"foreign_tables,omitempty"`
+	Sequences         int `json:"sequences,omitempty"`
+	Functions         int `json:"functions,omitempty"`
+	Procedures        int `json:"procedures,omitempty"`
+	Enums             int `json:"enums,omitempty"`
+	Types             int `json:"types,omitempty"`
+}
+
+// DefaultPrivilege is an ALTER DEFAULT PRIVILEGES grant scoped to a schema.
+type DefaultPrivilege struct {
+	Role       string   `json:"role"`
+	ObjectType string   `json:"object_type"`
+	Grantee    string   `json:"grantee"`
+	Privileges []string `json:"privileges"`
+}
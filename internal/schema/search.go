@@ -0,0 +1,26 @@
+package schema
+
+// TableSearch summarizes the full-text and trigram search capabilities of a table.
+// The usage hints show the query shape once for all sources of a kind.
+type TableSearch struct {
+	FullText      []FullTextSource `json:"full_text,omitempty"`
+	FullTextUsage string           `json:"full_text_usage,omitempty"`
+	Trigram       []TrigramSource  `json:"trigram,omitempty"`
+	TrigramUsage  string           `json:"trigram_usage,omitempty"`
+}
+
+// FullTextSource is a tsvector column or to_tsvector(...) index expression.
+type FullTextSource struct {
+	Column        string   `json:"column,omitempty"`
+	Expression    string   `json:"expression,omitempty"`
+	Config        string   `json:"config,omitempty"`
+	SourceColumns []string `json:"source_columns,omitempty"`
+	Indexes       []string `json:"indexes,omitempty"`
+}
+
+// TrigramSource is a column (or expression) covered by a pg_trgm index.
+type TrigramSource struct {
+	Column    string `json:"column"`
+	IndexName string `json:"index_name"`
+	IndexType string `json:"index_type"`
+}
@@ -8,6 +8,15 @@ type Table struct {
 	Comment          string `json:"-"`
 	RowCountEstimate *int64 `json:"-"`
 
+	// Storage
+	Persistence       string            `json:"-"`
+	Tablespace        string            `json:"-"`
+	AccessMethod      string            `json:"-"`
+	StorageParameters map[string]string `json:"-"`
+	ReplicaIdentity   string            `json:"-"`
+	RLSEnabled        bool              `json:"-"`
+	RLSForced         bool              `json:"-"`
+
 	Columns     []*Column     `json:"-"`
 	Indexes     []*Index      `json:"-"`
 	Constraints []*Constraint `json:"-"`
@@ -17,10 +26,15 @@ type Table struct {
 	OutgoingForeignKeys []*ForeignKey `json:"-"`
 	IncomingForeignKeys []*ForeignKey `json:"-"`
 
+	// Inheritance (classic INHERITS, not declarative partitioning)
+	InheritsFrom []TableRef `json:"-"`
+	InheritedBy  []TableRef `json:"-"`
+
 	// Heuristics
-	IsJunction       bool     `json:"-"`
-	JunctionReasoning string   `json:"-"`
-	Tags             []string `json:"-"`
+	IsJunction        bool         `json:"-"`
+	JunctionReasoning string       `json:"-"`
+	Tags              []string     `json:"-"`
+	Search            *TableSearch `json:"-"`
 
 	// Optional
 	Stats *Stats `json:"-"`
@@ -34,6 +48,25 @@ type TableStructure struct {
 	TableType        string      `json:"table_type,omitempty"`
 	PrimaryKey       *PrimaryKey `json:"primary_key,omitempty"`
 	RowCountEstimate *int64      `json:"row_count_estimate,omitempty"`
+
+	Persistence       string            `json:"persistence,omitempty"`
+	Tablespace        string            `json:"tablespace,omitempty"`
+	AccessMethod      string            `json:"access_method,omitempty"`
+	StorageParameters map[string]string `json:"storage_parameters,omitempty"`
+	ReplicaIdentity   string            `json:"replica_identity,omitempty"`
+	RLSEnabled        bool              `json:"rls_enabled,omitempty"`
+	RLSForced         bool              `json:"rls_forced,omitempty"`
+
+	InheritsFrom []TableRef `json:"inherits_from,omitempty"`
+	InheritedBy  []TableRef `json:"inherited_by,omitempty"`
+
+	Search *TableSearch `json:"search,omitempty"`
+}
+
+// TableRef identifies a table by schema and name.
+type TableRef struct {
+	TableName  string `json:"table_name"`
+	SchemaName string `json:"schema_name,omitempty"`
 }
 
 // PrimaryKey represents primary key information.
@@ -44,22 +77,27 @@ type PrimaryKey struct {
 
 // TableRelations represents the table.relations.json output file.
 type TableRelations struct {
-	OutgoingForeignKeys    []ForeignKeyOutput   `json:"outgoing_foreign_keys"`
-	IncomingForeignKeys    []IncomingFKOutput   `json:"incoming_foreign_keys"`
-	JunctionTableDetection *JunctionDetection   `json:"junction_table_detection,omitempty"`
+	OutgoingForeignKeys    []ForeignKeyOutput `json:"outgoing_foreign_keys"`
+	IncomingForeignKeys    []IncomingFKOutput `json:"incoming_foreign_keys"`
+	JunctionTableDetection *JunctionDetection `json:"junction_table_detection,omitempty"`
 }
 
 // ForeignKeyOutput represents an outgoing foreign key in JSON output.
 type ForeignKeyOutput struct {
-	ConstraintName string   `json:"constraint_name"`
-	FromColumns    []string `json:"from_columns"`
-	ToTable        string   `json:"to_table"`
-	ToSchema       string   `json:"to_schema,omitempty"`
-	ToColumns      []string `json:"to_columns"`
-	OnUpdate       string   `json:"on_update,omitempty"`
-	OnDelete       string   `json:"on_delete,omitempty"`
-	Nullable       bool     `json:"nullable"`
-	Cardinality    string   `json:"cardinality,omitempty"`
+	ConstraintName     string   `json:"constraint_name"`
+	FromColumns        []string `json:"from_columns"`
+	ToTable            string   `json:"to_table"`
+	ToSchema           string   `json:"to_schema,omitempty"`
+	ToColumns          []string `json:"to_columns"`
+	OnUpdate           string   `json:"on_update,omitempty"`
+	OnDelete           string   `json:"on_delete,omitempty"`
+	OnDeleteSetColumns []string `json:"on_delete_set_columns,omitempty"`
+	MatchType          string   `json:"match_type,omitempty"`
+	Deferrable         bool     `json:"deferrable,omitempty"`
+	InitiallyDeferred  bool     `json:"initially_deferred,omitempty"`
+	NotValid           bool     `json:"not_valid,omitempty"`
+	Nullable           bool     `json:"nullable"`
+	Cardinality        string   `json:"cardinality,omitempty"`
 }
 
 // IncomingFKOutput represents an incoming foreign key in JSON output.
@@ -69,6 +107,9 @@ type IncomingFKOutput struct {
 	FromSchema     string   `json:"from_schema,omitempty"`
 	FromColumns    []string `json:"from_columns"`
 	ToColumns      []string `json:"to_columns"`
+	OnDelete       string   `json:"on_delete,omitempty"`
+	Deferrable     bool     `json:"deferrable,omitempty"`
+	NotValid       bool     `json:"not_valid,omitempty"`
 	Cardinality    string   `json:"cardinality,omitempty"`
 }
 
@@ -80,8 +121,8 @@ type JunctionDetection struct {
 
 // TableComments represents the table.comments.json output file.
 type TableComments struct {
-	TableComment      string            `json:"table_comment,omitempty"`
-	ColumnComments    map[string]string `json:"column_comments,omitempty"`
+	TableComment      string             `json:"table_comment,omitempty"`
+	ColumnComments    map[string]string  `json:"column_comments,omitempty"`
 	InferredSemantics *InferredSemantics `json:"inferred_semantics,omitempty"`
 }
 
@@ -0,0 +1,135 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// loadViews reads every views/<schema>/<name>/ directory.
+func (l *loader) loadViews(db *schema.Database) error {
+	dirs, err := objectDirs(filepath.Join(l.root, "views"), l.name("view.definition"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		var def schema.ViewDefinition
+		if err := l.read(l.path(dir, "view.definition"), &def); err != nil {
+			return err
+		}
+
+		var columns schema.ColumnsOutput
+		if _, err := l.readOptional(l.path(dir, "view.columns"), &columns); err != nil {
+			return err
+		}
+
+		var deps schema.ViewDependencies
+		if _, err := l.readOptional(l.path(dir, "view.dependencies"), &deps); err != nil {
+			return err
+		}
+
+		var comments schema.ViewComments
+		if _, err := l.readOptional(l.path(dir, "view.comments"), &comments); err != nil {
+			return err
+		}
+
+		db.Views = append(db.Views, &schema.View{
+			ViewName:        def.ViewName,
+			SchemaName:      def.SchemaName,
+			Definition:      def.Definition,
+			Comment:         comments.ViewComment,
+			Columns:         buildColumns(columns.Columns, comments.ColumnComments),
+			DependsOnTables: deps.DependsOnTables,
+			DependsOnViews:  deps.DependsOnViews,
+		})
+	}
+
+	sort.SliceStable(db.Views, func(i, j int) bool {
+		a, b := db.Views[i], db.Views[j]
+		return qualifiedLess(a.SchemaName, a.ViewName, b.SchemaName, b.ViewName)
+	})
+	return nil
+}
+
+// loadObjects reads the one-file-per-object trees: schemas, routines, enums,
+// sequences and types.
+func (l *loader) loadObjects(db *schema.Database) error {
+	var err error
+
+	if db.Namespaces, err = loadFiles[schema.Namespace](l, filepath.Join(l.root, "schemas")); err != nil {
+		return err
+	}
+	sort.SliceStable(db.Namespaces, func(i, j int) bool {
+		return db.Namespaces[i].SchemaName < db.Namespaces[j].SchemaName
+	})
+
+	for _, kind := range []string{"functions", "procedures"} {
+		routines, err := loadFiles[schema.Routine](l, filepath.Join(l.root, "routines", kind))
+		if err != nil {
+			return err
+		}
+		db.Routines = append(db.Routines, routines...)
+	}
+	sort.SliceStable(db.Routines, func(i, j int) bool {
+		a, b := db.Routines[i], db.Routines[j]
+		return qualifiedLess(a.SchemaName, a.RoutineName, b.SchemaName, b.RoutineName)
+	})
+
+	if db.Enums, err = loadFiles[schema.Enum](l, filepath.Join(l.root, "enums")); err != nil {
+		return err
+	}
+	sort.SliceStable(db.Enums, func(i, j int) bool {
+		a, b := db.Enums[i], db.Enums[j]
+		return qualifiedLess(a.SchemaName, a.EnumName, b.SchemaName, b.EnumName)
+	})
+
+	if db.Sequences, err = loadFiles[schema.Sequence](l, filepath.Join(l.root, "sequences")); err != nil {
+		return err
+	}
+	sort.SliceStable(db.Sequences, func(i, j int) bool {
+		a, b := db.Sequences[i], db.Sequences[j]
+		return qualifiedLess(a.SchemaName, a.SequenceName, b.SchemaName, b.SequenceName)
+	})
+
+	if db.Types, err = loadFiles[schema.Type](l, filepath.Join(l.root, "types")); err != nil {
+		return err
+	}
+	sort.SliceStable(db.Types, func(i, j int) bool {
+		a, b := db.Types[i], db.Types[j]
+		return qualifiedLess(a.SchemaName, a.TypeName, b.SchemaName, b.TypeName)
+	})
+
+	return nil
+}
+
+// loadFiles decodes every file in the snapshot's format in dir and its
+// schema directories into a T.
+func loadFiles[T any](l *loader, dir string) ([]*T, error) {
+	paths, err := listDir(dir, l.isArtifact)
+	if err != nil {
+		return nil, err
+	}
+	schemaDirs, err := listDir(dir, isDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, schemaDir := range schemaDirs {
+		files, err := listDir(schemaDir, l.isArtifact)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, files...)
+	}
+
+	out := make([]*T, 0, len(paths))
+	for _, path := range paths {
+		v := new(T)
+		if err := l.read(path, v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,298 @@
+// Package snapshot reads a generated .xrai directory back into a
+// schema.Database, so snapshots can be diffed, served or regenerated without
+// access to the original database.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nenorrell/X-Rai/internal/generator"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/toon"
+	"gopkg.in/yaml.v3"
+)
+
+// DirName is the directory the generator writes snapshots into.
+const DirName = ".xrai"
+
+// manifestFile is the manifest's base name; the generator writes it once per
+// output format.
+const manifestFile = "xrai.manifest"
+
+// loadFormats are the output formats a snapshot can be read back from, in
+// the order their manifests are looked for. Markdown is written for people
+// and cannot be.
+var loadFormats = []string{generator.FormatTOON, generator.FormatJSON, generator.FormatYAML}
+
+// Load reads the snapshot in dir, which may be the .xrai directory itself or
+// the output directory that contains it.
+func Load(dir string) (*schema.Database, error) {
+	root, format, err := resolveDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l, manifest, err := readManifest(root, format)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &schema.Database{
+		Name:        manifest.DatabaseName,
+		Engine:      manifest.DatabaseEngine,
+		Version:     manifest.DatabaseVersion,
+		Schemas:     manifest.IncludedSchemas,
+		Unavailable: manifest.UnavailableFeatures,
+	}
+
+	if manifest.EnvironmentFile != "" {
+		db.Environment = &schema.Environment{}
+		if err := l.read(filepath.Join(root, manifest.EnvironmentFile), db.Environment); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.loadInventories(db); err != nil {
+		return nil, err
+	}
+
+	if err := l.loadTables(db); err != nil {
+		return nil, err
+	}
+
+	if err := l.loadViews(db); err != nil {
+		return nil, err
+	}
+
+	if err := l.loadObjects(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// resolveDir locates the directory holding the manifest and returns it with
+// the format of the first manifest found there.
+func resolveDir(dir string) (string, string, error) {
+	for _, candidate := range []string{dir, filepath.Join(dir, DirName)} {
+		for _, format := range loadFormats {
+			if _, err := os.Stat(filepath.Join(candidate, manifestFile+"."+format)); err == nil {
+				return candidate, format, nil
+			}
+		}
+		if _, err := os.Stat(filepath.Join(candidate, manifestFile+".md")); err == nil {
+			return "", "", fmt.Errorf("snapshot in %s was written only as markdown; regenerate it with --format toon, json or yaml to load it", candidate)
+		}
+	}
+	return "", "", fmt.Errorf("no %s.toon, .json or .yaml found in %s", manifestFile, dir)
+}
+
+// loader reads the files of one snapshot in the format and, for TOON, the
+// layout it was written in.
+type loader struct {
+	root   string
+	format string
+	opts   toon.DecodeOptions
+}
+
+// readManifest reads the manifest in format and returns a loader for the
+// rest of the snapshot, which reads the first format the manifest lists that
+// can be loaded. For TOON the indentation width comes from the manifest's
+// first indented line, and key folding from the flag the generator records;
+// the manifest itself never contains a foldable chain.
+func readManifest(root, format string) (*loader, *schema.Manifest, error) {
+	l := &loader{root: root, format: format}
+	manifest, err := l.readManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if primary := loadableFormat(manifest.Formats); primary != "" && primary != format {
+		l.format = primary
+		if manifest, err = l.readManifest(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return l, manifest, nil
+}
+
+func (l *loader) readManifest() (*schema.Manifest, error) {
+	path := l.path(l.root, manifestFile)
+	if l.format == generator.FormatTOON {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		l.opts = toon.DecodeOptions{Indent: toon.DetectIndent(data)}
+	}
+
+	var manifest schema.Manifest
+	if err := l.read(path, &manifest); err != nil {
+		return nil, err
+	}
+	l.opts.ExpandPaths = manifest.TOONKeyFolding
+	return &manifest, nil
+}
+
+// loadableFormat returns the first of formats a snapshot can be loaded from,
+// or "" when there is none.
+func loadableFormat(formats []string) string {
+	for _, f := range formats {
+		for _, lf := range loadFormats {
+			if f == lf {
+				return f
+			}
+		}
+	}
+	return ""
+}
+
+// name returns the file name of an artifact in the snapshot's format.
+func (l *loader) name(base string) string {
+	return base + "." + l.format
+}
+
+// path joins dir and the file name of an artifact.
+func (l *loader) path(dir, base string) string {
+	return filepath.Join(dir, l.name(base))
+}
+
+// isArtifact reports whether e is a file in the snapshot's format.
+func (l *loader) isArtifact(e fs.DirEntry) bool {
+	return !e.IsDir() && strings.HasSuffix(e.Name(), "."+l.format)
+}
+
+// loadInventories reads the database-wide collation and text search files.
+func (l *loader) loadInventories(db *schema.Database) error {
+	var collations schema.CollationsOutput
+	if ok, err := l.readOptional(l.path(l.root, "db.collations"), &collations); err != nil {
+		return err
+	} else if ok {
+		for i := range collations.Collations {
+			db.Collations = append(db.Collations, &collations.Collations[i])
+		}
+	}
+
+	var search schema.TextSearchOutput
+	if ok, err := l.readOptional(l.path(l.root, "db.text-search"), &search); err != nil {
+		return err
+	} else if ok {
+		for i := range search.Configurations {
+			db.TextSearchConfigs = append(db.TextSearchConfigs, &search.Configurations[i])
+		}
+		for i := range search.Dictionaries {
+			db.TextSearchDictionaries = append(db.TextSearchDictionaries, &search.Dictionaries[i])
+		}
+	}
+
+	return nil
+}
+
+// read decodes a file in the snapshot's format into v. YAML follows the
+// json tags the generator wrote it with, so it is decoded through JSON.
+func (l *loader) read(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	switch l.format {
+	case generator.FormatJSON:
+		err = json.Unmarshal(data, v)
+	case generator.FormatYAML:
+		err = unmarshalYAML(data, v)
+	default:
+		err = toon.UnmarshalWithOptions(data, v, l.opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return nil
+}
+
+func unmarshalYAML(data []byte, v interface{}) error {
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readOptional is read for files the generator only writes when there is
+// something to put in them. It reports whether the file existed.
+func (l *loader) readOptional(path string, v interface{}) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err := l.read(path, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// listDir returns the sorted entries of dir matching want, or nothing when
+// dir does not exist.
+func listDir(dir string, want func(fs.DirEntry) bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if want(e) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// objectDirs returns the object directories under dir: those in each
+// schema directory, and any holding marker directly, as snapshots written
+// before objects were grouped by schema do.
+func objectDirs(dir, marker string) ([]string, error) {
+	children, err := listDir(dir, isDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, child := range children {
+		if _, err := os.Stat(filepath.Join(child, marker)); err == nil {
+			dirs = append(dirs, child)
+			continue
+		}
+		objects, err := listDir(child, isDir)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, objects...)
+	}
+	return dirs, nil
+}
+
+func isDir(e fs.DirEntry) bool { return e.IsDir() }
+
+// qualifiedLess orders objects by schema then name, matching the
+// introspectors.
+func qualifiedLess(schemaA, nameA, schemaB, nameB string) bool {
+	if schemaA != schemaB {
+		return schemaA < schemaB
+	}
+	return nameA < nameB
+}
@@ -0,0 +1,266 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nenorrell/X-Rai/internal/config"
+	"github.com/nenorrell/X-Rai/internal/generator"
+	"github.com/nenorrell/X-Rai/internal/introspector"
+	"github.com/nenorrell/X-Rai/internal/introspector/ddl"
+	"github.com/nenorrell/X-Rai/internal/introspector/fixture"
+	"github.com/nenorrell/X-Rai/internal/schema"
+	"github.com/nenorrell/X-Rai/internal/toon"
+	"gopkg.in/yaml.v3"
+)
+
+// TestLoad_RoundTrip checks that generate → load → generate reproduces the
+// same snapshot.
+func TestLoad_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		open    func() (introspector.Introspector, error)
+		schemas []string
+		formats []string
+		toon    toon.EncodeOptions
+	}{
+		{
+			name: "fixture",
+			open: func() (introspector.Introspector, error) {
+				return fixture.New(filepath.Join("..", "generator", "testdata", "shop.yaml"))
+			},
+			schemas: []string{"public"},
+		},
+		{
+			name: "fixture with pipe delimiter and folded keys",
+			open: func() (introspector.Introspector, error) {
+				return fixture.New(filepath.Join("..", "generator", "testdata", "shop.yaml"))
+			},
+			schemas: []string{"public"},
+			toon:    toon.EncodeOptions{Delimiter: toon.Pipe, KeyFolding: true, Indent: 4, InlineLists: true},
+		},
+		{
+			name: "fixture as json",
+			open: func() (introspector.Introspector, error) {
+				return fixture.New(filepath.Join("..", "generator", "testdata", "shop.yaml"))
+			},
+			schemas: []string{"public"},
+			formats: []string{"json", "markdown"},
+		},
+		{
+			name: "pg_dump as yaml",
+			open: func() (introspector.Introspector, error) {
+				return ddl.New(filepath.Join("..", "introspector", "ddl", "testdata", "pg_dump.sql"))
+			},
+			schemas: []string{"public", "billing"},
+			formats: []string{"markdown", "yaml", "toon"},
+		},
+		{
+			name: "pg_dump",
+			open: func() (introspector.Introspector, error) {
+				return ddl.New(filepath.Join("..", "introspector", "ddl", "testdata", "pg_dump.sql"))
+			},
+			schemas: []string{"public", "billing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intro, err := tt.open()
+			if err != nil {
+				t.Fatalf("open() error = %v", err)
+			}
+
+			cfg := config.NewConfig()
+			cfg.Schemas = tt.schemas
+			cfg.IncludeViews = true
+			cfg.IncludeRoutines = true
+			cfg.TOON = tt.toon
+			if tt.formats != nil {
+				cfg.Formats = tt.formats
+			}
+
+			db, err := intro.Introspect(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("Introspect() error = %v", err)
+			}
+
+			first := generate(t, cfg, db)
+
+			loaded, err := Load(first)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			second := generate(t, cfg, loaded)
+			opts := toon.DecodeOptions{ExpandPaths: tt.toon.KeyFolding, Indent: tt.toon.Indent}
+			compareSnapshots(t, filepath.Join(first, DirName), filepath.Join(second, DirName), opts)
+		})
+	}
+}
+
+func TestLoad_Reconstructs(t *testing.T) {
+	intro, err := fixture.New(filepath.Join("..", "generator", "testdata", "shop.yaml"))
+	if err != nil {
+		t.Fatalf("fixture.New() error = %v", err)
+	}
+	cfg := config.NewConfig()
+	db, err := intro.Introspect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	out := generate(t, cfg, db)
+
+	loaded, err := Load(filepath.Join(out, DirName))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.Name != "shop" || loaded.Version != "16.2" || loaded.Engine != db.Engine {
+		t.Errorf("metadata = %q %q %q", loaded.Name, loaded.Version, loaded.Engine)
+	}
+	if len(loaded.Tables) != len(db.Tables) {
+		t.Fatalf("tables = %d, want %d", len(loaded.Tables), len(db.Tables))
+	}
+
+	for i, want := range db.Tables {
+		got := loaded.Tables[i]
+		if got.SchemaName != want.SchemaName || got.TableName != want.TableName {
+			t.Errorf("table %d = %s.%s, want %s.%s", i, got.SchemaName, got.TableName, want.SchemaName, want.TableName)
+			continue
+		}
+		if got.Comment != want.Comment {
+			t.Errorf("%s comment = %q, want %q", got.TableName, got.Comment, want.Comment)
+		}
+		if !reflect.DeepEqual(fkNames(got.IncomingForeignKeys), fkNames(want.IncomingForeignKeys)) {
+			t.Errorf("%s incoming = %v, want %v", got.TableName, fkNames(got.IncomingForeignKeys), fkNames(want.IncomingForeignKeys))
+		}
+		if len(got.Constraints) != len(want.Constraints) {
+			t.Errorf("%s constraints = %d, want %d", got.TableName, len(got.Constraints), len(want.Constraints))
+		}
+		for j, col := range got.Columns {
+			if col.OrdinalPosition != j+1 || col.Comment != want.Columns[j].Comment {
+				t.Errorf("%s.%s = position %d comment %q", got.TableName, col.ColumnName, col.OrdinalPosition, col.Comment)
+			}
+		}
+	}
+
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Error("Load() error = nil for a directory without a manifest")
+	}
+
+	cfg.Formats = []string{"markdown"}
+	if _, err := Load(generate(t, cfg, db)); err == nil || !strings.Contains(err.Error(), "only as markdown") {
+		t.Errorf("Load() error = %v for a markdown-only snapshot", err)
+	}
+}
+
+func generate(t *testing.T, cfg *config.Config, db *schema.Database) string {
+	t.Helper()
+	out := *cfg
+	out.OutputDir = t.TempDir()
+	if err := generator.New(&out).Generate(db); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	return out.OutputDir
+}
+
+// compareSnapshots asserts that two snapshot directories hold the same files
+// with the same decoded content, ignoring the generation timestamp.
+func compareSnapshots(t *testing.T, wantDir, gotDir string, opts toon.DecodeOptions) {
+	t.Helper()
+
+	want := readTree(t, wantDir)
+	got := readTree(t, gotDir)
+
+	for name := range want {
+		if _, ok := got[name]; !ok {
+			t.Errorf("missing %s after round trip", name)
+		}
+	}
+	for name, data := range got {
+		wantData, ok := want[name]
+		if !ok {
+			t.Errorf("unexpected %s after round trip", name)
+			continue
+		}
+		switch filepath.Ext(name) {
+		case ".toon", ".json", ".yaml":
+		default:
+			if !bytes.Equal(maskTimestamp(data), maskTimestamp(wantData)) {
+				t.Errorf("%s differs:\n%s\nwant:\n%s", name, data, wantData)
+			}
+			continue
+		}
+
+		a, b := decode(t, name, wantData, opts), decode(t, name, data, opts)
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("%s differs:\n%s\nwant:\n%s", name, data, wantData)
+		}
+	}
+}
+
+// timestampRe matches the generation timestamp line of a markdown manifest.
+var timestampRe = regexp.MustCompile(`(?m)^.*\*\*generation_timestamp\*\*.*$`)
+
+func maskTimestamp(data []byte) []byte {
+	return timestampRe.ReplaceAll(data, []byte("generation_timestamp"))
+}
+
+func readTree(t *testing.T, root string) map[string][]byte {
+	t.Helper()
+	files := map[string][]byte{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func decode(t *testing.T, name string, data []byte, opts toon.DecodeOptions) interface{} {
+	t.Helper()
+	var v interface{}
+	var err error
+	switch filepath.Ext(name) {
+	case ".json":
+		err = json.Unmarshal(data, &v)
+	case ".yaml":
+		err = yaml.Unmarshal(data, &v)
+	default:
+		v, err = toon.DecodeWithOptions(data, opts)
+	}
+	if err != nil {
+		t.Fatalf("Decode(%s) error = %v", name, err)
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		delete(obj, "generation_timestamp")
+	}
+	return v
+}
+
+func fkNames(fks []*schema.ForeignKey) []string {
+	var names []string
+	for _, fk := range fks {
+		names = append(names, fk.FromTable+"."+fk.ConstraintName)
+	}
+	return names
+}
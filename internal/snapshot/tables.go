@@ -0,0 +1,205 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/nenorrell/X-Rai/internal/schema"
+)
+
+// loadTables reads every tables/<schema>/<name>/ directory and links foreign
+// keys in both directions.
+func (l *loader) loadTables(db *schema.Database) error {
+	var index schema.DatabaseIndex
+	if _, err := l.readOptional(l.path(l.root, "db.index"), &index); err != nil {
+		return err
+	}
+	tags := make(map[string][]string, len(index.Tables))
+	for _, entry := range index.Tables {
+		tags[entry.SchemaName+"."+entry.TableName] = entry.Tags
+	}
+
+	dirs, err := objectDirs(filepath.Join(l.root, "tables"), l.name("table.structure"))
+	if err != nil {
+		return err
+	}
+
+	incoming := make(map[string][]schema.IncomingFKOutput)
+	for _, dir := range dirs {
+		table, in, err := l.loadTable(dir)
+		if err != nil {
+			return err
+		}
+		key := table.SchemaName + "." + table.TableName
+		table.Tags = tags[key]
+		incoming[key] = in
+		db.Tables = append(db.Tables, table)
+	}
+
+	sort.SliceStable(db.Tables, func(i, j int) bool {
+		a, b := db.Tables[i], db.Tables[j]
+		return qualifiedLess(a.SchemaName, a.TableName, b.SchemaName, b.TableName)
+	})
+
+	buildIncomingForeignKeys(db.Tables, incoming)
+	return nil
+}
+
+// loadTable reads one table directory. The incoming foreign keys recorded in
+// table.relations are returned separately; they are rebuilt from the
+// outgoing side once every table is loaded.
+func (l *loader) loadTable(dir string) (*schema.Table, []schema.IncomingFKOutput, error) {
+	var structure schema.TableStructure
+	if err := l.read(l.path(dir, "table.structure"), &structure); err != nil {
+		return nil, nil, err
+	}
+
+	table := &schema.Table{
+		TableName:         structure.TableName,
+		SchemaName:        structure.SchemaName,
+		TableType:         structure.TableType,
+		RowCountEstimate:  structure.RowCountEstimate,
+		Persistence:       structure.Persistence,
+		Tablespace:        structure.Tablespace,
+		AccessMethod:      structure.AccessMethod,
+		StorageParameters: structure.StorageParameters,
+		ReplicaIdentity:   structure.ReplicaIdentity,
+		RLSEnabled:        structure.RLSEnabled,
+		RLSForced:         structure.RLSForced,
+		InheritsFrom:      structure.InheritsFrom,
+		InheritedBy:       structure.InheritedBy,
+		Search:            structure.Search,
+	}
+
+	var comments schema.TableComments
+	if _, err := l.readOptional(l.path(dir, "table.comments"), &comments); err != nil {
+		return nil, nil, err
+	}
+	table.Comment = comments.TableComment
+
+	var columns schema.ColumnsOutput
+	if _, err := l.readOptional(l.path(dir, "table.columns"), &columns); err != nil {
+		return nil, nil, err
+	}
+	table.Columns = buildColumns(columns.Columns, comments.ColumnComments)
+
+	var indexes schema.IndexesOutput
+	if _, err := l.readOptional(l.path(dir, "table.indexes"), &indexes); err != nil {
+		return nil, nil, err
+	}
+	for i := range indexes.Indexes {
+		table.Indexes = append(table.Indexes, &indexes.Indexes[i])
+	}
+
+	var constraints schema.ConstraintsOutput
+	if _, err := l.readOptional(l.path(dir, "table.constraints"), &constraints); err != nil {
+		return nil, nil, err
+	}
+	table.Constraints = buildConstraints(constraints)
+
+	var triggers schema.TriggersOutput
+	if _, err := l.readOptional(l.path(dir, "table.triggers"), &triggers); err != nil {
+		return nil, nil, err
+	}
+	for i := range triggers.Triggers {
+		table.Triggers = append(table.Triggers, &triggers.Triggers[i])
+	}
+
+	var relations schema.TableRelations
+	if _, err := l.readOptional(l.path(dir, "table.relations"), &relations); err != nil {
+		return nil, nil, err
+	}
+	for _, fk := range relations.OutgoingForeignKeys {
+		table.OutgoingForeignKeys = append(table.OutgoingForeignKeys, &schema.ForeignKey{
+			ConstraintName:     fk.ConstraintName,
+			FromSchema:         table.SchemaName,
+			FromTable:          table.TableName,
+			FromColumns:        fk.FromColumns,
+			ToSchema:           fk.ToSchema,
+			ToTable:            fk.ToTable,
+			ToColumns:          fk.ToColumns,
+			OnUpdate:           fk.OnUpdate,
+			OnDelete:           fk.OnDelete,
+			OnDeleteSetColumns: fk.OnDeleteSetColumns,
+			MatchType:          fk.MatchType,
+			Deferrable:         fk.Deferrable,
+			InitiallyDeferred:  fk.InitiallyDeferred,
+			NotValid:           fk.NotValid,
+			Nullable:           fk.Nullable,
+			Cardinality:        fk.Cardinality,
+		})
+	}
+	if j := relations.JunctionTableDetection; j != nil {
+		table.IsJunction = j.IsJunction
+		table.JunctionReasoning = j.Reasoning
+	}
+
+	var stats schema.Stats
+	if ok, err := l.readOptional(l.path(dir, "table.stats"), &stats); err != nil {
+		return nil, nil, err
+	} else if ok {
+		table.Stats = &stats
+	}
+
+	return table, relations.IncomingForeignKeys, nil
+}
+
+// buildColumns restores column positions and the comments stored in the
+// comments file.
+func buildColumns(columns []schema.Column, comments map[string]string) []*schema.Column {
+	out := make([]*schema.Column, 0, len(columns))
+	for i := range columns {
+		col := &columns[i]
+		col.OrdinalPosition = i + 1
+		col.TypeModifier = -1
+		col.Comment = comments[col.ColumnName]
+		out = append(out, col)
+	}
+	return out
+}
+
+// buildConstraints flattens the grouped constraints file back into the
+// introspector order: primary key, unique, check, exclusion. NOT NULL entries
+// are derived from columns by the generator and are not restored.
+func buildConstraints(c schema.ConstraintsOutput) []*schema.Constraint {
+	var out []*schema.Constraint
+	if c.PrimaryKey != nil {
+		out = append(out, c.PrimaryKey)
+	}
+	for _, group := range [][]schema.Constraint{c.UniqueConstraints, c.CheckConstraints, c.ExclusionConstraints} {
+		for i := range group {
+			out = append(out, &group[i])
+		}
+	}
+	return out
+}
+
+// buildIncomingForeignKeys mirrors each outgoing foreign key onto its target,
+// as the introspectors do. Cardinality is taken from the recorded incoming
+// entry because it is derived separately for each direction.
+func buildIncomingForeignKeys(tables []*schema.Table, recorded map[string][]schema.IncomingFKOutput) {
+	tableMap := make(map[string]*schema.Table, len(tables))
+	for _, t := range tables {
+		tableMap[t.SchemaName+"."+t.TableName] = t
+	}
+
+	for _, t := range tables {
+		for _, fk := range t.OutgoingForeignKeys {
+			targetKey := fk.ToSchema + "." + fk.ToTable
+			target, ok := tableMap[targetKey]
+			if !ok {
+				continue
+			}
+
+			incoming := *fk
+			incoming.Cardinality = ""
+			for _, r := range recorded[targetKey] {
+				if r.ConstraintName == fk.ConstraintName && r.FromSchema == t.SchemaName && r.FromTable == t.TableName {
+					incoming.Cardinality = r.Cardinality
+					break
+				}
+			}
+			target.IncomingForeignKeys = append(target.IncomingForeignKeys, &incoming)
+		}
+	}
+}
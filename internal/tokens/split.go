@@ -0,0 +1,146 @@
+package tokens
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// split breaks text into the pieces BPE works within, matching the
+// cl100k_base pre-tokenizer pattern:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+//
+// Go's regexp has no lookahead, so the alternatives are matched by hand, in
+// the same order.
+func split(text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		n := matchAt(text, i)
+		pieces = append(pieces, text[i:i+n])
+		i += n
+	}
+	return pieces
+}
+
+// matchAt returns the length of the piece starting at byte offset i.
+func matchAt(s string, i int) int {
+	r, size := utf8.DecodeRuneInString(s[i:])
+
+	// (?i:'s|'t|'re|'ve|'m|'ll|'d)
+	if r == '\'' {
+		if n := contraction(s[i+1:]); n > 0 {
+			return 1 + n
+		}
+	}
+
+	// [^\r\n\p{L}\p{N}]?\p{L}+
+	if isLetter(r) {
+		return size + runLength(s[i+size:], isLetter)
+	}
+	if r != '\r' && r != '\n' && !isNumber(r) {
+		if next, _ := utf8.DecodeRuneInString(s[i+size:]); i+size < len(s) && isLetter(next) {
+			return size + runLength(s[i+size:], isLetter)
+		}
+	}
+
+	// \p{N}{1,3}
+	if isNumber(r) {
+		n := size
+		for count := 1; count < 3 && i+n < len(s); count++ {
+			next, nsize := utf8.DecodeRuneInString(s[i+n:])
+			if !isNumber(next) {
+				break
+			}
+			n += nsize
+		}
+		return n
+	}
+
+	// ' ?[^\s\p{L}\p{N}]+[\r\n]*'
+	start := 0
+	if r == ' ' {
+		start = 1
+	}
+	if n := runLength(s[i+start:], isSymbol); n > 0 {
+		n += start
+		n += runLength(s[i+n:], isNewline)
+		return n
+	}
+
+	// Whitespace: \s*[\r\n]+ | \s+(?!\S) | \s+
+	ws := runLength(s[i:], unicode.IsSpace)
+	if ws == 0 {
+		// Unreachable: every rune is a letter, number, symbol or space.
+		return size
+	}
+	if last := lastNewline(s[i : i+ws]); last >= 0 {
+		return last + 1
+	}
+	if i+ws == len(s) {
+		return ws
+	}
+	// Leave the final space to prefix the next word.
+	_, lastSize := utf8.DecodeLastRuneInString(s[i : i+ws])
+	if ws-lastSize > 0 {
+		return ws - lastSize
+	}
+	return ws
+}
+
+// contraction matches the suffixes of (?i:'s|'t|'re|'ve|'m|'ll|'d) after
+// the apostrophe.
+func contraction(s string) int {
+	for _, suffix := range []string{"s", "t", "re", "ve", "m", "ll", "d"} {
+		if len(s) >= len(suffix) && equalFoldASCII(s[:len(suffix)], suffix) {
+			return len(suffix)
+		}
+	}
+	return 0
+}
+
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		c := a[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// runLength returns the byte length of the leading run of runes in s that
+// satisfy fn.
+func runLength(s string, fn func(rune) bool) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !fn(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// lastNewline returns the offset of the last \r or \n in s, or -1.
+func lastNewline(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '\r' || s[i] == '\n' {
+			return i
+		}
+	}
+	return -1
+}
+
+func isLetter(r rune) bool  { return unicode.IsLetter(r) }
+func isNumber(r rune) bool  { return unicode.IsNumber(r) }
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+// isSymbol matches [^\s\p{L}\p{N}].
+func isSymbol(r rune) bool {
+	return !unicode.IsSpace(r) && !isLetter(r) && !isNumber(r)
+}
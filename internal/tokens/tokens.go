@@ -0,0 +1,122 @@
+// Package tokens counts tokens the way OpenAI's cl100k_base encoding does,
+// so snapshot sizes can be compared with a model's context window without a
+// network round trip. The BPE vocabulary is embedded in the binary: it is
+// https://openaipublic.blob.core.windows.net/encodings/cl100k_base.tiktoken
+// (SHA-256 223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7
+// before gzipping), MIT-licensed as part of tiktoken; see
+// cl100k_base.tiktoken.LICENSE.
+//
+// Other model families tokenize differently; treat counts as an estimate
+// that is exact for cl100k_base models and close for most others.
+package tokens
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoding names the vocabulary counts are based on.
+const Encoding = "cl100k_base"
+
+// cl100kBase is tiktoken's cl100k_base.tiktoken file, gzipped: one
+// base64-encoded token and its merge rank per line.
+//
+//go:embed cl100k_base.tiktoken.gz
+var cl100kBase []byte
+
+var (
+	loadOnce sync.Once
+	ranks    map[string]int
+	loadErr  error
+)
+
+// vocabulary parses the embedded vocabulary on first use.
+func vocabulary() (map[string]int, error) {
+	loadOnce.Do(func() {
+		ranks, loadErr = parseVocabulary(cl100kBase)
+	})
+	return ranks, loadErr
+}
+
+func parseVocabulary(data []byte) (map[string]int, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary: %w", err)
+	}
+	defer zr.Close()
+
+	vocab := make(map[string]int, 100256)
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		token, rank, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse vocabulary token %q: %w", token, err)
+		}
+		n, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse vocabulary rank %q: %w", rank, err)
+		}
+		vocab[string(raw)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary: %w", err)
+	}
+	return vocab, nil
+}
+
+// Count returns the number of cl100k_base tokens in text. Special tokens
+// such as <|endoftext|> are counted as ordinary text.
+func Count(text string) int {
+	vocab, err := vocabulary()
+	if err != nil {
+		// The vocabulary is compiled in, so this only happens if the
+		// embedded file is corrupt.
+		panic(err)
+	}
+
+	n := 0
+	for _, piece := range split(text) {
+		if _, ok := vocab[piece]; ok {
+			n++
+			continue
+		}
+		n += mergeCount(vocab, piece)
+	}
+	return n
+}
+
+// mergeCount applies byte-pair merges to piece, always merging the adjacent
+// pair with the lowest rank, and returns the number of parts left.
+func mergeCount(vocab map[string]int, piece string) int {
+	// bounds[i] is the start of part i; the last entry is len(piece).
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+
+	for len(bounds) > 2 {
+		best, at := -1, -1
+		for i := 0; i+2 < len(bounds); i++ {
+			rank, ok := vocab[piece[bounds[i]:bounds[i+2]]]
+			if ok && (best < 0 || rank < best) {
+				best, at = rank, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		bounds = append(bounds[:at+1], bounds[at+2:]...)
+	}
+	return len(bounds) - 1
+}
@@ -0,0 +1,90 @@
+package tokens
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// TestVocabularyChecksum pins the embedded vocabulary to the published
+// cl100k_base.tiktoken named in the package documentation.
+func TestVocabularyChecksum(t *testing.T) {
+	zr, err := gzip.NewReader(bytes.NewReader(cl100kBase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, zr); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7"
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		t.Errorf("cl100k_base.tiktoken SHA-256 = %s, want %s", got, want)
+	}
+}
+
+// The expected counts come from tiktoken's cl100k_base encoding.
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"words", "hello world", 2},
+		{"punctuation", "tiktoken is great!", 6},
+		{"contractions", "it's I'LL they're", 7},
+		{"indentation", "    indented  twice", 5},
+		{"newlines", "line\n\n\nbreak \r\n end", 5},
+		{"digits", "1234567", 3},
+		{"cjk", "日本語のテキスト", 8},
+		{"accents and emoji", "naïve café 😀👍🏽", 11},
+		{"sql", "SELECT o.id,\n    u.email\n   FROM public.orders o;", 14},
+		{"toon", "columns:[3]{name,type}:\n  id,bigint", 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.text); got != tt.expected {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"hello world", []string{"hello", " world"}},
+		{"it's", []string{"it", "'s"}},
+		{"a  b", []string{"a", " ", " b"}},
+		{"x\n\n  y", []string{"x", "\n\n", " ", " y"}},
+		{"12345", []string{"123", "45"}},
+		{"a := b;\n", []string{"a", " :=", " b", ";\n"}},
+		{"end  ", []string{"end", "  "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := split(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("split(%q) = %q, want %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	text := "tables:[2]{table_name,schema_name,primary_key_columns[|]}:\n  orders,public,id\n  order_items,public,order_id|sku\n"
+	Count(text)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Count(text)
+	}
+}
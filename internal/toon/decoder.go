@@ -0,0 +1,693 @@
+package toon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Decoder reads a TOON document from an input stream.
+type Decoder struct {
+	r    io.Reader
+	opts DecodeOptions
+}
+
+// NewDecoder creates a decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the rest of the stream as a single document and stores it in
+// v, as Unmarshal does. TOON has no document separator, so a stream holds
+// exactly one value.
+func (d *Decoder) Decode(v interface{}) error {
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return fmt.Errorf("failed to read TOON: %w", err)
+	}
+	return UnmarshalWithOptions(data, v, d.opts)
+}
+
+// SetOptions sets the options used by Decode.
+func (d *Decoder) SetOptions(opts DecodeOptions) {
+	d.opts = opts
+}
+
+// Decode parses TOON text into generic values: map[string]interface{} for
+// objects, []interface{} for arrays, and string, bool, int64, float64 or nil
+// for primitives. It is the inverse of Encode.
+func Decode(data []byte) (interface{}, error) {
+	return DecodeWithOptions(data, DecodeOptions{})
+}
+
+// DecodeWithOptions is Decode with control over path expansion and the
+// expected indentation width.
+func DecodeWithOptions(data []byte, opts DecodeOptions) (interface{}, error) {
+	d, err := newParser(data, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	return d.parse()
+}
+
+// SyntaxError reports a TOON spec violation. Line and Column are 1-based;
+// columns count bytes.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type line struct {
+	num    int
+	depth  int
+	indent int
+	text   string
+}
+
+// errorf returns a SyntaxError at byte offset off of the line's content.
+func (l line) errorf(off int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Line: l.num, Column: l.indent + off + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+type parser struct {
+	lines  []line
+	pos    int
+	expand bool
+
+	// validate collects recoverable problems in issues instead of stopping
+	// at the first one.
+	validate bool
+	issues   []*SyntaxError
+}
+
+// newParser splits data into lines. With validate set, indentation problems
+// are recorded and the line is placed at the deepest level its spaces reach,
+// so parsing carries on and later problems are reported too.
+func newParser(data []byte, opts DecodeOptions, validate bool) (*parser, error) {
+	d := &parser{expand: opts.ExpandPaths, validate: validate}
+	indent := opts.Indent
+	if indent <= 0 {
+		indent = 2
+	}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		content := strings.TrimLeft(raw, " ")
+		width := len(raw) - len(content)
+		if width%indent != 0 {
+			if err := d.problem(&SyntaxError{Line: i + 1, Column: 1, Msg: fmt.Sprintf("indentation is not a multiple of %d", indent)}); err != nil {
+				return nil, err
+			}
+		}
+		if content[0] == '\t' {
+			if err := d.problem(&SyntaxError{Line: i + 1, Column: width + 1, Msg: "tab in indentation"}); err != nil {
+				return nil, err
+			}
+			content = strings.TrimLeft(content, " \t")
+		}
+		d.lines = append(d.lines, line{num: i + 1, depth: width / indent, indent: width, text: content})
+	}
+	return d, nil
+}
+
+// problem handles an error the parser can continue past. Validation records
+// it and carries on; decoding stops there.
+func (d *parser) problem(err *SyntaxError) error {
+	if d.validate {
+		d.issues = append(d.issues, err)
+		return nil
+	}
+	return err
+}
+
+func (d *parser) parse() (interface{}, error) {
+	if len(d.lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	first := d.lines[0]
+	if strings.HasPrefix(first.text, "[") {
+		d.pos++
+		v, err := d.parseArray(first, first.text, 1)
+		if err != nil {
+			return nil, err
+		}
+		return v, d.expectEOF()
+	}
+	if len(d.lines) == 1 && !hasKey(first.text) {
+		d.pos++
+		return d.primitive(first, 0, first.text, 0)
+	}
+
+	v, err := d.parseObject(0)
+	if err != nil {
+		return nil, err
+	}
+	return v, d.expectEOF()
+}
+
+func (d *parser) peek() (line, bool) {
+	if d.pos >= len(d.lines) {
+		return line{}, false
+	}
+	return d.lines[d.pos], true
+}
+
+// capacity bounds a declared array length by the lines left, so a bogus
+// header cannot force a huge allocation.
+func (d *parser) capacity(n int) int {
+	if left := len(d.lines) - d.pos; n > left {
+		return left
+	}
+	return n
+}
+
+func (d *parser) expectEOF() error {
+	if l, ok := d.peek(); ok {
+		return l.errorf(0, "unexpected content %q", l.text)
+	}
+	return nil
+}
+
+// parseObject reads key/value lines at the given depth.
+func (d *parser) parseObject(depth int) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	for {
+		l, ok := d.peek()
+		if !ok || l.depth < depth {
+			return obj, nil
+		}
+		if l.depth > depth {
+			if err := d.problem(&SyntaxError{Line: l.num, Column: 1, Msg: "unexpected indentation"}); err != nil {
+				return nil, err
+			}
+			// Read the over-indented block on its own so the problems
+			// inside it are reported, then carry on at this depth.
+			if _, err := d.parseObject(l.depth); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		d.pos++
+
+		key, rest, err := splitKey(l.text)
+		if err != nil {
+			return nil, l.errorf(0, "%s", err)
+		}
+
+		v, err := d.parseValue(l, rest, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.set(obj, l.text, key, v); err != nil {
+			if err := d.problem(l.errorf(0, "%s", err)); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// set stores v under key, which was read from text. With path expansion an
+// unquoted dotted key is split into nested objects, merging with any that
+// earlier folded keys created.
+func (d *parser) set(obj map[string]interface{}, text, key string, v interface{}) error {
+	path := []string{key}
+	if d.expand && !strings.HasPrefix(text, `"`) && strings.Contains(key, ".") {
+		path = strings.Split(key, ".")
+		for _, seg := range path {
+			if !segmentRe.MatchString(seg) {
+				path = []string{key}
+				break
+			}
+		}
+	}
+
+	for _, seg := range path[:len(path)-1] {
+		next, ok := obj[seg]
+		if !ok {
+			child := map[string]interface{}{}
+			obj[seg] = child
+			obj = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("key %q conflicts with path %q", seg, key)
+		}
+		obj = child
+	}
+
+	leaf := path[len(path)-1]
+	if existing, dup := obj[leaf]; dup {
+		a, aok := existing.(map[string]interface{})
+		b, bok := v.(map[string]interface{})
+		if !d.expand || !aok || !bok {
+			return fmt.Errorf("duplicate key %q", key)
+		}
+		for k, item := range b {
+			if err := d.set(a, `"`, k, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	obj[leaf] = v
+	return nil
+}
+
+// parseValue interprets the text following a key. rest starts with either
+// "[" (an array header) or ":". Array headers are also accepted after the
+// colon ("key:[N]: ..."), which is how Encode writes them.
+func (d *parser) parseValue(l line, rest string, childDepth int) (interface{}, error) {
+	if strings.HasPrefix(rest, "[") {
+		return d.parseArray(l, rest, childDepth)
+	}
+	if strings.HasPrefix(rest, ":[") {
+		return d.parseArray(l, rest[1:], childDepth)
+	}
+
+	value := strings.TrimPrefix(rest[1:], " ")
+	switch value {
+	case "":
+		if next, ok := d.peek(); ok && next.depth >= childDepth {
+			return d.parseObject(childDepth)
+		}
+		return map[string]interface{}{}, nil
+	case "{}":
+		return map[string]interface{}{}, nil
+	}
+
+	return d.primitive(l, len(l.text)-len(value), value, 0)
+}
+
+var headerRe = regexp.MustCompile(`^\[(\d+)([|\t]?)\](?:\{(.*)\})?:(.*)$`)
+
+// parseArray reads an array from its header ("[N]: ...", "[N]{f,...}:" or
+// "[N]:"), which ends line l, and any rows or list items at childDepth. A
+// "|" or tab after the length switches the delimiter for the header's
+// fields and values.
+func (d *parser) parseArray(l line, header string, childDepth int) ([]interface{}, error) {
+	off := len(l.text) - len(header)
+
+	m := headerRe.FindStringSubmatch(header)
+	if m == nil {
+		return nil, l.errorf(off, "malformed array header %q", header)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, l.errorf(off, "invalid array length: %s", err)
+	}
+	delim := Comma
+	if m[2] != "" {
+		delim = Delimiter(m[2][0])
+	}
+	inline := strings.TrimPrefix(m[4], " ")
+
+	switch {
+	case m[3] != "":
+		fields := splitValues(m[3], delim)
+		keys := make([]string, len(fields))
+		lists := make([]Delimiter, len(fields))
+		for i, f := range fields {
+			if keys[i], lists[i], err = parseField(f); err != nil {
+				return nil, l.errorf(off, "%s", err)
+			}
+		}
+		return d.parseRows(l, off, n, keys, lists, delim, childDepth)
+
+	case inline != "":
+		values := splitValues(inline, delim)
+		arr := make([]interface{}, 0, len(values))
+		pos := len(l.text) - len(inline)
+		for _, s := range values {
+			v, err := d.primitive(l, pos, s, delim)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+			pos += len(s) + 1
+		}
+		if len(values) != n {
+			if err := d.problem(l.errorf(off, "array declares %d values, found %d", n, len(values))); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+
+	case n == 0:
+		return []interface{}{}, nil
+
+	default:
+		return d.parseList(l, off, n, childDepth)
+	}
+}
+
+// parseRows reads the tabular rows at depth following header line h. lists
+// holds the item delimiter of each list column and zero for the others.
+func (d *parser) parseRows(h line, off, n int, keys []string, lists []Delimiter, delim Delimiter, depth int) ([]interface{}, error) {
+	arr := make([]interface{}, 0, d.capacity(n))
+	for {
+		l, ok := d.peek()
+		if !ok || l.depth != depth {
+			break
+		}
+		d.pos++
+
+		values := splitValues(l.text, delim)
+		switch {
+		case len(values) > len(keys):
+			extra := len(strings.Join(values[:len(keys)], string(rune(delim))))
+			err := l.errorf(extra, "row has %d values, header declares %d fields; quote values containing %q", len(values), len(keys), rune(delim))
+			if err := d.problem(err); err != nil {
+				return nil, err
+			}
+			values = values[:len(keys)]
+		case len(values) < len(keys):
+			err := l.errorf(len(l.text), "row has %d values, header declares %d fields", len(values), len(keys))
+			if err := d.problem(err); err != nil {
+				return nil, err
+			}
+		}
+
+		row := make(map[string]interface{}, len(keys))
+		pos := 0
+		for i, s := range values {
+			var v interface{}
+			var err error
+			if lists[i] != 0 {
+				v, err = d.listCell(l, pos, s, lists[i])
+			} else {
+				v, err = d.primitive(l, pos, s, delim)
+			}
+			if err != nil {
+				return nil, err
+			}
+			row[keys[i]] = v
+			pos += len(s) + 1
+		}
+		arr = append(arr, row)
+	}
+	if len(arr) != n {
+		if err := d.problem(h.errorf(off, "array declares %d rows, found %d", n, len(arr))); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
+// parseList reads the "- " items at depth following header line h. An
+// object item either starts on the following lines or, as the spec writes
+// it, carries its first field on the hyphen line.
+func (d *parser) parseList(h line, off, n int, depth int) ([]interface{}, error) {
+	arr := make([]interface{}, 0, d.capacity(n))
+	for {
+		l, ok := d.peek()
+		if !ok || l.depth != depth || (l.text != "-" && !strings.HasPrefix(l.text, "- ")) {
+			break
+		}
+		d.pos++
+
+		item := strings.TrimPrefix(strings.TrimPrefix(l.text, "-"), " ")
+		switch {
+		case item == "":
+			obj, err := d.parseObject(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, obj)
+		case item == "{}":
+			arr = append(arr, map[string]interface{}{})
+		case strings.HasPrefix(item, "["):
+			v, err := d.parseArray(l, item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		case hasKey(item):
+			obj, err := d.parseListObject(l, item, depth)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, obj)
+		default:
+			v, err := d.primitive(l, len(l.text)-len(item), item, 0)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	}
+	if len(arr) != n {
+		if err := d.problem(h.errorf(off, "array declares %d items, found %d", n, len(arr))); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
+// parseListObject reads an object whose first field shares the hyphen line.
+// Its remaining fields sit one level below the hyphen.
+func (d *parser) parseListObject(l line, item string, depth int) (map[string]interface{}, error) {
+	key, rest, err := splitKey(item)
+	if err != nil {
+		return nil, l.errorf(len(l.text)-len(item), "%s", err)
+	}
+	first, err := d.parseValue(l, rest, depth+2)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := d.parseObject(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.set(obj, item, key, first); err != nil {
+		if err := d.problem(l.errorf(len(l.text)-len(item), "%s", err)); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// listCell parses a list column's cell at byte offset off: items separated
+// by sub, an empty cell for an empty list, or null.
+func (d *parser) listCell(l line, off int, s string, sub Delimiter) (interface{}, error) {
+	switch s {
+	case "null":
+		return nil, nil
+	case "":
+		return []interface{}{}, nil
+	}
+
+	items := splitValues(s, sub)
+	list := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		v, err := d.primitive(l, off, item, sub)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		off += len(item) + 1
+	}
+	return list, nil
+}
+
+// primitive parses the token s found at byte offset off of line l. delim is
+// the delimiter s was split on, or zero for a whole value; validation uses it
+// to reject strings that should have been quoted.
+func (d *parser) primitive(l line, off int, s string, delim Delimiter) (interface{}, error) {
+	v, err := parsePrimitive(s)
+	if err != nil {
+		var esc *escapeError
+		if errors.As(err, &esc) {
+			off += esc.off + 1
+		}
+		return s, d.problem(l.errorf(off, "%s", err))
+	}
+
+	if str, ok := v.(string); ok && d.validate && !strings.HasPrefix(s, `"`) && needsQuoting(str, delim) {
+		return v, d.problem(l.errorf(off, "string %q must be quoted", str))
+	}
+	return v, nil
+}
+
+// hasKey reports whether a line starts with a key followed by ":" or "[".
+func hasKey(text string) bool {
+	_, _, err := splitKey(text)
+	return err == nil
+}
+
+// splitKey separates a leading key from the rest of the line, which begins
+// with ":" or "[".
+func splitKey(text string) (string, string, error) {
+	if strings.HasPrefix(text, `"`) {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key, err := unescapeString(text[1:end])
+		if err != nil {
+			return "", "", err
+		}
+		rest := text[end+1:]
+		if !strings.HasPrefix(rest, ":") && !strings.HasPrefix(rest, "[") {
+			return "", "", fmt.Errorf("expected ':' after key %q", key)
+		}
+		return key, rest, nil
+	}
+
+	i := strings.IndexAny(text, ":[")
+	if i <= 0 {
+		return "", "", fmt.Errorf("expected key in %q", text)
+	}
+	return text[:i], text[i:], nil
+}
+
+// parseField reads a tabular header field: a key, optionally followed by
+// "[d]" to mark a list column whose items are separated by d.
+func parseField(f string) (string, Delimiter, error) {
+	var sub Delimiter
+	if n := len(f); n >= 4 && f[n-3] == '[' && f[n-1] == ']' {
+		switch d := Delimiter(f[n-2]); d {
+		case Comma, Tab, Pipe:
+			sub, f = d, f[:n-3]
+		}
+	}
+	key, err := parseKey(f)
+	return key, sub, err
+}
+
+func parseKey(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		if len(s) < 2 || closingQuote(s) != len(s)-1 {
+			return "", fmt.Errorf("malformed quoted key %s", s)
+		}
+		return unescapeString(s[1 : len(s)-1])
+	}
+	return s, nil
+}
+
+var numberRe = regexp.MustCompile(`^-?(?:0|[1-9][0-9]*)(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?$`)
+
+// parsePrimitive converts a single token to a Go value.
+func parsePrimitive(s string) (interface{}, error) {
+	switch s {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	if strings.HasPrefix(s, `"`) {
+		if len(s) < 2 || closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("malformed quoted string %s", s)
+		}
+		return unescapeString(s[1 : len(s)-1])
+	}
+
+	if numberRe.MatchString(s) {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s: %w", s, err)
+		}
+		// 1.0 and 1e3 are whole numbers; canonical TOON writes them as
+		// integers, so decode them as such
+		if f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			return int64(f), nil
+		}
+		return f, nil
+	}
+
+	return s, nil
+}
+
+// splitValues splits a delimited list, keeping quoted delimiters intact. An
+// unterminated quote runs to the end of s, where parsePrimitive rejects it.
+func splitValues(s string, delim Delimiter) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			end := closingQuote(s[i:])
+			if end < 0 {
+				return append(values, s[start:])
+			}
+			i += end
+		case byte(delim):
+			values = append(values, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(values, s[start:])
+}
+
+// closingQuote returns the index of the quote closing the string that opens
+// at s[0], or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// escapeError is an invalid escape sequence at byte offset off.
+type escapeError struct {
+	off int
+	msg string
+}
+
+func (e *escapeError) Error() string { return e.msg }
+
+func unescapeString(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", &escapeError{off: i - 1, msg: fmt.Sprintf("unterminated escape in %q", s)}
+		}
+		switch s[i] {
+		case '\\':
+			sb.WriteByte('\\')
+		case '"':
+			sb.WriteByte('"')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		default:
+			return "", &escapeError{off: i - 1, msg: fmt.Sprintf("invalid escape \\%c", s[i])}
+		}
+	}
+	return sb.String(), nil
+}
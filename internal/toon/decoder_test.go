@@ -0,0 +1,90 @@
+package toon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{"string", "hello world"},
+		{"quoted string", "say \"hi\"\n"},
+		{"number", int64(42)},
+		{"float", 3.25},
+		{"null", nil},
+		{"inline array", []interface{}{"a", "b,c", int64(1), true, nil}},
+		{"empty array", []interface{}{}},
+		{"object", map[string]interface{}{
+			"name":  "users",
+			"count": int64(3),
+			"empty": map[string]interface{}{},
+			"none":  []interface{}{},
+			"nested": map[string]interface{}{
+				"deep":    map[string]interface{}{"flag": false},
+				"numbers": []interface{}{int64(1), int64(2)},
+			},
+		}},
+		{"tabular", map[string]interface{}{
+			"columns": []interface{}{
+				map[string]interface{}{"column_name": "id", "nullable": false, "default_value": "nextval('s'::regclass)"},
+				map[string]interface{}{"column_name": "note", "nullable": true, "default_value": "a: b, c"},
+			},
+		}},
+		{"list", map[string]interface{}{
+			"indexes": []interface{}{
+				map[string]interface{}{"index_name": "a", "columns": []interface{}{"x", "y"}},
+				map[string]interface{}{},
+				"plain",
+				[]interface{}{"p", "q"},
+			},
+		}},
+		{"odd keys", map[string]interface{}{
+			"has space": "1",
+			"with:colon": map[string]interface{}{
+				"123": "-flag",
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			got, err := Decode([]byte(encoded))
+			if err != nil {
+				t.Fatalf("Decode() error = %v\n%s", err, encoded)
+			}
+			if !reflect.DeepEqual(got, tt.input) {
+				t.Errorf("Decode() = %#v, want %#v\n%s", got, tt.input, encoded)
+			}
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"odd indentation", "a:\n   b: 1"},
+		{"unexpected indentation", "a: 1\n    b: 2"},
+		{"duplicate key", "a: 1\na: 2"},
+		{"short row", "rows[1]{a,b}:\n  1"},
+		{"unterminated string", `a: "open`},
+		{"bad escape", `a: "\q"`},
+		{"malformed header", "a[x]: 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode([]byte(tt.input)); err == nil {
+				t.Errorf("Decode(%q) error = nil", tt.input)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package toon
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -9,48 +12,79 @@ import (
 
 // Encoder encodes data to TOON format.
 type Encoder struct {
-	sb         strings.Builder
-	indent     int
-	indentSize int
+	buf    strings.Builder
+	w      stringWriter
+	indent int
+	opts   EncodeOptions
 }
 
-// NewEncoder creates a new TOON encoder.
+// stringWriter is satisfied by strings.Builder and bufio.Writer.
+type stringWriter interface {
+	WriteString(s string) (int, error)
+	WriteByte(c byte) error
+}
+
+// NewEncoder creates a new TOON encoder that buffers its output in memory.
 func NewEncoder() *Encoder {
-	return &Encoder{
-		indentSize: 2,
+	return newEncoder(EncodeOptions{})
+}
+
+func newEncoder(opts EncodeOptions) *Encoder {
+	e := &Encoder{
+		opts: opts.withDefaults(),
 	}
+	e.w = &e.buf
+	return e
 }
 
-// Encode encodes a value to TOON format.
+// Encode encodes a value to TOON format. Struct fields are written in
+// declaration order and map keys in sorted order, so equal values always
+// produce identical output.
 func Encode(v interface{}) (string, error) {
-	e := NewEncoder()
-	if err := e.encode(v, true); err != nil {
+	return EncodeWithOptions(v, EncodeOptions{})
+}
+
+// EncodeWithOptions encodes a value to TOON using the given delimiter, key
+// folding and indentation.
+func EncodeWithOptions(v interface{}, opts EncodeOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	val, err := normalize(reflect.ValueOf(v))
+	if err != nil {
 		return "", err
 	}
-	return e.sb.String(), nil
+
+	e := newEncoder(opts)
+	if err := e.encode(val, true); err != nil {
+		return "", err
+	}
+	return e.buf.String(), nil
 }
 
 func (e *Encoder) encode(v interface{}, root bool) error {
 	switch val := v.(type) {
 	case nil:
-		e.sb.WriteString("null")
+		e.w.WriteString("null")
 	case bool:
 		if val {
-			e.sb.WriteString("true")
+			e.w.WriteString("true")
 		} else {
-			e.sb.WriteString("false")
+			e.w.WriteString("false")
 		}
-	case int:
-		e.sb.WriteString(strconv.Itoa(val))
 	case int64:
-		e.sb.WriteString(strconv.FormatInt(val, 10))
+		e.w.WriteString(strconv.FormatInt(val, 10))
+	case uint64:
+		e.w.WriteString(strconv.FormatUint(val, 10))
 	case float64:
-		e.sb.WriteString(formatNumber(val))
+		e.w.WriteString(formatNumber(val))
+	case json.Number:
+		e.w.WriteString(formatJSONNumber(val))
 	case string:
-		e.sb.WriteString(quoteString(val, ','))
+		e.w.WriteString(quoteString(val, e.opts.Delimiter))
 	case []interface{}:
 		return e.encodeArray(val)
-	case map[string]interface{}:
+	case *object:
 		return e.encodeObject(val, root)
 	default:
 		return fmt.Errorf("unsupported type: %T", v)
@@ -58,62 +92,97 @@ func (e *Encoder) encode(v interface{}, root bool) error {
 	return nil
 }
 
-func (e *Encoder) encodeObject(obj map[string]interface{}, root bool) error {
-	keys := make([]string, 0, len(obj))
-	for k := range obj {
-		keys = append(keys, k)
+func (e *Encoder) encodeObject(obj *object, root bool) error {
+	for i, k := range obj.keys {
+		if i > 0 {
+			e.w.WriteString("\n")
+		}
+		if err := e.encodeField(k, obj.values[i], root && i == 0); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	for i, k := range keys {
-		if !root || i > 0 {
-			e.writeIndent()
-		}
-		e.sb.WriteString(formatKey(k))
-		e.sb.WriteString(":")
-
-		v := obj[k]
-		switch val := v.(type) {
-		case map[string]interface{}:
-			if len(val) == 0 {
-				e.sb.WriteString(" {}")
-			} else {
-				e.sb.WriteString("\n")
-				e.indent++
-				if err := e.encodeObject(val, false); err != nil {
-					return err
-				}
-				e.indent--
-			}
-		case []interface{}:
-			if err := e.encodeArrayValue(val); err != nil {
-				return err
-			}
-		default:
-			e.sb.WriteString(" ")
-			if err := e.encode(v, false); err != nil {
-				return err
-			}
+// encodeField writes one "key: value" entry at the current indentation.
+func (e *Encoder) encodeField(k string, v interface{}, first bool) error {
+	if !first {
+		e.writeIndent()
+	}
+	if e.opts.KeyFolding {
+		k, v = e.foldKey(k, v)
+	} else {
+		k = e.formatKey(k)
+	}
+	e.w.WriteString(k)
+	e.w.WriteString(":")
+
+	switch val := v.(type) {
+	case *object:
+		if len(val.keys) == 0 {
+			e.w.WriteString(" {}")
+			return nil
 		}
+		e.w.WriteString("\n")
+		e.indent++
+		err := e.encodeObject(val, false)
+		e.indent--
+		return err
+	case []interface{}:
+		return e.encodeArrayValue(val)
+	default:
+		e.w.WriteString(" ")
+		return e.encode(v, false)
+	}
+}
 
-		if i < len(keys)-1 {
-			e.sb.WriteString("\n")
+// foldKey follows a chain of single-key objects below k and returns the
+// dotted path to write along with the value at its end. Keys that are not
+// plain identifiers stop the chain and, when they contain a dot, are quoted.
+func (e *Encoder) foldKey(k string, v interface{}) (string, interface{}) {
+	if !segmentRe.MatchString(k) {
+		return e.formatKey(k), v
+	}
+
+	path := k
+	for {
+		obj, ok := v.(*object)
+		if !ok || len(obj.keys) != 1 || !segmentRe.MatchString(obj.keys[0]) {
+			return path, v
 		}
+		path += "." + obj.keys[0]
+		v = obj.values[0]
 	}
-	return nil
+}
+
+// formatKey quotes keys as formatKey does and, with key folding enabled,
+// also any key containing a dot so it cannot be read as a folded path.
+func (e *Encoder) formatKey(k string) string {
+	if e.opts.KeyFolding && strings.Contains(k, ".") {
+		return `"` + escapeString(k) + `"`
+	}
+	return formatKey(k)
+}
+
+// header returns an array header such as "[3]", "[3|]" or "[3\t]".
+func (e *Encoder) header(n int) string {
+	return fmt.Sprintf("[%d%s]", n, e.opts.Delimiter.marker())
 }
 
 func (e *Encoder) encodeArrayValue(arr []interface{}) error {
 	if len(arr) == 0 {
-		e.sb.WriteString(fmt.Sprintf("[%d]:", len(arr)))
+		e.w.WriteString(e.header(0) + ":")
 		return nil
 	}
 
+	delim := string(rune(e.opts.Delimiter))
+
 	// Check if all elements are primitives (inline array)
 	if allPrimitives(arr) {
-		e.sb.WriteString(fmt.Sprintf("[%d]: ", len(arr)))
+		e.w.WriteString(e.header(len(arr)) + ": ")
 		for i, v := range arr {
 			if i > 0 {
-				e.sb.WriteString(",")
+				e.w.WriteString(delim)
 			}
 			if err := e.encode(v, false); err != nil {
 				return err
@@ -123,22 +192,16 @@ func (e *Encoder) encodeArrayValue(arr []interface{}) error {
 	}
 
 	// Check if all elements are uniform objects (tabular array)
-	if fields, ok := uniformObjectFields(arr); ok && len(fields) > 0 {
-		e.sb.WriteString(fmt.Sprintf("[%d]{%s}:\n", len(arr), strings.Join(fields, ",")))
+	if fields, lists, ok := uniformObjectFields(arr, e.opts.InlineLists); ok && len(fields) > 0 {
+		e.w.WriteString(e.header(len(arr)) + e.fieldList(fields, lists) + ":\n")
 		e.indent++
 		for i, v := range arr {
 			e.writeIndent()
-			obj := v.(map[string]interface{})
-			for j, f := range fields {
-				if j > 0 {
-					e.sb.WriteString(",")
-				}
-				if err := e.encode(obj[f], false); err != nil {
-					return err
-				}
+			if err := e.encodeRow(v.(*object), lists); err != nil {
+				return err
 			}
 			if i < len(arr)-1 {
-				e.sb.WriteString("\n")
+				e.w.WriteString("\n")
 			}
 		}
 		e.indent--
@@ -146,43 +209,99 @@ func (e *Encoder) encodeArrayValue(arr []interface{}) error {
 	}
 
 	// Mixed array - use dash notation
-	e.sb.WriteString(fmt.Sprintf("[%d]:\n", len(arr)))
+	e.w.WriteString(e.header(len(arr)) + ":\n")
 	e.indent++
 	for i, v := range arr {
-		e.writeIndent()
-		e.sb.WriteString("- ")
-		switch val := v.(type) {
-		case map[string]interface{}:
-			if len(val) == 0 {
-				e.sb.WriteString("{}")
-			} else {
-				e.sb.WriteString("\n")
-				e.indent++
-				if err := e.encodeObject(val, false); err != nil {
-					return err
-				}
-				e.indent--
-			}
-		default:
-			if err := e.encode(v, false); err != nil {
-				return err
-			}
+		if err := e.encodeListItem(v); err != nil {
+			return err
 		}
 		if i < len(arr)-1 {
-			e.sb.WriteString("\n")
+			e.w.WriteString("\n")
 		}
 	}
 	e.indent--
 	return nil
 }
 
+// encodeRow writes the cells of one tabular row.
+func (e *Encoder) encodeRow(obj *object, lists []bool) error {
+	for j, val := range obj.values {
+		if j > 0 {
+			e.w.WriteString(string(rune(e.opts.Delimiter)))
+		}
+		if lists[j] {
+			e.encodeListCell(val)
+			continue
+		}
+		if err := e.encode(val, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeListItem writes one "- " item of a mixed array.
+func (e *Encoder) encodeListItem(v interface{}) error {
+	e.writeIndent()
+	e.w.WriteString("- ")
+	obj, ok := v.(*object)
+	if !ok {
+		return e.encode(v, false)
+	}
+	if len(obj.keys) == 0 {
+		e.w.WriteString("{}")
+		return nil
+	}
+	e.w.WriteString("\n")
+	e.indent++
+	err := e.encodeObject(obj, false)
+	e.indent--
+	return err
+}
+
 func (e *Encoder) encodeArray(arr []interface{}) error {
 	return e.encodeArrayValue(arr)
 }
 
+// fieldList writes the "{a,b}" field names of a tabular header using the
+// active delimiter. List columns carry their item delimiter: "{a,b[|]}".
+func (e *Encoder) fieldList(fields []string, lists []bool) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = e.formatKey(f)
+		if lists != nil && lists[i] {
+			names[i] += "[" + string(rune(e.opts.ListDelimiter())) + "]"
+		}
+	}
+	return "{" + strings.Join(names, string(rune(e.opts.Delimiter))) + "}"
+}
+
+// encodeListCell writes a list column's cell: the items joined by the list
+// delimiter, nothing for an empty list, or null. Strings are quoted when
+// they contain either delimiter.
+func (e *Encoder) encodeListCell(v interface{}) {
+	list, ok := v.([]interface{})
+	if !ok {
+		e.w.WriteString("null")
+		return
+	}
+
+	sub := e.opts.ListDelimiter()
+	for i, item := range list {
+		if i > 0 {
+			e.w.WriteString(string(rune(sub)))
+		}
+		if s, ok := item.(string); ok && (needsQuoting(s, e.opts.Delimiter) || needsQuoting(s, sub)) {
+			e.w.WriteString(`"` + escapeString(s) + `"`)
+			continue
+		}
+		e.encode(item, false)
+	}
+}
+
 func (e *Encoder) writeIndent() {
-	for i := 0; i < e.indent*e.indentSize; i++ {
-		e.sb.WriteByte(' ')
+	for i := 0; i < e.indent*e.opts.Indent; i++ {
+		e.w.WriteByte(' ')
 	}
 }
 
@@ -191,12 +310,23 @@ func formatNumber(f float64) string {
 	if f == 0 {
 		return "0"
 	}
-	// Check if it's an integer
-	if f == float64(int64(f)) {
+	// Whole numbers within int64 range are written as integers
+	if f == math.Trunc(f) && math.Abs(f) < 1<<63 {
 		return strconv.FormatInt(int64(f), 10)
 	}
-	s := strconv.FormatFloat(f, 'f', -1, 64)
-	return s
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatJSONNumber writes integers exactly and other numbers in the same
+// canonical form as formatNumber.
+func formatJSONNumber(n json.Number) string {
+	if i, err := n.Int64(); err == nil {
+		return strconv.FormatInt(i, 10)
+	}
+	if f, err := n.Float64(); err == nil {
+		return formatNumber(f)
+	}
+	return n.String()
 }
 
 var (
@@ -209,18 +339,18 @@ func formatKey(k string) string {
 	if unquotedKeyRe.MatchString(k) {
 		return k
 	}
-	return quoteString(k, ',')
+	return quoteString(k, Comma)
 }
 
 // quoteString quotes a string if necessary per TOON spec.
-func quoteString(s string, delim byte) string {
+func quoteString(s string, delim Delimiter) string {
 	if needsQuoting(s, delim) {
 		return `"` + escapeString(s) + `"`
 	}
 	return s
 }
 
-func needsQuoting(s string, delim byte) bool {
+func needsQuoting(s string, delim Delimiter) bool {
 	if s == "" {
 		return true
 	}
@@ -241,7 +371,7 @@ func needsQuoting(s string, delim byte) bool {
 		case ':', '"', '\\', '[', ']', '{', '}', '\n', '\r', '\t':
 			return true
 		}
-		if byte(r) == delim {
+		if r == rune(delim) {
 			return true
 		}
 		if r < 32 {
@@ -274,54 +404,88 @@ func escapeString(s string) string {
 
 func allPrimitives(arr []interface{}) bool {
 	for _, v := range arr {
-		switch v.(type) {
-		case nil, bool, int, int64, float64, string:
-			continue
-		default:
+		if !isPrimitive(v) {
 			return false
 		}
 	}
 	return true
 }
 
-func uniformObjectFields(arr []interface{}) ([]string, bool) {
+func isPrimitive(v interface{}) bool {
+	switch v.(type) {
+	case nil, bool, int, int64, uint64, float64, json.Number, string:
+		return true
+	}
+	return false
+}
+
+// uniformObjectFields returns the shared keys when every element is an
+// object with the same keys in the same order and only primitive values.
+// With lists set, a column may instead hold inline lists and nulls; lists
+// marks those columns.
+func uniformObjectFields(arr []interface{}, lists bool) ([]string, []bool, bool) {
 	if len(arr) == 0 {
-		return nil, false
+		return nil, nil, false
 	}
 
 	var fields []string
+	var listCols, primCols []bool
 	for i, v := range arr {
-		obj, ok := v.(map[string]interface{})
+		obj, ok := v.(*object)
 		if !ok {
-			return nil, false
-		}
-
-		// Check all values are primitives
-		for _, val := range obj {
-			switch val.(type) {
-			case nil, bool, int, int64, float64, string:
-				continue
-			default:
-				return nil, false
-			}
+			return nil, nil, false
 		}
 
 		if i == 0 {
-			fields = make([]string, 0, len(obj))
-			for k := range obj {
-				fields = append(fields, k)
-			}
+			fields = obj.keys
+			listCols = make([]bool, len(fields))
+			primCols = make([]bool, len(fields))
 		} else {
-			// Check same fields
-			if len(obj) != len(fields) {
-				return nil, false
+			if len(obj.keys) != len(fields) {
+				return nil, nil, false
 			}
-			for _, f := range fields {
-				if _, ok := obj[f]; !ok {
-					return nil, false
+			for j, k := range obj.keys {
+				if k != fields[j] {
+					return nil, nil, false
 				}
 			}
 		}
+
+		for j, val := range obj.values {
+			switch {
+			case val == nil:
+			case isPrimitive(val):
+				primCols[j] = true
+			case lists && inlineList(val, len(fields)):
+				listCols[j] = true
+			default:
+				return nil, nil, false
+			}
+		}
+	}
+
+	// A column mixing lists and other primitives would read back as lists
+	for j := range fields {
+		if listCols[j] && primCols[j] {
+			return nil, nil, false
+		}
 	}
-	return fields, true
+	return fields, listCols, true
+}
+
+// inlineList reports whether v is an array that fits in a tabular cell:
+// primitives only, and no nulls, which a cell could not tell apart from a
+// null list. An empty list in a one-field row would leave a blank line, so
+// that is refused too.
+func inlineList(v interface{}, fields int) bool {
+	list, ok := v.([]interface{})
+	if !ok || (len(list) == 0 && fields == 1) {
+		return false
+	}
+	for _, item := range list {
+		if item == nil || !isPrimitive(item) {
+			return false
+		}
+	}
+	return true
 }
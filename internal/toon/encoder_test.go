@@ -1,6 +1,7 @@
 package toon
 
 import (
+	"math"
 	"strings"
 	"testing"
 )
@@ -140,7 +141,7 @@ func TestFormatKey(t *testing.T) {
 func TestNeedsQuoting(t *testing.T) {
 	tests := []struct {
 		input    string
-		delim    byte
+		delim    Delimiter
 		expected bool
 	}{
 		{"simple", ',', false},
@@ -154,6 +155,9 @@ func TestNeedsQuoting(t *testing.T) {
 		{"has,comma", ',', true},
 		{"no comma", ';', false},
 		{"has;semi", ';', true},
+		{"has,comma", Pipe, false},
+		{"has|pipe", Pipe, true},
+		{"has\ttab", Tab, true},
 	}
 
 	for _, tt := range tests {
@@ -188,3 +192,107 @@ func TestAllPrimitives(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshal_LargeIntegers(t *testing.T) {
+	input := struct {
+		Max int64   `json:"max"`
+		Min int64   `json:"min"`
+		Fr  float64 `json:"fraction"`
+	}{Max: 9223372036854775807, Min: -9223372036854775808, Fr: 0.00001}
+
+	data, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"max: 9223372036854775807", "min: -9223372036854775808", "fraction: 0.00001"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in %q", want, data)
+		}
+	}
+}
+
+type orderedBase struct {
+	Schema string `json:"schema_name,omitempty"`
+}
+
+type orderedRow struct {
+	Name     string `json:"name"`
+	Nullable bool   `json:"nullable"`
+}
+
+type orderedTable struct {
+	Zeta    string            `json:"zeta"`
+	Alpha   int               `json:"alpha"`
+	Skipped string            `json:"-"`
+	Empty   []string          `json:"empty,omitempty"`
+	Ptr     *string           `json:"ptr,omitempty"`
+	Labels  map[string]string `json:"labels"`
+	Rows    []orderedRow      `json:"rows"`
+	orderedBase
+	hidden string
+}
+
+func TestMarshal_StructOrder(t *testing.T) {
+	input := orderedTable{
+		Zeta:        "z",
+		Alpha:       1,
+		Skipped:     "no",
+		Labels:      map[string]string{"b": "2", "a": "1", "c": "3"},
+		Rows:        []orderedRow{{"id", false}, {"email", true}},
+		orderedBase: orderedBase{Schema: "public"},
+		hidden:      "no",
+	}
+
+	want := strings.Join([]string{
+		"zeta: z",
+		"alpha: 1",
+		"labels:",
+		`  a: "1"`,
+		`  b: "2"`,
+		`  c: "3"`,
+		"rows:[2]{name,nullable}:",
+		"  id,false",
+		"  email,true",
+		"schema_name: public",
+	}, "\n")
+
+	for i := 0; i < 20; i++ {
+		got, err := Marshal(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestEncode_SortedMapKeys(t *testing.T) {
+	input := map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"z": 1, "a": 2},
+			map[string]interface{}{"a": 3, "z": 4},
+		},
+		"b": true,
+		"a": map[string]interface{}{"y": nil, "x": "x"},
+	}
+
+	want := "a:\n  x: x\n  y: null\nb: true\nrows:[2]{a,z}:\n  2,1\n  3,4"
+	for i := 0; i < 20; i++ {
+		got, err := Encode(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	for _, v := range []interface{}{math.NaN(), make(chan int), map[float64]string{1: "x"}} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) error = nil", v)
+		}
+	}
+}
@@ -5,27 +5,24 @@ import (
 	"fmt"
 )
 
-// Marshal encodes a Go value to TOON format.
-// It first converts to a generic map/slice structure, then encodes to TOON.
+// Marshal encodes a Go value to TOON format. Struct fields honor json tags,
+// including omitempty, and are written in declaration order; map keys are
+// sorted.
 func Marshal(v interface{}) ([]byte, error) {
-	// Use JSON as intermediate to handle struct tags and complex types
-	jsonBytes, err := json.Marshal(v)
+	toon, err := Encode(v)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal to JSON: %w", err)
-	}
-
-	// Parse JSON into generic structure
-	var generic interface{}
-	if err := json.Unmarshal(jsonBytes, &generic); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+		return nil, fmt.Errorf("failed to encode TOON: %w", err)
 	}
+	return []byte(toon), nil
+}
 
-	// Encode to TOON
-	toon, err := Encode(generic)
+// MarshalWithOptions encodes a Go value to TOON with the given delimiter, key
+// folding and indentation.
+func MarshalWithOptions(v interface{}, opts EncodeOptions) ([]byte, error) {
+	toon, err := EncodeWithOptions(v, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode TOON: %w", err)
 	}
-
 	return []byte(toon), nil
 }
 
@@ -33,3 +30,32 @@ func Marshal(v interface{}) ([]byte, error) {
 func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 	return Marshal(v)
 }
+
+// Unmarshal decodes TOON data into v. Decoding into an *interface{} yields the
+// generic values returned by Decode; other targets are filled through
+// encoding/json so struct tags apply exactly as they do for Marshal.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v, DecodeOptions{})
+}
+
+// UnmarshalWithOptions is Unmarshal with path expansion control.
+func UnmarshalWithOptions(data []byte, v interface{}, opts DecodeOptions) error {
+	generic, err := DecodeWithOptions(data, opts)
+	if err != nil {
+		return fmt.Errorf("failed to decode TOON: %w", err)
+	}
+
+	if p, ok := v.(*interface{}); ok {
+		*p = generic
+		return nil
+	}
+
+	jsonBytes, err := json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("failed to marshal to JSON: %w", err)
+	}
+	if err := json.Unmarshal(jsonBytes, v); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package toon
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Delimiter separates values in inline arrays and tabular rows. Tab and pipe
+// are declared in each array header ("key[N|]: a|b"); comma is the default
+// and is left implicit.
+type Delimiter byte
+
+const (
+	Comma Delimiter = ','
+	Tab   Delimiter = '\t'
+	Pipe  Delimiter = '|'
+)
+
+// ParseDelimiter accepts "comma", "tab" or "pipe".
+func ParseDelimiter(name string) (Delimiter, error) {
+	switch name {
+	case "comma", ",":
+		return Comma, nil
+	case "tab", "\t":
+		return Tab, nil
+	case "pipe", "|":
+		return Pipe, nil
+	}
+	return 0, fmt.Errorf("unknown delimiter %q (want comma, tab or pipe)", name)
+}
+
+// String returns the delimiter's name as accepted by ParseDelimiter.
+func (d Delimiter) String() string {
+	switch d {
+	case Tab:
+		return "tab"
+	case Pipe:
+		return "pipe"
+	}
+	return "comma"
+}
+
+// marker is the symbol written inside an array header's brackets.
+func (d Delimiter) marker() string {
+	if d == Comma {
+		return ""
+	}
+	return string(rune(d))
+}
+
+// EncodeOptions controls the layout of encoded TOON. The zero value encodes
+// as Encode does: comma delimiters, no key folding and two-space indentation.
+type EncodeOptions struct {
+	// Delimiter separates inline array values and tabular row cells.
+	Delimiter Delimiter
+
+	// KeyFolding collapses chains of single-key objects into dotted paths,
+	// writing "a.b.c: v" instead of three nested blocks. Literal keys that
+	// contain a dot are quoted so folded documents decode unambiguously
+	// with DecodeOptions.ExpandPaths.
+	KeyFolding bool
+
+	// Indent is the number of spaces per nesting level.
+	Indent int
+
+	// InlineLists keeps arrays of objects tabular when some fields hold
+	// arrays of primitives, writing each such array inside its row cell.
+	// The header marks these columns with the delimiter between items,
+	// "|" or, when Delimiter is Pipe, ",":
+	//
+	//	edges:[2]{name,columns[|]}:
+	//	  orders_user_fk,user_id
+	//	  order_items_pk,order_id|product_id
+	//
+	// An empty cell is an empty list. This extends the TOON spec; Decode
+	// reads it regardless of options.
+	InlineLists bool
+}
+
+// ListDelimiter separates the items of an inline list cell.
+func (o EncodeOptions) ListDelimiter() Delimiter {
+	if o.Delimiter == Pipe {
+		return Comma
+	}
+	return Pipe
+}
+
+func (o EncodeOptions) withDefaults() EncodeOptions {
+	if o.Delimiter == 0 {
+		o.Delimiter = Comma
+	}
+	if o.Indent <= 0 {
+		o.Indent = 2
+	}
+	return o
+}
+
+// Validate reports options that cannot be encoded.
+func (o EncodeOptions) Validate() error {
+	switch o.Delimiter {
+	case 0, Comma, Tab, Pipe:
+	default:
+		return fmt.Errorf("unsupported delimiter %q", rune(o.Delimiter))
+	}
+	if o.Indent < 0 {
+		return fmt.Errorf("indent must not be negative, got %d", o.Indent)
+	}
+	return nil
+}
+
+// DecodeOptions controls how TOON text is interpreted. Delimiters need no
+// option: each array header declares its own.
+type DecodeOptions struct {
+	// ExpandPaths splits unquoted dotted keys ("a.b.c: v") back into nested
+	// objects, reversing EncodeOptions.KeyFolding. Quoted keys are never
+	// split.
+	ExpandPaths bool
+
+	// Indent is the number of spaces per nesting level, 2 when zero.
+	Indent int
+}
+
+// segmentRe matches a key that may take part in a folded path.
+var segmentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
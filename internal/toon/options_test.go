@@ -0,0 +1,379 @@
+package toon
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type optionsDoc struct {
+	Name    string            `json:"name"`
+	Tags    []string          `json:"tags"`
+	Rows    []optionsRow      `json:"rows"`
+	Meta    optionsMeta       `json:"meta"`
+	Labels  map[string]string `json:"labels"`
+	Comment string            `json:"comment"`
+}
+
+type optionsRow struct {
+	ID   int    `json:"id"`
+	Note string `json:"note"`
+}
+
+type optionsMeta struct {
+	Source optionsSource `json:"source"`
+}
+
+type optionsSource struct {
+	Kind  string   `json:"kind"`
+	Files []string `json:"files"`
+}
+
+func optionsFixture() optionsDoc {
+	return optionsDoc{
+		Name: "orders",
+		Tags: []string{"core", "a,b", "x|y"},
+		Rows: []optionsRow{{1, "first, second"}, {2, "pipe|kept"}},
+		Meta: optionsMeta{Source: optionsSource{Kind: "ddl", Files: []string{"a.sql"}}},
+		Labels: map[string]string{
+			"owner.team": "billing",
+		},
+		Comment: "plain, text",
+	}
+}
+
+func TestEncodeWithOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts EncodeOptions
+		want []string
+	}{
+		{
+			name: "comma",
+			want: []string{
+				`name: orders`,
+				`tags:[3]: core,"a,b",x|y`,
+				`rows:[2]{id,note}:`,
+				`  1,"first, second"`,
+				`  2,pipe|kept`,
+				`meta:`,
+				`  source:`,
+				`    kind: ddl`,
+				`    files:[1]: a.sql`,
+				`labels:`,
+				`  owner.team: billing`,
+				`comment: "plain, text"`,
+			},
+		},
+		{
+			name: "pipe",
+			opts: EncodeOptions{Delimiter: Pipe},
+			want: []string{
+				`name: orders`,
+				`tags:[3|]: core|a,b|"x|y"`,
+				`rows:[2|]{id|note}:`,
+				`  1|first, second`,
+				`  2|"pipe|kept"`,
+				`meta:`,
+				`  source:`,
+				`    kind: ddl`,
+				`    files:[1|]: a.sql`,
+				`labels:`,
+				`  owner.team: billing`,
+				`comment: plain, text`,
+			},
+		},
+		{
+			name: "tab",
+			opts: EncodeOptions{Delimiter: Tab},
+			want: []string{
+				`name: orders`,
+				"tags:[3\t]: core\ta,b\tx|y",
+				"rows:[2\t]{id\tnote}:",
+				"  1\tfirst, second",
+				"  2\tpipe|kept",
+				`meta:`,
+				`  source:`,
+				`    kind: ddl`,
+				"    files:[1\t]: a.sql",
+				`labels:`,
+				`  owner.team: billing`,
+				`comment: plain, text`,
+			},
+		},
+		{
+			name: "key folding and indent",
+			opts: EncodeOptions{KeyFolding: true, Indent: 4},
+			want: []string{
+				`name: orders`,
+				`tags:[3]: core,"a,b",x|y`,
+				`rows:[2]{id,note}:`,
+				`    1,"first, second"`,
+				`    2,pipe|kept`,
+				`meta.source:`,
+				`    kind: ddl`,
+				`    files:[1]: a.sql`,
+				`labels:`,
+				`    "owner.team": billing`,
+				`comment: "plain, text"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeWithOptions(optionsFixture(), tt.opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("EncodeWithOptions() =\n%s\nwant:\n%s", got, want)
+			}
+
+			var stream bytes.Buffer
+			enc := NewStreamEncoder(&stream)
+			if err := enc.SetOptions(tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if err := enc.Encode(optionsFixture()); err != nil {
+				t.Fatal(err)
+			}
+			if stream.String() != got {
+				t.Errorf("StreamEncoder output =\n%s\nwant:\n%s", stream.String(), got)
+			}
+
+			var decoded optionsDoc
+			dec := DecodeOptions{ExpandPaths: tt.opts.KeyFolding, Indent: tt.opts.Indent}
+			if err := UnmarshalWithOptions([]byte(got), &decoded, dec); err != nil {
+				t.Fatalf("UnmarshalWithOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, optionsFixture()) {
+				t.Errorf("round trip = %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestKeyFolding_Chains(t *testing.T) {
+	input := map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}},
+		"d": map[string]interface{}{"e": []int{1, 2}},
+		"f": map[string]interface{}{"g-h": map[string]interface{}{"i": true}},
+		"k": map[string]interface{}{"l.m": 2},
+		"j": map[string]interface{}{},
+	}
+	want := strings.Join([]string{
+		`a.b.c: 1`,
+		`d.e:[2]: 1,2`,
+		`f:`,
+		`  g-h:`,
+		`    i: true`,
+		`j: {}`,
+		`k:`,
+		`  "l.m": 2`,
+	}, "\n")
+
+	got, err := EncodeWithOptions(input, EncodeOptions{KeyFolding: true})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("EncodeWithOptions() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDecodeWithOptions_ExpandPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    DecodeOptions
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:  "literal without expansion",
+			input: "a.b: 1",
+			want:  map[string]interface{}{"a.b": int64(1)},
+		},
+		{
+			name:  "expanded and merged",
+			input: "a.b: 1\na:\n  c: 2\n\"x.y\": 3",
+			opts:  DecodeOptions{ExpandPaths: true},
+			want: map[string]interface{}{
+				"a":   map[string]interface{}{"b": int64(1), "c": int64(2)},
+				"x.y": int64(3),
+			},
+		},
+		{
+			name:    "conflict",
+			input:   "a: 1\na.b: 2",
+			opts:    DecodeOptions{ExpandPaths: true},
+			wantErr: true,
+		},
+		{
+			name:  "four space indent",
+			input: "a:\n    b:[2|]: x|y",
+			opts:  DecodeOptions{Indent: 4},
+			want:  map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"x", "y"}}},
+		},
+		{
+			name:    "wrong indent",
+			input:   "a:\n  b: 1",
+			opts:    DecodeOptions{Indent: 4},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeWithOptions([]byte(tt.input), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeWithOptions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	for _, name := range []string{"comma", "tab", "pipe"} {
+		d, err := ParseDelimiter(name)
+		if err != nil {
+			t.Fatalf("ParseDelimiter(%q) error = %v", name, err)
+		}
+		if d.String() != name {
+			t.Errorf("ParseDelimiter(%q).String() = %q", name, d.String())
+		}
+	}
+	if _, err := ParseDelimiter("semicolon"); err == nil {
+		t.Error("ParseDelimiter(semicolon) error = nil")
+	}
+}
+
+type inlineEdge struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Targets []string `json:"targets"`
+}
+
+func TestEncodeWithOptions_InlineLists(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		opts  EncodeOptions
+		want  []string
+	}{
+		{
+			name: "list columns",
+			input: map[string]interface{}{"edges": []inlineEdge{
+				{Name: "orders_user_fk", Columns: []string{"user_id"}, Targets: []string{"id"}},
+				{Name: "items_pk", Columns: []string{"order_id", "product_id"}, Targets: []string{}},
+				{Name: "odd", Columns: []string{"a|b", "c,d", "", "null"}},
+			}},
+			opts: EncodeOptions{InlineLists: true},
+			want: []string{
+				`edges:[3]{name,columns[|],targets[|]}:`,
+				`  orders_user_fk,user_id,id`,
+				`  items_pk,order_id|product_id,`,
+				`  odd,"a|b"|"c,d"|""|"null",null`,
+			},
+		},
+		{
+			name: "pipe delimiter",
+			input: map[string]interface{}{"edges": []inlineEdge{
+				{Name: "items_pk", Columns: []string{"order_id", "product_id"}, Targets: []string{"x,y"}},
+			}},
+			opts: EncodeOptions{InlineLists: true, Delimiter: Pipe},
+			want: []string{
+				`edges:[1|]{name|columns[,]|targets[,]}:`,
+				`  items_pk|order_id,product_id|"x,y"`,
+			},
+		},
+		{
+			name: "numbers and booleans",
+			input: map[string]interface{}{"rows": []map[string]interface{}{
+				{"id": 1, "flags": []interface{}{true, 2.5, -3}},
+			}},
+			opts: EncodeOptions{InlineLists: true},
+			want: []string{
+				`rows:[1]{flags[|],id}:`,
+				`  true|2.5|-3,1`,
+			},
+		},
+		{
+			name: "null item falls back to a list",
+			input: map[string]interface{}{"rows": []map[string]interface{}{
+				{"a": []interface{}{"x", nil}},
+			}},
+			opts: EncodeOptions{InlineLists: true},
+			want: []string{
+				`rows:[1]:`,
+				`  - `,
+				`    a:[2]: x,null`,
+			},
+		},
+		{
+			name: "column mixing lists and strings falls back",
+			input: map[string]interface{}{"rows": []map[string]interface{}{
+				{"a": []interface{}{"x"}},
+				{"a": "x"},
+			}},
+			opts: EncodeOptions{InlineLists: true},
+			want: []string{
+				`rows:[2]:`,
+				`  - `,
+				`    a:[1]: x`,
+				`  - `,
+				`    a: x`,
+			},
+		},
+		{
+			name: "disabled",
+			input: map[string]interface{}{"edges": []inlineEdge{
+				{Name: "pk", Columns: []string{"id"}},
+			}},
+			want: []string{
+				`edges:[1]:`,
+				`  - `,
+				`    name: pk`,
+				`    columns:[1]: id`,
+				`    targets: null`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("EncodeWithOptions() =\n%s\nwant:\n%s", got, want)
+			}
+			if issues := Validate([]byte(got), DecodeOptions{}); issues != nil {
+				t.Errorf("Validate() = %v", issues)
+			}
+
+			// The compact form must decode to the same value as the spec form
+			spec, err := Encode(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Decode([]byte(spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := Decode([]byte(got))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, want) {
+				t.Errorf("Decode() = %#v, want %#v", decoded, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,604 @@
+package toon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// StreamEncoder writes TOON to an io.Writer as it is produced. The fields of
+// a root struct or map are written one at a time; slice and array fields
+// (and a root slice) are written one element at a time, after a first pass
+// over the elements that picks the array form without building them. Table
+// writes tabular rows as they arrive. Memory is bounded by the largest
+// single element or non-list field rather than the whole document.
+type StreamEncoder struct {
+	bw     *bufio.Writer
+	enc    *Encoder
+	fields int
+	table  *TableWriter
+}
+
+// NewStreamEncoder creates an encoder writing to w. Output is buffered; call
+// Flush (Encode does so itself) when done.
+func NewStreamEncoder(w io.Writer) *StreamEncoder {
+	bw := bufio.NewWriter(w)
+	return &StreamEncoder{
+		bw:  bw,
+		enc: &Encoder{w: bw, opts: EncodeOptions{}.withDefaults()},
+	}
+}
+
+// SetOptions sets the delimiter, key folding and indentation for everything
+// written afterwards. Call it before the first field.
+func (s *StreamEncoder) SetOptions(opts EncodeOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if s.fields > 0 {
+		return fmt.Errorf("options must be set before writing")
+	}
+	s.enc.opts = opts.withDefaults()
+	return nil
+}
+
+// Encode writes v as a complete document and flushes. The output is
+// identical to Marshal.
+func (s *StreamEncoder) Encode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && !rv.IsNil() && !hasMarshaler(rv) {
+		rv = rv.Elem()
+	}
+
+	var err error
+	switch {
+	case rv.IsValid() && rv.Kind() == reflect.Struct && !hasMarshaler(rv):
+		err = eachStructValue(rv, s.writeValueField)
+	case rv.IsValid() && rv.Kind() == reflect.Map && !rv.IsNil() && !hasMarshaler(rv):
+		err = eachMapEntry(rv, func(key string, fv reflect.Value) error {
+			return s.writeValueField(key, "", fv)
+		})
+	case isList(rv):
+		s.fields++
+		err = s.writeList("", rv)
+	default:
+		err = s.writeValue(rv)
+	}
+	if err != nil {
+		return err
+	}
+	return s.Flush()
+}
+
+// hasMarshaler reports whether v encodes through a marshaler interface and
+// so cannot be streamed field by field.
+func hasMarshaler(v reflect.Value) bool {
+	t := v.Type()
+	if t == jsonNumberType || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	return v.CanAddr() && reflect.PointerTo(t).Implements(jsonMarshalerType)
+}
+
+// writeValue encodes a non-object root value in one piece.
+func (s *StreamEncoder) writeValue(rv reflect.Value) error {
+	item, err := normalize(rv)
+	if err != nil {
+		return err
+	}
+	if obj, ok := item.(*object); ok {
+		for i, k := range obj.keys {
+			if err := s.writeField(k, obj.values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if s.fields > 0 {
+		return fmt.Errorf("cannot write a %T after object fields", item)
+	}
+	s.fields++
+	return s.enc.encode(item, true)
+}
+
+// Field writes one field of the root object.
+func (s *StreamEncoder) Field(key string, v interface{}) error {
+	item, err := normalize(reflect.ValueOf(v))
+	if err != nil {
+		return err
+	}
+	return s.writeField(key, item)
+}
+
+// writeValueField writes one field of a root struct or map, streaming lists
+// element by element and normalizing anything else in one piece.
+func (s *StreamEncoder) writeValueField(key, opts string, fv reflect.Value) error {
+	if !isList(fv) {
+		item, err := normalize(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if hasOption(opts, "string") {
+			item = quoteOption(item)
+		}
+		return s.writeField(key, item)
+	}
+
+	if s.table != nil {
+		return fmt.Errorf("table %q is still open", s.table.key)
+	}
+	if s.fields > 0 {
+		s.enc.w.WriteString("\n")
+	}
+	s.fields++
+	s.enc.indent = 0
+	if err := s.writeList(s.enc.formatKey(key)+":", fv); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *StreamEncoder) writeField(key string, item interface{}) error {
+	if s.table != nil {
+		return fmt.Errorf("table %q is still open", s.table.key)
+	}
+	if s.fields > 0 {
+		s.enc.w.WriteString("\n")
+	}
+	s.fields++
+	s.enc.indent = 0
+	return s.enc.encodeField(key, item, true)
+}
+
+// Table starts a tabular array field of n rows with the given columns. Rows
+// are written with TableWriter.Row; Close must be called before the next
+// field.
+func (s *StreamEncoder) Table(key string, n int, fields ...string) (*TableWriter, error) {
+	if s.table != nil {
+		return nil, fmt.Errorf("table %q is still open", s.table.key)
+	}
+	if n > 0 && len(fields) == 0 {
+		return nil, fmt.Errorf("table %q needs at least one field", key)
+	}
+
+	if s.fields > 0 {
+		s.enc.w.WriteString("\n")
+	}
+	s.fields++
+
+	s.enc.w.WriteString(s.enc.formatKey(key) + ":" + s.enc.header(n))
+	if n > 0 {
+		s.enc.w.WriteString(s.enc.fieldList(fields, nil))
+	}
+	s.enc.w.WriteString(":")
+
+	s.table = &TableWriter{s: s, key: key, n: n, fields: len(fields)}
+	return s.table, nil
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (s *StreamEncoder) Flush() error {
+	if s.table != nil {
+		return fmt.Errorf("table %q is still open", s.table.key)
+	}
+	if err := s.bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write TOON: %w", err)
+	}
+	return nil
+}
+
+// TableWriter writes the rows of a tabular array started with
+// StreamEncoder.Table.
+type TableWriter struct {
+	s      *StreamEncoder
+	key    string
+	n      int
+	fields int
+	rows   int
+}
+
+// Row writes one row. Values must be primitives, one per declared field.
+func (t *TableWriter) Row(values ...interface{}) error {
+	if t.rows == t.n {
+		return fmt.Errorf("table %q declares %d rows", t.key, t.n)
+	}
+	if len(values) != t.fields {
+		return fmt.Errorf("table %q row has %d values, header declares %d fields", t.key, len(values), t.fields)
+	}
+
+	items := make([]interface{}, len(values))
+	for i, v := range values {
+		item, err := normalize(reflect.ValueOf(v))
+		if err != nil {
+			return err
+		}
+		if !isPrimitive(item) {
+			return fmt.Errorf("table %q row value %d is not a primitive", t.key, i)
+		}
+		items[i] = item
+	}
+
+	e := t.s.enc
+	e.w.WriteString("\n")
+	e.indent = 1
+	e.writeIndent()
+	for i, item := range items {
+		if i > 0 {
+			e.w.WriteString(string(rune(e.opts.Delimiter)))
+		}
+		if err := e.encode(item, false); err != nil {
+			return err
+		}
+	}
+	e.indent = 0
+	t.rows++
+	return nil
+}
+
+// Close ends the table, checking that every declared row was written.
+func (t *TableWriter) Close() error {
+	if t.s.table != t {
+		return nil
+	}
+	t.s.table = nil
+	if t.rows != t.n {
+		return fmt.Errorf("table %q declares %d rows, wrote %d", t.key, t.n, t.rows)
+	}
+	return nil
+}
+
+// isList reports whether v is a slice or array the stream writes element by
+// element. []byte encodes as a base64 string and is not one.
+func isList(v reflect.Value) bool {
+	if !v.IsValid() || hasMarshaler(v) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		return !v.IsNil() && v.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// writeList writes a list after prefix ("key:" for a field, nothing at the
+// root) in the form Marshal would choose. A first pass over the elements
+// picks the form; the second normalizes and writes them one at a time, and
+// writes the cells of struct rows straight from their fields.
+func (s *StreamEncoder) writeList(prefix string, rv reflect.Value) error {
+	e := s.enc
+	n := rv.Len()
+	if n == 0 {
+		e.w.WriteString(prefix + e.header(0) + ":")
+		return nil
+	}
+
+	shape := &listShape{inlineLists: e.opts.InlineLists, primitive: true, tab: true}
+	for i := 0; i < n && (shape.primitive || shape.tab); i++ {
+		if err := shape.add(rv.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	if shape.primitive {
+		e.w.WriteString(prefix + e.header(n) + ": ")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				e.w.WriteString(string(rune(e.opts.Delimiter)))
+			}
+			item, err := normalize(rv.Index(i))
+			if err != nil {
+				return err
+			}
+			if err := e.encode(item, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tabular := shape.tabular()
+	if tabular {
+		e.w.WriteString(prefix + e.header(n) + e.fieldList(shape.fields, shape.lists) + ":\n")
+	} else {
+		e.w.WriteString(prefix + e.header(n) + ":\n")
+	}
+	e.indent++
+	for i := 0; i < n; i++ {
+		var err error
+		if tabular {
+			e.writeIndent()
+			err = s.writeRow(rv.Index(i), shape.lists)
+		} else {
+			err = s.writeListItem(rv.Index(i))
+		}
+		if err != nil {
+			return err
+		}
+		if i < n-1 {
+			e.w.WriteString("\n")
+		}
+	}
+	e.indent--
+	return nil
+}
+
+func (s *StreamEncoder) writeListItem(v reflect.Value) error {
+	item, err := normalize(v)
+	if err != nil {
+		return err
+	}
+	return s.enc.encodeListItem(item)
+}
+
+// writeRow writes one tabular row. Struct elements are written field by
+// field; anything else is normalized first.
+func (s *StreamEncoder) writeRow(v reflect.Value, lists []bool) error {
+	v, ok := plainStruct(v)
+	if !ok {
+		item, err := normalize(v)
+		if err != nil {
+			return err
+		}
+		return s.enc.encodeRow(item.(*object), lists)
+	}
+
+	j := 0
+	return eachStructValue(v, func(name, opts string, fv reflect.Value) error {
+		if j > 0 {
+			s.enc.w.WriteString(string(rune(s.enc.opts.Delimiter)))
+		}
+		list := lists[j]
+		j++
+		if err := s.writeCell(fv, opts, list); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// writeCell writes a tabular cell. Strings, booleans and integers are
+// written directly; other values go through normalize.
+func (s *StreamEncoder) writeCell(v reflect.Value, opts string, list bool) error {
+	e := s.enc
+	if !list && !hasOption(opts, "string") && !hasMarshaler(v) {
+		switch v.Kind() {
+		case reflect.String:
+			e.w.WriteString(quoteString(v.String(), e.opts.Delimiter))
+			return nil
+		case reflect.Bool:
+			e.w.WriteString(strconv.FormatBool(v.Bool()))
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			e.w.WriteString(strconv.FormatInt(v.Int(), 10))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			e.w.WriteString(strconv.FormatUint(v.Uint(), 10))
+			return nil
+		}
+	}
+
+	item, err := normalize(v)
+	if err != nil {
+		return err
+	}
+	if hasOption(opts, "string") {
+		item = quoteOption(item)
+	}
+	if list {
+		e.encodeListCell(item)
+		return nil
+	}
+	return e.encode(item, false)
+}
+
+// plainStruct dereferences v and reports whether it is a struct encoded
+// from its fields rather than through a marshaler.
+func plainStruct(v reflect.Value) (reflect.Value, bool) {
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() && !hasMarshaler(v) {
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct && !hasMarshaler(v)
+}
+
+// cellClass is what a value contributes to the choice of array form.
+type cellClass int
+
+const (
+	cellNull cellClass = iota
+	cellPrimitive
+	cellList
+	cellEmptyList
+	cellOther
+)
+
+// listShape follows uniformObjectFields one element at a time, so the form
+// of a list can be chosen without holding its normalized elements.
+type listShape struct {
+	inlineLists bool
+	primitive   bool // every element so far is a primitive
+	tab         bool // every element so far is an object with the same keys
+	objects     int
+	width       int // keys seen in the current object
+	fields      []string
+	lists       []bool
+	prims       []bool
+	empty       bool // a list column holds an empty list
+}
+
+// add records one element. Struct elements are classified from their fields
+// without normalizing them.
+func (sh *listShape) add(v reflect.Value) error {
+	v, ok := plainStruct(v)
+	if !ok {
+		item, err := normalize(v)
+		if err != nil {
+			return err
+		}
+		sh.addItem(item)
+		return nil
+	}
+
+	sh.begin()
+	err := eachStructValue(v, func(name, _ string, fv reflect.Value) error {
+		c, err := classifyValue(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		sh.key(name, c)
+		return nil
+	})
+	sh.end()
+	return err
+}
+
+func (sh *listShape) addItem(item interface{}) {
+	obj, ok := item.(*object)
+	if !ok {
+		sh.tab = false
+		if !isPrimitive(item) {
+			sh.primitive = false
+		}
+		return
+	}
+	sh.begin()
+	for i, k := range obj.keys {
+		sh.key(k, classify(obj.values[i]))
+	}
+	sh.end()
+}
+
+func (sh *listShape) begin() {
+	sh.primitive = false
+	sh.width = 0
+}
+
+func (sh *listShape) key(name string, c cellClass) {
+	if !sh.tab {
+		return
+	}
+	j := sh.width
+	sh.width++
+	if sh.objects == 0 {
+		sh.fields = append(sh.fields, name)
+		sh.lists = append(sh.lists, false)
+		sh.prims = append(sh.prims, false)
+	} else if j >= len(sh.fields) || sh.fields[j] != name {
+		sh.tab = false
+		return
+	}
+
+	switch c {
+	case cellNull:
+	case cellPrimitive:
+		sh.prims[j] = true
+	case cellList, cellEmptyList:
+		if !sh.inlineLists {
+			sh.tab = false
+			return
+		}
+		sh.lists[j] = true
+		sh.empty = sh.empty || c == cellEmptyList
+	default:
+		sh.tab = false
+	}
+}
+
+func (sh *listShape) end() {
+	if sh.width != len(sh.fields) {
+		sh.tab = false
+	}
+	sh.objects++
+}
+
+// tabular reports whether the list takes the tabular form, with the same
+// rules as uniformObjectFields and inlineList.
+func (sh *listShape) tabular() bool {
+	if !sh.tab || len(sh.fields) == 0 || (sh.empty && len(sh.fields) == 1) {
+		return false
+	}
+	for j := range sh.fields {
+		if sh.lists[j] && sh.prims[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// classify returns the class of a normalized value.
+func classify(v interface{}) cellClass {
+	if v == nil {
+		return cellNull
+	}
+	if isPrimitive(v) {
+		return cellPrimitive
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return cellOther
+	}
+	for _, item := range list {
+		if item == nil || !isPrimitive(item) {
+			return cellOther
+		}
+	}
+	if len(list) == 0 {
+		return cellEmptyList
+	}
+	return cellList
+}
+
+// classifyValue returns the class v would normalize to, normalizing only
+// values with a marshaler.
+func classifyValue(v reflect.Value) (cellClass, error) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return cellNull, nil
+		}
+		if hasMarshaler(v) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return cellNull, nil
+	}
+	if hasMarshaler(v) {
+		item, err := normalize(v)
+		if err != nil {
+			return cellOther, err
+		}
+		return classify(item), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cellPrimitive, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return cellNull, nil
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return cellNull, nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return cellPrimitive, nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if c, err := classifyValue(v.Index(i)); err != nil || c != cellPrimitive {
+				return cellOther, err
+			}
+		}
+		if v.Len() == 0 {
+			return cellEmptyList, nil
+		}
+		return cellList, nil
+	}
+	return cellOther, nil
+}
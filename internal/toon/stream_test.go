@@ -0,0 +1,328 @@
+package toon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamEncoder_MatchesMarshal(t *testing.T) {
+	def := "now()"
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{"struct", orderedTable{
+			Zeta:   "z",
+			Alpha:  1,
+			Labels: map[string]string{"b": "2", "a": "1"},
+			Rows:   []orderedRow{{"id", false}},
+		}},
+		{"pointer", &unmarshalColumn{Name: "created_at", Nullable: true, Default: &def}},
+		{"map", map[string]interface{}{"b": []interface{}{1, "x"}, "a": map[string]int{"z": 1}}},
+		{"empty struct", struct{}{}},
+		{"time", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"primitive", "hello"},
+		{"array", []orderedRow{{"a", true}}},
+		{"nil", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := NewStreamEncoder(&buf).Encode(tt.input); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("stream output:\n%s\nwant:\n%s", buf.String(), want)
+			}
+		})
+	}
+}
+
+type streamCell struct {
+	Name  string   `json:"name"`
+	Count *int     `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+	ID    int64    `json:"id,string"`
+}
+
+func TestStreamEncoder_ListsMatchMarshal(t *testing.T) {
+	two := 2
+	def := "now()"
+	lists := struct {
+		Empty     []string                 `json:"empty"`
+		Prims     []interface{}            `json:"prims"`
+		Rows      []streamCell             `json:"rows"`
+		Ragged    []streamCell             `json:"ragged"`
+		Pointers  []*unmarshalColumn       `json:"pointers"`
+		Mixed     []interface{}            `json:"mixed"`
+		Nested    []orderedTable           `json:"nested"`
+		Maps      []map[string]interface{} `json:"maps"`
+		Blank     []struct{}               `json:"blank"`
+		Times     []time.Time              `json:"times"`
+		Fixed     [2]float64               `json:"fixed"`
+		Bytes     []byte                   `json:"bytes"`
+		NilSlice  []int                    `json:"nil_slice"`
+		EmptyTags []streamCell             `json:"empty_tags"`
+	}{
+		Empty: []string{},
+		Prims: []interface{}{1, "a, b", nil, true, 1.5},
+		Rows: []streamCell{
+			{Name: "a|b", Count: &two, Tags: []string{"x", "y, z"}, ID: 1},
+			{Name: "c", Tags: []string{"w"}, ID: 2},
+		},
+		Ragged:   []streamCell{{Name: "a", Tags: []string{"x"}}, {Name: "b"}},
+		Pointers: []*unmarshalColumn{{Name: "id"}, {Name: "created_at", Nullable: true, Default: &def}},
+		Mixed:    []interface{}{"x", map[string]int{"a": 1}, []int{1, 2}},
+		Nested: []orderedTable{
+			{Zeta: "z", Rows: []orderedRow{{"id", false}}},
+		},
+		Maps:      []map[string]interface{}{{"b": 1, "a": "x"}, {"a": "y", "b": 2}},
+		Blank:     []struct{}{{}, {}},
+		Times:     []time.Time{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		Fixed:     [2]float64{0.5, 2},
+		Bytes:     []byte("hi"),
+		EmptyTags: []streamCell{{Name: "a", Tags: []string{"x"}}, {Name: "b", Tags: []string{"y"}}},
+	}
+
+	options := map[string]EncodeOptions{
+		"default":      {},
+		"pipe":         {Delimiter: Pipe},
+		"inline lists": {InlineLists: true},
+		"folding":      {KeyFolding: true, Indent: 4},
+	}
+	for name, opts := range options {
+		for _, input := range []interface{}{lists, []streamCell{{Name: "root", ID: 7}}} {
+			t.Run(name, func(t *testing.T) {
+				want, err := MarshalWithOptions(input, opts)
+				if err != nil {
+					t.Fatalf("MarshalWithOptions() error = %v", err)
+				}
+
+				var buf bytes.Buffer
+				enc := NewStreamEncoder(&buf)
+				if err := enc.SetOptions(opts); err != nil {
+					t.Fatal(err)
+				}
+				if err := enc.Encode(input); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+				if buf.String() != string(want) {
+					t.Errorf("stream output:\n%s\nwant:\n%s", buf.String(), want)
+				}
+			})
+		}
+	}
+}
+
+func TestStreamEncoder_ListErrors(t *testing.T) {
+	input := struct {
+		Values []float64 `json:"values"`
+	}{[]float64{1, math.NaN()}}
+
+	if err := NewStreamEncoder(io.Discard).Encode(input); err == nil || !strings.HasPrefix(err.Error(), "values: ") {
+		t.Errorf("Encode() error = %v, want a values error", err)
+	}
+}
+
+func TestStreamEncoder_Table(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewStreamEncoder(&buf)
+
+	if err := enc.Field("name", "graph"); err != nil {
+		t.Fatal(err)
+	}
+	tw, err := enc.Table("edges", 2, "from", "to", "weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Field("early", 1); err == nil {
+		t.Error("Field() error = nil while a table is open")
+	}
+	if err := tw.Row("users", "orders, archived", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Row("orders", "items", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Row("extra", "row", 0); err == nil {
+		t.Error("Row() error = nil past the declared count")
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Field("count", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name: graph\nedges:[2]{from,to,weight}:\n  users,\"orders, archived\",1.5\n  orders,items,null\ncount: 2"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	var decoded map[string]interface{}
+	if err := Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+}
+
+func TestStreamEncoder_TableErrors(t *testing.T) {
+	enc := NewStreamEncoder(io.Discard)
+	tw, err := enc.Table("rows", 2, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Row(1, 2); err == nil {
+		t.Error("Row() error = nil for too many values")
+	}
+	if err := tw.Row([]int{1}); err == nil {
+		t.Error("Row() error = nil for a non-primitive value")
+	}
+	if err := enc.Flush(); err == nil {
+		t.Error("Flush() error = nil with an open table")
+	}
+	if err := tw.Close(); err == nil {
+		t.Error("Close() error = nil with missing rows")
+	}
+}
+
+type benchEdge struct {
+	Kind           string   `json:"kind"`
+	FromTable      string   `json:"from_table"`
+	FromSchema     string   `json:"from_schema,omitempty"`
+	FromColumns    []string `json:"from_columns,omitempty"`
+	ToTable        string   `json:"to_table"`
+	ToSchema       string   `json:"to_schema,omitempty"`
+	ToColumns      []string `json:"to_columns,omitempty"`
+	ConstraintName string   `json:"constraint_name,omitempty"`
+}
+
+type benchNode struct {
+	TableName  string `json:"table_name"`
+	SchemaName string `json:"schema_name,omitempty"`
+}
+
+type benchGraph struct {
+	Nodes []benchNode `json:"nodes"`
+	Edges []benchEdge `json:"edges"`
+}
+
+func benchmarkGraph(n int) benchGraph {
+	g := benchGraph{Nodes: make([]benchNode, n), Edges: make([]benchEdge, n)}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("table_%d", i)
+		g.Nodes[i] = benchNode{TableName: name, SchemaName: "public"}
+		g.Edges[i] = benchEdge{
+			Kind:           "foreign_key",
+			FromTable:      name,
+			FromSchema:     "public",
+			FromColumns:    []string{"parent_id"},
+			ToTable:        fmt.Sprintf("table_%d", (i+1)%n),
+			ToSchema:       "public",
+			ToColumns:      []string{"id"},
+			ConstraintName: name + "_parent_id_fkey",
+		}
+	}
+	return g
+}
+
+// BenchmarkMarshal_Graph is the buffered path: build the whole document in
+// memory, then write it.
+func BenchmarkMarshal_Graph(b *testing.B) {
+	g := benchmarkGraph(10000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := Marshal(g)
+		if err != nil {
+			b.Fatal(err)
+		}
+		io.Discard.Write(data)
+	}
+}
+
+// BenchmarkStreamEncoder_Graph writes the same graph one list element at a
+// time; nodes rows are written straight from their fields.
+func BenchmarkStreamEncoder_Graph(b *testing.B) {
+	g := benchmarkGraph(10000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := NewStreamEncoder(io.Discard).Encode(g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal_Rows(b *testing.B) {
+	g := benchmarkGraph(10000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := Marshal(struct {
+			Nodes []benchNode `json:"nodes"`
+		}{g.Nodes})
+		if err != nil {
+			b.Fatal(err)
+		}
+		io.Discard.Write(data)
+	}
+}
+
+// BenchmarkStreamEncoder_Rows writes the same rows through Table without
+// materializing the slice in the encoder's value model.
+func BenchmarkStreamEncoder_Rows(b *testing.B) {
+	g := benchmarkGraph(10000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		enc := NewStreamEncoder(io.Discard)
+		tw, err := enc.Table("nodes", len(g.Nodes), "table_name", "schema_name")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, n := range g.Nodes {
+			if err := tw.Row(n.TableName, n.SchemaName); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			b.Fatal(err)
+		}
+		if err := enc.Flush(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStreamEncoder_RowsMatchMarshal(t *testing.T) {
+	g := benchmarkGraph(3)
+	want, err := Marshal(struct {
+		Nodes []benchNode `json:"nodes"`
+	}{g.Nodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	enc := NewStreamEncoder(&sb)
+	tw, _ := enc.Table("nodes", len(g.Nodes), "table_name", "schema_name")
+	for _, n := range g.Nodes {
+		tw.Row(n.TableName, n.SchemaName)
+	}
+	tw.Close()
+	enc.Flush()
+
+	if sb.String() != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
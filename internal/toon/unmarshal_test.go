@@ -0,0 +1,237 @@
+package toon
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+type unmarshalColumn struct {
+	Name     string  `json:"column_name"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default_value,omitempty"`
+}
+
+type unmarshalTable struct {
+	Name      string            `json:"table_name"`
+	Rows      *int64            `json:"row_count_estimate,omitempty"`
+	Columns   []unmarshalColumn `json:"columns"`
+	Tags      []string          `json:"tags,omitempty"`
+	Comments  map[string]string `json:"column_comments,omitempty"`
+	Computed  time.Time         `json:"computed_at"`
+	Internal  string            `json:"-"`
+	Unchanged string            `json:"unchanged,omitempty"`
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+	def := "now()"
+	rows := int64(9223372036854775807)
+	want := unmarshalTable{
+		Name: "users",
+		Rows: &rows,
+		Columns: []unmarshalColumn{
+			{Name: "id", Nullable: false},
+			{Name: "created_at", Nullable: true, Default: &def},
+		},
+		Tags:     []string{"core", "1"},
+		Comments: map[string]string{"id": "Primary key, generated", "has space": "x: y"},
+		Computed: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	got := unmarshalTable{Internal: "kept", Unchanged: "kept"}
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v\n%s", err, data)
+	}
+	want.Internal, want.Unchanged = "kept", "kept"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshal_Spec(t *testing.T) {
+	input := strings.Join([]string{
+		`context:`,
+		`  task: Our favorite hikes`,
+		`  season: spring_2025`,
+		`friends[3]: ana,luis,sam`,
+		`hikes[2]{id,name,"distance km",wasSunny}:`,
+		`  1,Blue Lake Trail,7.5,true`,
+		`  2,"Ridge Overlook, North",9.2,false`,
+		`items[3]:`,
+		`  - id: 1`,
+		`    tags[2]: a,b`,
+		`  - meta:`,
+		`      note: "quoted \"text\""`,
+		`    ok: true`,
+		`  - plain`,
+	}, "\n")
+
+	var got interface{}
+	if err := Unmarshal([]byte(input), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"context": map[string]interface{}{"task": "Our favorite hikes", "season": "spring_2025"},
+		"friends": []interface{}{"ana", "luis", "sam"},
+		"hikes": []interface{}{
+			map[string]interface{}{"id": int64(1), "name": "Blue Lake Trail", "distance km": 7.5, "wasSunny": true},
+			map[string]interface{}{"id": int64(2), "name": "Ridge Overlook, North", "distance km": 9.2, "wasSunny": false},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"id": int64(1), "tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"meta": map[string]interface{}{"note": `quoted "text"`}, "ok": true},
+			"plain",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %#v\nwant %#v", got, want)
+	}
+}
+
+func TestUnmarshal_LengthValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"inline too short", "tags[3]: a,b"},
+		{"inline too long", "tags[1]: a,b"},
+		{"missing rows", "rows[2]{a}:\n  1"},
+		{"extra rows", "rows[1]{a}:\n  1\n  2"},
+		{"missing items", "items[2]:\n  - a"},
+		{"extra items", "items[1]:\n  - a\n  - b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := Unmarshal([]byte(tt.input), &v); err == nil {
+				t.Errorf("Unmarshal(%q) error = nil, got %#v", tt.input, v)
+			}
+		})
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	var got unmarshalColumn
+	if err := NewDecoder(strings.NewReader("column_name: id\nnullable: true")).Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Name != "id" || !got.Nullable {
+		t.Errorf("Decode() = %+v", got)
+	}
+}
+
+type fuzzRow struct {
+	Key   string  `json:"key"`
+	Value int64   `json:"value"`
+	Score float64 `json:"score"`
+}
+
+type fuzzRecord struct {
+	Name    string            `json:"name"`
+	Count   int64             `json:"count"`
+	Ratio   float64           `json:"ratio"`
+	Flag    bool              `json:"flag"`
+	Note    *string           `json:"note,omitempty"`
+	Tags    []string          `json:"tags"`
+	Rows    []fuzzRow         `json:"rows"`
+	Attrs   map[string]string `json:"attrs"`
+	Nested  *fuzzRecord       `json:"nested,omitempty"`
+	Matrix  [][]string        `json:"matrix"`
+	Objects []fuzzObject      `json:"objects"`
+}
+
+type fuzzObject struct {
+	Name string   `json:"name"`
+	List []string `json:"list"`
+}
+
+// FuzzUnmarshal_RoundTrip checks Unmarshal(Marshal(v)) == v for records built
+// from fuzzed strings and numbers, in the spec layout and with inline lists.
+func FuzzUnmarshal_RoundTrip(f *testing.F) {
+	f.Add("users", "id", int64(42), 3.5, true)
+	f.Add("", "", int64(0), 0.0, false)
+	f.Add("a,b", "- dash", int64(-1), -0.25, true)
+	f.Add("key: value", "[0]:", int64(math.MaxInt64), 1e21, false)
+	f.Add(" padded ", "\"quoted\"\n\\", int64(math.MinInt64), 1e-7, true)
+	f.Add("true", "null", int64(7), 123456.789, false)
+	f.Add("{}", "05", int64(1), 0.1, true)
+
+	f.Fuzz(func(t *testing.T, a, b string, n int64, x float64, flag bool) {
+		if !utf8.ValidString(a) || !utf8.ValidString(b) || math.IsNaN(x) || math.IsInf(x, 0) {
+			t.Skip()
+		}
+
+		want := fuzzRecord{
+			Name:  a,
+			Count: n,
+			Ratio: x,
+			Flag:  flag,
+			Note:  &b,
+			Tags:  []string{a, b},
+			Rows: []fuzzRow{
+				{Key: a, Value: n, Score: x},
+				{Key: b, Value: -n, Score: -x},
+			},
+			Attrs:   map[string]string{a: b, "fixed": a},
+			Nested:  &fuzzRecord{Name: b, Tags: []string{}, Attrs: map[string]string{b: a}},
+			Matrix:  [][]string{{a}, {}, {b, a}},
+			Objects: []fuzzObject{{Name: a, List: []string{b}}, {Name: b}},
+		}
+
+		for _, opts := range []EncodeOptions{{}, {InlineLists: true}, {InlineLists: true, Delimiter: Pipe}} {
+			data, err := MarshalWithOptions(want, opts)
+			if err != nil {
+				t.Fatalf("Marshal(%+v) error = %v", opts, err)
+			}
+
+			var got fuzzRecord
+			if err := Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Unmarshal(Marshal(v, %+v)) = %+v\nwant %+v\n%s", opts, got, want, data)
+			}
+		}
+	})
+}
+
+// FuzzDecode checks that arbitrary input never panics and that anything that
+// decodes re-encodes to a document that decodes to the same value.
+func FuzzDecode(f *testing.F) {
+	f.Add("a: 1\nb[2]: x,y\nc[1]{k,v}:\n  1,two\nd[2]:\n  - e: f\n  - [1]: g")
+	f.Add(`"quoted key": "value, with comma"`)
+	f.Add("[2]:\n  - \n    a: 1\n  - {}")
+	f.Add("x:\n  y:\n    z: null")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if !utf8.ValidString(input) {
+			t.Skip()
+		}
+		v, err := Decode([]byte(input))
+		if err != nil {
+			return
+		}
+
+		encoded, err := Encode(v)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		again, err := Decode([]byte(encoded))
+		if err != nil {
+			t.Fatalf("Decode(Encode(v)) error = %v\n%s", err, encoded)
+		}
+		if !reflect.DeepEqual(again, v) {
+			t.Fatalf("Decode(Encode(v)) = %#v, want %#v\n%s", again, v, encoded)
+		}
+	})
+}
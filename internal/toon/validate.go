@@ -0,0 +1,47 @@
+package toon
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Validate checks data against the TOON spec and returns every problem
+// found, ordered by position; nil means the document is valid. Parsing
+// carries on past problems that leave the structure readable, such as a
+// wrong array length, an invalid escape or a badly indented line, so one pass
+// reports them all.
+//
+// Validate is stricter than Decode: strings that the spec requires to be
+// quoted (empty, numeric-looking, or containing structural characters or the
+// active delimiter) must be.
+func Validate(data []byte, opts DecodeOptions) []*SyntaxError {
+	d, _ := newParser(data, opts, true)
+	if _, err := d.parse(); err != nil {
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			serr = &SyntaxError{Line: 1, Column: 1, Msg: err.Error()}
+		}
+		d.issues = append(d.issues, serr)
+	}
+
+	sort.SliceStable(d.issues, func(i, j int) bool {
+		a, b := d.issues[i], d.issues[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return d.issues
+}
+
+// DetectIndent returns the indentation width of the first indented line,
+// or zero (the decoder's default) when nothing is indented.
+func DetectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		if content := strings.TrimLeft(line, " "); strings.TrimSpace(content) != "" && len(content) < len(line) {
+			return len(line) - len(content)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package toon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type issue struct {
+		line, column int
+		msg          string
+	}
+	tests := []struct {
+		name  string
+		input string
+		opts  DecodeOptions
+		want  []issue
+	}{
+		{
+			name:  "valid",
+			input: "name: users\ntags:[2]: a,\"b,c\"\nrows:[1|]{id|note}:\n  1|x,y",
+		},
+		{
+			name:  "inline length",
+			input: "tags:[3]: a,b",
+			want:  []issue{{1, 6, "declares 3 values, found 2"}},
+		},
+		{
+			name:  "row count",
+			input: "rows:[3]{a,b}:\n  1,2\n  3,4\nnext: 1",
+			want:  []issue{{1, 6, "declares 3 rows, found 2"}},
+		},
+		{
+			name:  "extra list items",
+			input: "items:[1]:\n  - a\n  - b",
+			want:  []issue{{1, 7, "declares 1 items, found 2"}},
+		},
+		{
+			name:  "field counts",
+			input: "rows:[2]{a,b}:\n  1,two, three\n  4",
+			want: []issue{
+				{2, 8, "row has 3 values, header declares 2 fields; quote values containing ','"},
+				{3, 4, "row has 1 values, header declares 2 fields"},
+			},
+		},
+		{
+			name:  "unquoted delimiter in inline array",
+			input: "tags:[2|]: a|b|c",
+			want:  []issue{{1, 6, "declares 2 values, found 3"}},
+		},
+		{
+			name:  "strings that must be quoted",
+			input: "a: 05\nb: x: y\nc:[2]: ok,\nd: -flag",
+			want: []issue{
+				{1, 4, `"05" must be quoted`},
+				{2, 4, `"x: y" must be quoted`},
+				{3, 11, `"" must be quoted`},
+				{4, 4, `"-flag" must be quoted`},
+			},
+		},
+		{
+			name:  "invalid escapes",
+			input: "a: \"ok \\q\"\nb:[2]: x,\"\\u\"",
+			want: []issue{
+				{1, 8, `invalid escape \q`},
+				{2, 11, `invalid escape \u`},
+			},
+		},
+		{
+			name:  "unterminated string",
+			input: "a: \"open",
+			want:  []issue{{1, 4, "malformed quoted string"}},
+		},
+		{
+			name:  "bad indentation",
+			input: "a:\n   b: 1",
+			want:  []issue{{2, 1, "not a multiple of 2"}},
+		},
+		{
+			name:  "unexpected indentation",
+			input: "a: 1\n    b: 2",
+			want:  []issue{{2, 1, "unexpected indentation"}},
+		},
+		{
+			name:  "problems after bad indentation",
+			input: "a:\n   b: 05\nc:[2]: x\nd: 1\nd: 2",
+			want: []issue{
+				{2, 1, "not a multiple of 2"},
+				{2, 7, `"05" must be quoted`},
+				{3, 3, "declares 2 values, found 1"},
+				{5, 1, `duplicate key "d"`},
+			},
+		},
+		{
+			name:  "problems inside unexpected indentation",
+			input: "a: 1\n    b: 05\n    c: 1\nd: x: y",
+			want: []issue{
+				{2, 1, "unexpected indentation"},
+				{2, 8, `"05" must be quoted`},
+				{4, 4, `"x: y" must be quoted`},
+			},
+		},
+		{
+			name:  "tab in indentation",
+			input: "a:\n\tb: 1\nc: 05",
+			want: []issue{
+				{2, 1, "tab in indentation"},
+				{3, 4, `"05" must be quoted`},
+			},
+		},
+		{
+			name:  "wider indent",
+			input: "a:\n    b: 1",
+			opts:  DecodeOptions{Indent: 4},
+		},
+		{
+			name:  "duplicate key",
+			input: "a: 1\na: 2",
+			want:  []issue{{2, 1, `duplicate key "a"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate([]byte(tt.input), tt.opts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d issues", got, len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.Line != w.line || g.Column != w.column || !strings.Contains(g.Msg, w.msg) {
+					t.Errorf("issue %d = %v, want line %d, column %d: %s", i, g, w.line, w.column, w.msg)
+				}
+			}
+		})
+	}
+}
+
+// TestValidate_EncoderOutput checks that everything Encode writes passes
+// validation under every layout option.
+func TestValidate_EncoderOutput(t *testing.T) {
+	values := []interface{}{
+		optionsFixture(),
+		map[string]interface{}{"empty": "", "num": "05", "dash": "-x", "colon": "a: b", "list": []interface{}{"", "1", map[string]interface{}{"k": "v"}}},
+		[]interface{}{"a", "b,c"},
+	}
+	layouts := []EncodeOptions{{}, {Delimiter: Pipe}, {Delimiter: Tab}, {KeyFolding: true, Indent: 4}}
+
+	for _, v := range values {
+		for _, opts := range layouts {
+			out, err := EncodeWithOptions(v, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			if issues := Validate([]byte(out), DecodeOptions{Indent: opts.Indent}); issues != nil {
+				t.Errorf("Validate(%+v) = %v\n%s", opts, issues, out)
+			}
+		}
+	}
+}
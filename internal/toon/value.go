@@ -0,0 +1,316 @@
+package toon
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// object is an object whose fields keep their encoding order: struct
+// declaration order, or sorted keys for maps.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) add(key string, v interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, v)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+// normalize converts a Go value into the encoder's value model: nil, bool,
+// int64, uint64, float64, json.Number, string, []interface{} and *object.
+// It follows encoding/json's rules for tags, omitempty, embedded structs and
+// marshaler interfaces, without going through JSON text.
+func normalize(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Type() == jsonNumberType {
+		return json.Number(v.String()), nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		return marshalJSONValue(v.Interface().(json.Marshaler))
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) {
+		return marshalJSONValue(v.Addr().Interface().(json.Marshaler))
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", v.Type(), err)
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return normalize(v.Elem())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("unsupported value: %v", f)
+		}
+		if v.Kind() == reflect.Float32 {
+			// Use the shortest decimal that round-trips at 32 bits
+			f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+		}
+		return f, nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		return normalizeList(v)
+	case reflect.Array:
+		return normalizeList(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return normalizeMap(v)
+	case reflect.Struct:
+		obj := &object{}
+		if err := addStructFields(obj, v); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", v.Type())
+	}
+}
+
+func normalizeList(v reflect.Value) ([]interface{}, error) {
+	arr := make([]interface{}, v.Len())
+	for i := range arr {
+		item, err := normalize(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = item
+	}
+	return arr, nil
+}
+
+// normalizeMap sorts keys so map iteration order never reaches the output.
+func normalizeMap(v reflect.Value) (*object, error) {
+	obj := &object{}
+	err := eachMapEntry(v, func(key string, fv reflect.Value) error {
+		item, err := normalize(fv)
+		if err != nil {
+			return err
+		}
+		obj.add(key, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// eachMapEntry calls fn for every entry in sorted key order.
+func eachMapEntry(v reflect.Value, fn func(key string, fv reflect.Value) error) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	for _, e := range entries {
+		if err := fn(e.key, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal map key: %w", err)
+		}
+		return string(text), nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type: %s", k.Type())
+}
+
+// addStructFields appends exported fields in declaration order.
+func addStructFields(obj *object, v reflect.Value) error {
+	return eachStructField(v, func(key string, item interface{}) error {
+		obj.add(key, item)
+		return nil
+	})
+}
+
+// eachStructField calls fn with the normalized value of every exported field
+// in declaration order, applying json tags and omitempty.
+func eachStructField(v reflect.Value, fn func(key string, item interface{}) error) error {
+	return eachStructValue(v, func(name, opts string, fv reflect.Value) error {
+		item, err := normalize(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if hasOption(opts, "string") {
+			item = quoteOption(item)
+		}
+		return fn(name, item)
+	})
+}
+
+// eachStructValue calls fn with the name, tag options and value of every
+// field that encodes, in declaration order, skipping omitempty fields that
+// are empty. Untagged embedded structs contribute their fields inline, as
+// with encoding/json.
+func eachStructValue(v reflect.Value, fn func(name, opts string, fv reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := eachStructValue(fv, fn); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if hasOption(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+
+		if err := fn(name, opts, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasOption(opts, want string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == want {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteOption applies the ",string" tag option to scalar values.
+func quoteOption(v interface{}) interface{} {
+	switch val := v.(type) {
+	case bool:
+		return strconv.FormatBool(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case float64:
+		return formatNumber(val)
+	}
+	return v
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// marshalJSONValue converts a json.Marshaler's output (time.Time, for one)
+// into the value model, keeping numbers exact and sorting object keys.
+func marshalJSONValue(m json.Marshaler) (interface{}, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", m, err)
+	}
+
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to parse %T output: %w", m, err)
+	}
+	return normalize(reflect.ValueOf(generic))
+}